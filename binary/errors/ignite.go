@@ -41,7 +41,8 @@ func NewError(status int32, message string) error {
 		message: fmt.Sprintf("[%d] %s", status, message)}
 }
 
-// Wrapf formats error
+// Wrapf formats error.
+// Wrapped error can be checked with errors.Is and errors.As.
 func Wrapf(err error, format string, a ...interface{}) error {
 	m := fmt.Sprintf("%s: %s", fmt.Sprintf(format, a...), err.Error())
 	original, ok := err.(*IgniteError)
@@ -49,5 +50,20 @@ func Wrapf(err error, format string, a ...interface{}) error {
 		original.message = m
 		return original
 	}
-	return Errorf(m)
+	return &wrapped{message: m, err: err}
+}
+
+// wrapped is error with message and original error
+type wrapped struct {
+	message string
+	err     error
+}
+
+func (e *wrapped) Error() string {
+	return e.message
+}
+
+// Unwrap returns original error
+func (e *wrapped) Unwrap() error {
+	return e.err
 }
@@ -1,7 +1,9 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"io"
 	"testing"
 )
 
@@ -91,6 +93,16 @@ func TestWrapf(t *testing.T) {
 	}
 }
 
+func TestWrapf_Unwrap(t *testing.T) {
+	err := Wrapf(Wrapf(io.EOF, "failed to read"), "failed to execute operation")
+	if !stderrors.Is(err, io.EOF) {
+		t.Errorf("Wrapf() error = %v, does not wrap %v", err, io.EOF)
+	}
+	if got, want := err.Error(), "failed to execute operation: failed to read: EOF"; got != want {
+		t.Errorf("Wrapf() error = %v, want %v", got, want)
+	}
+}
+
 func TestIgniteError_String(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,491 @@
+package ignite
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// partitionAwarenessVersion is the lowest protocol version which supports partition awareness
+var partitionAwarenessVersion = ProtocolVersion{Major: 1, Minor: 4}
+
+// cacheAffinity is partition to node mapping of the cache
+type cacheAffinity struct {
+	// applicable is false if keys of the cache can not be mapped to partitions by the client
+	applicable bool
+	// nodes contains IDs of the primary nodes by partition
+	nodes []uuid.UUID
+}
+
+// partitioned is transport which sends key-value operations straight to the primary node of the key.
+// Other operations are sent over the default connection.
+type partitioned struct {
+	ci  ConnInfo
+	def *failover
+
+	// fetch serializes requests of partition mappings
+	fetch sync.Mutex
+
+	mutex sync.Mutex
+	// connections to the cluster nodes by node ID
+	nodes      map[uuid.UUID]*connection
+	connecting bool
+	// partition mappings by cache ID
+	caches   map[int32]*cacheAffinity
+	topology affinityVersion
+	closed   bool
+}
+
+// newPartitioned opens default connection and connections to all available endpoints
+func newPartitioned(ctx context.Context, ci ConnInfo) (*partitioned, error) {
+	def, err := newFailover(ctx, ci)
+	if err != nil {
+		return nil, err
+	}
+	if v := def.session().version; v.Less(partitionAwarenessVersion) {
+		_ = def.close()
+		return nil, errors.Errorf("partition awareness requires protocol version %s or above, but negotiated version is %s",
+			partitionAwarenessVersion, v)
+	}
+	p := &partitioned{
+		ci:     ci,
+		def:    def,
+		nodes:  map[uuid.UUID]*connection{},
+		caches: map[int32]*cacheAffinity{},
+	}
+	p.connecting = true
+	p.connectNodes(ctx)
+
+	return p, nil
+}
+
+// connectNodes opens connections to the endpoints which are not connected yet
+func (p *partitioned) connectNodes(ctx context.Context) {
+	for _, e := range p.ci.endpoints() {
+		conn, err := dialEndpoint(ctx, p.ci, e)
+		if err != nil {
+			continue
+		}
+		p.mutex.Lock()
+		if old, ok := p.nodes[conn.nodeID]; p.closed || (ok && old.alive()) {
+			p.mutex.Unlock()
+			_ = conn.close()
+			continue
+		}
+		p.nodes[conn.nodeID] = conn
+		p.mutex.Unlock()
+	}
+	p.mutex.Lock()
+	p.connecting = false
+	p.mutex.Unlock()
+}
+
+// reconnectNodes opens connections to the nodes in background
+func (p *partitioned) reconnectNodes() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed || p.connecting {
+		return
+	}
+	p.connecting = true
+	go p.connectNodes(p.def.ctx)
+}
+
+// do sends key-value operation request to the primary node of the key if it is known,
+// other requests are sent over the default connection
+func (p *partitioned) do(ctx context.Context, message []byte) ([]byte, error) {
+	conn := p.primary(ctx, message)
+	if conn == nil {
+		var err error
+		if conn, err = p.def.connection(ctx); err != nil {
+			return nil, err
+		}
+	}
+	frame, err := conn.do(ctx, message)
+	p.checkTopology(conn)
+	return frame, err
+}
+
+// stream sends request like do and calls fn with reader of the response message
+func (p *partitioned) stream(ctx context.Context, message []byte, fn func(r io.Reader) error) error {
+	conn := p.primary(ctx, message)
+	if conn == nil {
+		var err error
+		if conn, err = p.def.connection(ctx); err != nil {
+			return err
+		}
+	}
+	err := conn.stream(ctx, message, fn)
+	p.checkTopology(conn)
+	return err
+}
+
+// primary returns connection to the primary node of the key of the key-value operation request.
+// Returns nil if the node is unknown or not connected.
+func (p *partitioned) primary(ctx context.Context, message []byte) *connection {
+	cacheID, hash, ok := affinityKey(message)
+	if !ok {
+		return nil
+	}
+	a := p.affinity(ctx, cacheID)
+	if a == nil || !a.applicable || len(a.nodes) == 0 {
+		return nil
+	}
+	nodeID := a.nodes[partition(hash, len(a.nodes))]
+
+	p.mutex.Lock()
+	conn, ok := p.nodes[nodeID]
+	if ok && !conn.alive() {
+		delete(p.nodes, nodeID)
+		conn = nil
+	}
+	p.mutex.Unlock()
+	if conn == nil {
+		p.reconnectNodes()
+	}
+	return conn
+}
+
+// affinity returns partition mapping of the cache, requests it from the cluster if it is unknown
+func (p *partitioned) affinity(ctx context.Context, cacheID int32) *cacheAffinity {
+	p.mutex.Lock()
+	a, ok := p.caches[cacheID]
+	p.mutex.Unlock()
+	if ok {
+		return a
+	}
+
+	p.fetch.Lock()
+	defer p.fetch.Unlock()
+	p.mutex.Lock()
+	a, ok = p.caches[cacheID]
+	p.mutex.Unlock()
+	if ok {
+		return a
+	}
+
+	conn, err := p.def.connection(ctx)
+	if err != nil {
+		return nil
+	}
+	v, caches, err := requestPartitions(ctx, conn, cacheID)
+	p.checkTopology(conn)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		// there is no mapping for this topology version,
+		// the cache is sent to the default node until topology is changed
+		v = conn.affinityTopology()
+		caches = map[int32]*cacheAffinity{cacheID: {}}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if v.less(p.topology) {
+		// mapping is obsolete
+		return nil
+	}
+	p.topology = v
+	for id, ca := range caches {
+		p.caches[id] = ca
+	}
+	return caches[cacheID]
+}
+
+// checkTopology drops partition mappings if the connection received newer affinity topology version
+func (p *partitioned) checkTopology(conn *connection) {
+	v := conn.affinityTopology()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.topology.less(v) {
+		p.topology = v
+		p.caches = map[int32]*cacheAffinity{}
+	}
+}
+
+// bind returns transport which sends requests over the default connection until it is closed
+func (p *partitioned) bind(ctx context.Context) (transport, error) {
+	return p.def.bind(ctx)
+}
+
+// alive returns true if the default connection is not closed or broken
+func (p *partitioned) alive() bool {
+	return p.def.alive()
+}
+
+// state returns state of the default connection
+func (p *partitioned) state() ConnectionState {
+	return p.def.state()
+}
+
+// endpoint returns endpoint of the default connection
+func (p *partitioned) endpoint() Endpoint {
+	return p.def.endpoint()
+}
+
+// session returns parameters negotiated with the node of the default connection
+func (p *partitioned) session() session {
+	return p.def.session()
+}
+
+// close closes all connections
+func (p *partitioned) close() error {
+	p.mutex.Lock()
+	p.closed = true
+	nodes := p.nodes
+	p.nodes = map[uuid.UUID]*connection{}
+	p.mutex.Unlock()
+
+	err := p.def.close()
+	for _, conn := range nodes {
+		_ = conn.close()
+	}
+	return err
+}
+
+// requestPartitions requests partition mappings of the cache and the caches of the same affinity group.
+// Returns affinity topology version of the mappings.
+func requestPartitions(ctx context.Context, conn *connection, cacheID int32) (affinityVersion, map[int32]*cacheAffinity, error) {
+	var v affinityVersion
+
+	// request and response
+	req := NewRequestOperation(OpCachePartitions)
+	res := NewResponseOperation(req.UID)
+
+	// set parameters
+	if err := WriteInt(req, 1); err != nil {
+		return v, nil, errors.Wrapf(err, "failed to write cache count")
+	}
+	if err := WriteInt(req, cacheID); err != nil {
+		return v, nil, errors.Wrapf(err, "failed to write cache ID")
+	}
+
+	// execute operation
+	msg := &bytes.Buffer{}
+	if _, err := req.WriteTo(msg); err != nil {
+		return v, nil, errors.Wrapf(err, "failed to prepare request")
+	}
+	frame, err := conn.do(ctx, msg.Bytes())
+	if err != nil {
+		return v, nil, errors.Wrapf(err, "failed to execute OP_CACHE_PARTITIONS operation")
+	}
+	if _, err = res.ReadFrom(bytes.NewReader(frame)); err != nil {
+		return v, nil, errors.Wrapf(err, "failed to execute OP_CACHE_PARTITIONS operation")
+	}
+	if err = res.CheckStatus(); err != nil {
+		return v, nil, err
+	}
+
+	// process result
+	if v.major, err = ReadLong(res); err != nil {
+		return v, nil, errors.Wrapf(err, "failed to read affinity topology version")
+	}
+	if v.minor, err = ReadInt(res); err != nil {
+		return v, nil, errors.Wrapf(err, "failed to read affinity topology minor version")
+	}
+	count, err := ReadInt(res)
+	if err != nil {
+		return v, nil, errors.Wrapf(err, "failed to read mapping count")
+	}
+	caches := map[int32]*cacheAffinity{}
+	for i := 0; i < int(count); i++ {
+		if err = readPartitionMapping(res, caches); err != nil {
+			return v, nil, errors.Wrapf(err, "failed to read mapping with index %d", i)
+		}
+	}
+	return v, caches, nil
+}
+
+// readPartitionMapping reads mapping of the caches with the same partition distribution
+func readPartitionMapping(r *ResponseOperation, caches map[int32]*cacheAffinity) error {
+	applicable, err := ReadBool(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read applicable flag")
+	}
+	a := &cacheAffinity{applicable: applicable}
+
+	count, err := ReadInt(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read cache count")
+	}
+	for i := 0; i < int(count); i++ {
+		id, err := ReadInt(r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read cache ID")
+		}
+		caches[id] = a
+		if !applicable {
+			continue
+		}
+		// key configurations are used for complex object keys only, they are not mapped by the client
+		keys, err := ReadInt(r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read key configuration count")
+		}
+		for j := 0; j < 2*int(keys); j++ {
+			if _, err = ReadInt(r); err != nil {
+				return errors.Wrapf(err, "failed to read key configuration")
+			}
+		}
+	}
+	if !applicable {
+		return nil
+	}
+
+	nodes, err := ReadInt(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read node count")
+	}
+	for i := 0; i < int(nodes); i++ {
+		id, err := ReadOUUID(r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read node ID")
+		}
+		parts, err := ReadInt(r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read partition count")
+		}
+		for j := 0; j < int(parts); j++ {
+			part, err := ReadInt(r)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read partition")
+			}
+			if part < 0 {
+				return errors.Errorf("invalid partition %d", part)
+			}
+			for int(part) >= len(a.nodes) {
+				a.nodes = append(a.nodes, uuid.UUID{})
+			}
+			a.nodes[part] = id
+		}
+	}
+	return nil
+}
+
+// affinityKey returns cache ID and Java hash code of the key of single key operation request
+func affinityKey(message []byte) (int32, int32, bool) {
+	if len(message) < requestHeaderLength {
+		return 0, 0, false
+	}
+	switch binary.LittleEndian.Uint16(message[4:]) {
+	case OpCacheGet, OpCachePut, OpCachePutIfAbsent, OpCacheGetAndPut, OpCacheGetAndReplace,
+		OpCacheGetAndRemove, OpCacheGetAndPutIfAbsent, OpCacheReplace, OpCacheReplaceIfEquals,
+		OpCacheContainsKey, OpCacheClearKey, OpCacheRemoveKey, OpCacheRemoveIfEquals:
+	default:
+		return 0, 0, false
+	}
+
+	// request starts with cache ID and flags
+	data := message[requestHeaderLength:]
+	if len(data) < 4+1 {
+		return 0, 0, false
+	}
+	cacheID := int32(binary.LittleEndian.Uint32(data))
+	flags := data[4]
+	data = data[4+1:]
+	if flags&TransactionalFlagMask != 0 {
+		return 0, 0, false
+	}
+	if flags&WithExpiryPolicyFlagMask != 0 {
+		// expiry policy contains create, update and access durations
+		if len(data) < 3*8 {
+			return 0, 0, false
+		}
+		data = data[3*8:]
+	}
+
+	hash, ok := javaHashCode(data)
+	return cacheID, hash, ok
+}
+
+// javaHashCode calculates Java hash code of the object serialized to data.
+// Returns false if hash code of the object type is not supported.
+func javaHashCode(data []byte) (int32, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	t, data := data[0], data[1:]
+
+	// check size of the fixed length value
+	size := 0
+	switch t {
+	case typeByte, typeBool:
+		size = 1
+	case typeShort, typeChar:
+		size = 2
+	case typeInt, typeFloat:
+		size = 4
+	case typeLong, typeDouble, typeDate, typeTimestamp:
+		size = 8
+	case typeUUID:
+		size = 16
+	}
+	if len(data) < size {
+		return 0, false
+	}
+
+	switch t {
+	case typeByte:
+		return int32(int8(data[0])), true
+	case typeShort:
+		return int32(int16(binary.LittleEndian.Uint16(data))), true
+	case typeInt, typeFloat:
+		// Float.hashCode is its bits
+		return int32(binary.LittleEndian.Uint32(data)), true
+	case typeLong, typeDouble, typeDate, typeTimestamp:
+		// Date.hashCode is hash code of its milliseconds
+		return longHashCode(binary.LittleEndian.Uint64(data)), true
+	case typeChar:
+		return int32(binary.LittleEndian.Uint16(data)), true
+	case typeBool:
+		if data[0] != 0 {
+			return 1231, true
+		}
+		return 1237, true
+	case typeUUID:
+		return longHashCode(binary.LittleEndian.Uint64(data) ^ binary.LittleEndian.Uint64(data[8:])), true
+	case typeString:
+		if len(data) < 4 {
+			return 0, false
+		}
+		l := int(int32(binary.LittleEndian.Uint32(data)))
+		if l < 0 || len(data) < 4+l {
+			return 0, false
+		}
+		// Java strings are UTF-16 encoded
+		h := int32(0)
+		for _, c := range utf16.Encode([]rune(string(data[4 : 4+l]))) {
+			h = 31*h + int32(c)
+		}
+		return h, true
+	default:
+		return 0, false
+	}
+}
+
+// longHashCode calculates Java hash code of long value
+func longHashCode(v uint64) int32 {
+	return int32(v ^ v>>32)
+}
+
+// partition calculates partition of the key with Java hash code hash the same way
+// as the default Apache Ignite affinity function does
+func partition(hash int32, parts int) int {
+	if parts&(parts-1) == 0 {
+		h := uint32(hash)
+		return int((h ^ h>>16) & uint32(parts-1))
+	}
+	p := hash % int32(parts)
+	if p < 0 {
+		p = -p
+	}
+	return int(p)
+}
@@ -0,0 +1,176 @@
+package ignite
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	stderrors "errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func Test_javaHashCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    interface{}
+		want   int32
+		wantOk bool
+	}{
+		{name: "byte", key: byte(200), want: -56, wantOk: true},
+		{name: "short", key: int16(-7), want: -7, wantOk: true},
+		{name: "int", key: int32(42), want: 42, wantOk: true},
+		{name: "long", key: int64(1) << 32, want: 1, wantOk: true},
+		{name: "double", key: float64(1.5), want: 1073217536, wantOk: true},
+		{name: "bool true", key: true, want: 1231, wantOk: true},
+		{name: "bool false", key: false, want: 1237, wantOk: true},
+		{name: "string", key: "abc", want: 96354, wantOk: true},
+		{name: "string UTF-16", key: "Привет😀", want: 1536742923, wantOk: true},
+		{name: "UUID", key: uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"), want: 963287497, wantOk: true},
+		{name: "byte array", key: []byte{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &bytes.Buffer{}
+			if err := WriteObject(data, tt.key); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			got, ok := javaHashCode(data.Bytes())
+			if ok != tt.wantOk {
+				t.Fatalf("javaHashCode() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("javaHashCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_partition(t *testing.T) {
+	tests := []struct {
+		name  string
+		hash  int32
+		parts int
+		want  int
+	}{
+		{name: "power of two", hash: 1, parts: 1024, want: 1},
+		{name: "power of two high bits", hash: 0x10000, parts: 1024, want: 1},
+		{name: "power of two negative", hash: -1, parts: 1024, want: 0},
+		{name: "not power of two", hash: 12345, parts: 1000, want: 345},
+		{name: "not power of two negative", hash: -5, parts: 1000, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partition(tt.hash, tt.parts); got != tt.want {
+				t.Errorf("partition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnect_PartitionAwareness(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	cacheID := HashCode("cache")
+
+	// partition 0 belongs to the first node, partition 1 belongs to the second node
+	partitions := appendInt64(nil, 1)
+	partitions = appendInt32(partitions, 0)
+	partitions = appendInt32(partitions, 1)
+	partitions = append(partitions, 1)
+	partitions = appendInt32(partitions, 1)
+	partitions = appendInt32(partitions, cacheID)
+	partitions = appendInt32(partitions, 0)
+	partitions = appendInt32(partitions, 2)
+	for i, id := range ids {
+		b := &bytes.Buffer{}
+		_ = WriteOUUID(b, id)
+		partitions = append(partitions, b.Bytes()...)
+		partitions = appendInt32(partitions, 1)
+		partitions = appendInt32(partitions, int32(i))
+	}
+
+	var mutex sync.Mutex
+	// keys received by the nodes
+	keys := map[int][]int32{}
+	handler := func(node int) testHandler {
+		return func(conn int, code int16, data []byte) ([]byte, error) {
+			switch code {
+			case OpCachePartitions:
+				return partitions, nil
+			case OpCacheGet:
+				mutex.Lock()
+				keys[node] = append(keys[node], int32(binary.LittleEndian.Uint32(data[4+1+1:])))
+				mutex.Unlock()
+			}
+			return []byte{typeNULL}, nil
+		}
+	}
+	s1 := newTestNode(t, ids[0], handler(0))
+	defer s1.close()
+	s2 := newTestNode(t, ids[1], handler(1))
+	defer s2.close()
+
+	ci := s1.connInfo()
+	ci.Endpoints = []Endpoint{s1.endpoint(), s2.endpoint()}
+	ci.PartitionAwareness = true
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	for _, key := range []int32{0, 1, 2, 3} {
+		if _, err = c.CacheGet("cache", false, key); err != nil {
+			t.Errorf("Client.CacheGet() error = %v", err)
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for node, want := range [][]int32{{0, 2}, {1, 3}} {
+		got := keys[node]
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("node %d received keys %v, want %v", node, got, want)
+		}
+	}
+}
+
+func TestConnect_PartitionAwarenessUnavailable(t *testing.T) {
+	var requests int32
+	s := newTestNode(t, uuid.New(), func(conn int, code int16, data []byte) ([]byte, error) {
+		if code == OpCachePartitions {
+			atomic.AddInt32(&requests, 1)
+			return nil, stderrors.New("partitions are not available")
+		}
+		return []byte{typeNULL}, nil
+	})
+	defer s.close()
+
+	ci := s.connInfo()
+	ci.PartitionAwareness = true
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+	// the node reported affinity topology version
+	conn, err := c.(*client).conn.(*partitioned).def.connection(context.Background())
+	if err != nil {
+		t.Fatalf("failover.connection() error = %v", err)
+	}
+	conn.mutex.Lock()
+	conn.topology = affinityVersion{major: 1}
+	conn.mutex.Unlock()
+
+	for _, key := range []int32{0, 1, 2} {
+		if _, err = c.CacheGet("cache", false, key); err != nil {
+			t.Errorf("Client.CacheGet() error = %v", err)
+		}
+	}
+	// failure is cached until topology is changed
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("partitions are requested %d times, want 1", got)
+	}
+}
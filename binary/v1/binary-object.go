@@ -0,0 +1,199 @@
+package ignite
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// BinaryObject is object wrapped in byte array ("wrapped binary object" type).
+// Cache operations with binary (keep binary) flag return wrapped objects as BinaryObject
+// which keeps bytes received from the cluster: fields are read on demand without reading the whole object
+// and the object is written back unchanged, so it can be put to another cache without conversion.
+type BinaryObject struct {
+	// data is the wrapping byte array
+	data []byte
+	// offset is offset of the object in data
+	offset int32
+	// resolver resolves schemas of the objects with compact footers, it is set if the object is read
+	// from the response of the client
+	resolver schemaResolver
+}
+
+// NewBinaryObject returns binary object wrapping the object written to data at offset
+func NewBinaryObject(data []byte, offset int32) (BinaryObject, error) {
+	if offset < 0 || int(offset) >= len(data) {
+		return BinaryObject{}, errors.Errorf("invalid binary object offset %d, byte array length is %d", offset, len(data))
+	}
+	return BinaryObject{data: data, offset: offset}, nil
+}
+
+// Bytes returns bytes of the object including type code
+func (o BinaryObject) Bytes() []byte {
+	return o.data[o.offset:]
+}
+
+// TypeID returns type ID of the complex object
+func (o BinaryObject) TypeID() (int32, error) {
+	b, err := o.complexObject()
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b[4:])), nil
+}
+
+// Get reads value of the field with name field, false is returned if the object has no field
+func (o BinaryObject) Get(field string) (interface{}, bool, error) {
+	return o.Field(HashCode(field))
+}
+
+// Field reads value of the field with ID fieldID, false is returned if the object has no field.
+// Only the field is read, other fields of the object are skipped.
+// Unknown schema of the object with compact footer is got from the cluster if the object is received by the client.
+func (o BinaryObject) Field(fieldID int32) (interface{}, bool, error) {
+	b, err := o.complexObject()
+	if err != nil {
+		return nil, false, err
+	}
+	flags := int16(binary.LittleEndian.Uint16(b[2:]))
+	if flags&ComplexObjectHasSchema == 0 {
+		// object has no fields
+		return nil, false, nil
+	}
+	typeID := int32(binary.LittleEndian.Uint32(b[4:]))
+	length := int(int32(binary.LittleEndian.Uint32(b[12:])))
+	schemaID := int32(binary.LittleEndian.Uint32(b[16:]))
+	schemaOffset := int(int32(binary.LittleEndian.Uint32(b[20:])))
+	if length < ComplexObjectHeaderLength || length > len(b) {
+		return nil, false, errors.Errorf("invalid complex object length %d, binary object length is %d", length, len(b))
+	}
+	footerEnd := length
+	if flags&ComplexObjectHasRaw != 0 {
+		// raw data offset follows the footer
+		footerEnd -= 4
+	}
+	if schemaOffset < ComplexObjectHeaderLength || schemaOffset > footerEnd {
+		return nil, false, errors.Errorf("invalid complex object schema offset %d, object length is %d", schemaOffset, length)
+	}
+
+	step := 4
+	if flags&ComplexObjectOffsetOneByte != 0 {
+		step = 1
+	} else if flags&ComplexObjectOffsetTwoBytes != 0 {
+		step = 2
+	}
+	// compact footer has no field IDs, they are taken from the known schema
+	var compact *schema
+	entry := step + 4
+	if flags&ComplexObjectCompactFooter != 0 {
+		if compact, err = lookupSchema(o.resolver, typeID, schemaID); err != nil {
+			return nil, false, err
+		}
+		entry = step
+	}
+
+	for i, pos := 0, schemaOffset; pos+entry <= footerEnd; i, pos = i+1, pos+entry {
+		var id int32
+		if compact != nil {
+			if i >= len(compact.fields) {
+				return nil, false, errors.Errorf("object has more fields than schema %d of binary type %d", schemaID, typeID)
+			}
+			id = compact.fields[i]
+		} else {
+			id = int32(binary.LittleEndian.Uint32(b[pos:]))
+		}
+		if id != fieldID {
+			continue
+		}
+
+		p := pos + entry - step
+		var fieldOffset int
+		switch step {
+		case 1:
+			fieldOffset = int(b[p])
+		case 2:
+			fieldOffset = int(binary.LittleEndian.Uint16(b[p:]))
+		default:
+			fieldOffset = int(int32(binary.LittleEndian.Uint32(b[p:])))
+		}
+		if fieldOffset < ComplexObjectHeaderLength || fieldOffset >= schemaOffset {
+			return nil, false, errors.Errorf("invalid offset %d of field with index %d", fieldOffset, i)
+		}
+		v, err := ReadObject(&decoder{b: b[fieldOffset:schemaOffset], resolver: o.resolver})
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to read field data with index %d", i)
+		}
+		return v, true, nil
+	}
+	return nil, false, nil
+}
+
+// Deserialize reads the whole object, complex object is read as ComplexObject
+func (o BinaryObject) Deserialize() (interface{}, error) {
+	return ReadObject(&decoder{b: o.Bytes(), resolver: o.resolver})
+}
+
+// Unmarshal reads the whole object into the value pointed to by dst the same way as ReadObjectInto
+func (o BinaryObject) Unmarshal(dst interface{}) error {
+	return ReadObjectInto(&decoder{b: o.Bytes(), resolver: o.resolver}, dst)
+}
+
+// WriteBinary writes the object wrapped in byte array unchanged, it implements BinaryMarshaler
+func (o BinaryObject) WriteBinary(w io.Writer) error {
+	return WriteOBinaryObject(w, o)
+}
+
+// complexObject returns bytes of the object, it fails if the object is not complex object
+func (o BinaryObject) complexObject() ([]byte, error) {
+	b := o.Bytes()
+	if len(b) < ComplexObjectHeaderLength || b[0] != typeComplexObject {
+		return nil, errors.Errorf("binary object is not complex object")
+	}
+	return b, nil
+}
+
+// WriteOBinaryObject writes "wrapped binary object" object value
+func WriteOBinaryObject(w io.Writer, v BinaryObject) error {
+	if err := WriteType(w, typeBinaryObjectArray); err != nil {
+		return err
+	}
+	if err := WriteInt(w, int32(len(v.data))); err != nil {
+		return err
+	}
+	if err := WriteBytes(w, v.data); err != nil {
+		return err
+	}
+	return WriteInt(w, v.offset)
+}
+
+// ReadBinaryObject reads "wrapped binary object" value keeping the bytes of the object
+func ReadBinaryObject(r io.Reader) (BinaryObject, error) {
+	b, err := ReadArrayBytes(r)
+	if err != nil {
+		return BinaryObject{}, err
+	}
+	offset, err := ReadInt(r)
+	if err != nil {
+		return BinaryObject{}, err
+	}
+	o, err := NewBinaryObject(b, offset)
+	o.resolver = resolverOf(r)
+	return o, err
+}
+
+// readCacheObject reads object of the cache operation result.
+// Wrapped binary objects are kept as BinaryObject if binary (keep binary) flag of the operation is set.
+func readCacheObject(r io.Reader, binary bool) (interface{}, error) {
+	if !binary {
+		return ReadObject(r)
+	}
+	t, err := ReadByte(r)
+	if err != nil {
+		return nil, err
+	}
+	if t == typeBinaryObjectArray {
+		return ReadBinaryObject(r)
+	}
+	return readObject(r, t)
+}
@@ -0,0 +1,157 @@
+package ignite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryObject(t *testing.T) {
+	type Person struct {
+		Name string `ignite:"name"`
+		Age  int32  `ignite:"age"`
+	}
+	o := NewComplexObject("TestBinaryObject")
+	o.Set("name", "Ivan")
+	o.Set("age", int32(42))
+	o.Raw = []byte{1, 2, 3}
+	w := &bytes.Buffer{}
+	if err := WriteOComplexObject(w, o); err != nil {
+		t.Fatalf("WriteOComplexObject() error = %v", err)
+	}
+	// object is wrapped with some data before it
+	data := append([]byte{7, 7, 7}, w.Bytes()...)
+	wrapped := &bytes.Buffer{}
+	if err := WriteObject(wrapped, BinaryObject{data: data, offset: 3}); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+
+	v, err := readCacheObject(bytes.NewReader(wrapped.Bytes()), true)
+	if err != nil {
+		t.Fatalf("readCacheObject() error = %v", err)
+	}
+	b, ok := v.(BinaryObject)
+	if !ok {
+		t.Fatalf("readCacheObject() = %T, want BinaryObject", v)
+	}
+	if !reflect.DeepEqual(b.Bytes(), w.Bytes()) {
+		t.Errorf("BinaryObject.Bytes() = %v, want %v", b.Bytes(), w.Bytes())
+	}
+	if typeID, err := b.TypeID(); err != nil || typeID != o.Type {
+		t.Errorf("BinaryObject.TypeID() = %v, %v, want %v", typeID, err, o.Type)
+	}
+
+	tests := []struct {
+		name   string
+		field  string
+		want   interface{}
+		wantOK bool
+	}{
+		{
+			name:   "string",
+			field:  "name",
+			want:   "Ivan",
+			wantOK: true,
+		},
+		{
+			name:   "int",
+			field:  "age",
+			want:   int32(42),
+			wantOK: true,
+		},
+		{
+			name:  "unknown",
+			field: "salary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := b.Get(tt.field)
+			if err != nil {
+				t.Fatalf("BinaryObject.Get() error = %v", err)
+			}
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BinaryObject.Get() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	// whole object
+	got, err := b.Deserialize()
+	if err != nil {
+		t.Fatalf("BinaryObject.Deserialize() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, o) {
+		t.Errorf("BinaryObject.Deserialize() = %v, want %v", got, o)
+	}
+	var p Person
+	if err = UnmarshalObject(b, &p); err != nil {
+		t.Fatalf("UnmarshalObject() error = %v", err)
+	}
+	if p != (Person{Name: "Ivan", Age: 42}) {
+		t.Errorf("UnmarshalObject() = %v, want %v", p, Person{Name: "Ivan", Age: 42})
+	}
+
+	// unchanged bytes are written back
+	w2 := &bytes.Buffer{}
+	if err = WriteObject(w2, &b); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+	if !reflect.DeepEqual(w2.Bytes(), wrapped.Bytes()) {
+		t.Errorf("WriteObject() = %v, want %v", w2.Bytes(), wrapped.Bytes())
+	}
+
+	// wrapped object is unwrapped without binary flag
+	v, err = readCacheObject(bytes.NewReader(wrapped.Bytes()), false)
+	if err != nil {
+		t.Fatalf("readCacheObject() error = %v", err)
+	}
+	if !reflect.DeepEqual(v, o) {
+		t.Errorf("readCacheObject() = %v, want %v", v, o)
+	}
+}
+
+func TestBinaryObject_CompactFooter(t *testing.T) {
+	typeID := HashCode("TestBinaryObject_CompactFooter")
+	ids := []int32{HashCode("a"), HashCode("b")}
+	schemas.put(typeID, ids, true, true)
+
+	// nested object is written with compact footer too
+	o := ComplexObject{Type: typeID, Fields: map[int32]interface{}{
+		ids[0]: int32(1),
+		ids[1]: ComplexObject{Type: typeID, Fields: map[int32]interface{}{ids[0]: int32(2), ids[1]: "b"}},
+	}}
+	w := NewRequestOperation(OpCachePut)
+	w.schemas = &registeredSchemas{}
+	w.schemas.add(typeID, ids)
+	if err := WriteObject(w, o); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+	b, err := NewBinaryObject(w.payload.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("NewBinaryObject() error = %v", err)
+	}
+	got, ok, err := b.Get("b")
+	if err != nil || !ok {
+		t.Fatalf("BinaryObject.Get() = %v, %v, %v, want nested object", got, ok, err)
+	}
+	nested, ok := got.(ComplexObject)
+	if !ok || nested.Fields[ids[1]] != "b" {
+		t.Errorf("BinaryObject.Get() = %v, want nested object", got)
+	}
+	for _, pos := range []int{2, 24 + 5 + 2} {
+		// flags of the object and the nested one written after the first field
+		if flags := int16(binary.LittleEndian.Uint16(w.payload.Bytes()[pos:])); flags&ComplexObjectCompactFooter == 0 {
+			t.Errorf("flags at %d = %#x, compact footer is not set", pos, flags)
+		}
+	}
+
+	if _, err = NewBinaryObject(w.payload.Bytes(), int32(w.length())); err == nil {
+		t.Errorf("NewBinaryObject() error = nil for offset out of range")
+	}
+	s, _ := NewBinaryObject([]byte{9, 1, 0, 0, 0, 97}, 0)
+	if _, _, err = s.Get("a"); err == nil {
+		t.Errorf("BinaryObject.Get() error = nil for string")
+	}
+}
@@ -0,0 +1,293 @@
+package ignite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// maxPooledBufferSize is the capacity of the buffer above which it is not returned to the pool,
+// so occasional large requests do not keep memory allocated
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool contains buffers requests are encoded to
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+// decoderPool contains decoders frames of the responses are passed to responses through
+var decoderPool = sync.Pool{
+	New: func() interface{} {
+		return &decoder{}
+	},
+}
+
+// getBuffer returns empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buffer to the pool.
+// The buffer must not be used after that.
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBufferSize {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// getDecoder returns decoder of the message from the pool
+func getDecoder(message []byte) *decoder {
+	d := decoderPool.Get().(*decoder)
+	d.b, d.off = message, 0
+	return d
+}
+
+// putDecoder returns decoder to the pool.
+// The decoder must not be used after that.
+func putDecoder(d *decoder) {
+	d.b, d.off = nil, 0
+	decoderPool.Put(d)
+}
+
+// bufferWriter is writer which encodes values to the memory buffer
+type bufferWriter interface {
+	buffer() *bytes.Buffer
+}
+
+// writeBuffer returns memory buffer of w or nil if w does not write to the memory.
+// Values are appended to the memory buffer directly without allocation.
+func writeBuffer(w io.Writer) *bytes.Buffer {
+	switch w := w.(type) {
+	case *bytes.Buffer:
+		return w
+	case bufferWriter:
+		return w.buffer()
+	}
+	return nil
+}
+
+// writeUint16 writes 2 bytes value in little-endian order
+func writeUint16(w io.Writer, v uint16) error {
+	if b := writeBuffer(w); b != nil {
+		var a [2]byte
+		binary.LittleEndian.PutUint16(a[:], v)
+		_, err := b.Write(a[:])
+		return err
+	}
+	a := make([]byte, 2)
+	binary.LittleEndian.PutUint16(a, v)
+	_, err := w.Write(a)
+	return err
+}
+
+// writeUint32 writes 4 bytes value in little-endian order
+func writeUint32(w io.Writer, v uint32) error {
+	if b := writeBuffer(w); b != nil {
+		var a [4]byte
+		binary.LittleEndian.PutUint32(a[:], v)
+		_, err := b.Write(a[:])
+		return err
+	}
+	a := make([]byte, 4)
+	binary.LittleEndian.PutUint32(a, v)
+	_, err := w.Write(a)
+	return err
+}
+
+// writeUint64 writes 8 bytes value in little-endian order
+func writeUint64(w io.Writer, v uint64) error {
+	if b := writeBuffer(w); b != nil {
+		var a [8]byte
+		binary.LittleEndian.PutUint64(a[:], v)
+		_, err := b.Write(a[:])
+		return err
+	}
+	a := make([]byte, 8)
+	binary.LittleEndian.PutUint64(a, v)
+	_, err := w.Write(a)
+	return err
+}
+
+// writeArray writes count elements of the array encoded by put to size bytes every one in little-endian order.
+// Elements are encoded straight to the memory buffer of w, other writers get the whole array
+// encoded to the buffer from the pool.
+func writeArray(w io.Writer, count int, size int, put func(b []byte, i int)) error {
+	b := writeBuffer(w)
+	direct := b != nil
+	if !direct {
+		b = getBuffer()
+		defer putBuffer(b)
+	}
+	n := count * size
+	b.Grow(n)
+	// free space of the buffer is filled and appended to the buffer contents then
+	a := b.Bytes()
+	a = a[len(a) : len(a)+n]
+	for i := 0; i < count; i++ {
+		put(a[i*size:], i)
+	}
+	b.Write(a)
+	if direct {
+		return nil
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// sliceReader is reader which returns the next bytes of the message without copying
+type sliceReader interface {
+	// next returns the next n bytes of the message.
+	// Returned slice is valid until the next read only.
+	next(n int) ([]byte, error)
+}
+
+// sizedReader is reader which knows length of the unread part of the message
+type sizedReader interface {
+	// remaining returns count of the unread bytes of the message, -1 if it is unknown
+	remaining() int64
+}
+
+// remaining returns count of the unread bytes of the message read by r, -1 if it is unknown
+func remaining(r io.Reader) int64 {
+	switch r := r.(type) {
+	case sizedReader:
+		return r.remaining()
+	case *io.LimitedReader:
+		return r.N
+	case interface{ Len() int }:
+		return int64(r.Len())
+	}
+	return -1
+}
+
+// checkLength returns error if count of elements of size bytes at least is negative
+// or the elements do not fit in the unread part of the message read by r.
+// It is called before memory for the elements is allocated.
+func checkLength(r io.Reader, count int32, size int64) error {
+	if count < 0 {
+		return errors.Errorf("invalid length %d", count)
+	}
+	if left := remaining(r); left >= 0 && int64(count)*size > left {
+		return errors.Errorf("length %d exceeds the unread part of the message (%d bytes)", count, left)
+	}
+	return nil
+}
+
+// readLength reads count of elements of size bytes at least and checks it by checkLength
+func readLength(r io.Reader, size int64) (int, error) {
+	l, err := ReadInt(r)
+	if err != nil {
+		return 0, err
+	}
+	if err = checkLength(r, l, size); err != nil {
+		return 0, err
+	}
+	return int(l), nil
+}
+
+// maxUncheckedCapacity is the maximum capacity of the slice allocated for the elements
+// which can't be checked to fit in the message
+const maxUncheckedCapacity = 1024
+
+// capacity returns capacity of the slice for count elements read from r.
+// If length of the message is unknown the slice grows while the elements are actually read.
+func capacity(r io.Reader, count int) int {
+	if count > maxUncheckedCapacity && remaining(r) < 0 {
+		return maxUncheckedCapacity
+	}
+	return count
+}
+
+// readNext returns the next n bytes read from r.
+// Returned slice is valid until the next read from r only.
+func readNext(r io.Reader, n int) ([]byte, error) {
+	if s, ok := r.(sliceReader); ok {
+		return s.next(n)
+	}
+	left := remaining(r)
+	if n < 0 || (left >= 0 && int64(n) > left) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if left < 0 && n > maxPooledBufferSize {
+		// length of the message is unknown, memory is allocated while the data is actually read
+		b, err := ioutil.ReadAll(io.LimitReader(r, int64(n)))
+		if err != nil {
+			return nil, err
+		}
+		if len(b) < n {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return b, nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readBytes returns the next n bytes read from r in the new slice
+func readBytes(r io.Reader, n int) ([]byte, error) {
+	data, err := readNext(r, n)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := r.(sliceReader); !ok {
+		// readNext allocated the slice
+		return data, nil
+	}
+	b := make([]byte, n)
+	copy(b, data)
+	return b, nil
+}
+
+// decoder reads the message from the memory without copying
+type decoder struct {
+	b   []byte
+	off int
+	// resolver resolves schemas of the objects with compact footers, it is nil if they are not resolved
+	resolver schemaResolver
+}
+
+// Read reads up to len(p) bytes of the message into p
+func (d *decoder) Read(p []byte) (int, error) {
+	if d.off >= len(d.b) {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n := copy(p, d.b[d.off:])
+	d.off += n
+	return n, nil
+}
+
+// remaining returns count of the unread bytes of the message
+func (d *decoder) remaining() int64 {
+	return int64(len(d.b) - d.off)
+}
+
+// next returns the next n bytes of the message
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if left := len(d.b) - d.off; left < n {
+		d.off = len(d.b)
+		if left == 0 {
+			return nil, io.EOF
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.b[d.off : d.off+n : d.off+n]
+	d.off += n
+	return b, nil
+}
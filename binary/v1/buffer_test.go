@@ -0,0 +1,327 @@
+package ignite
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func Test_decoder_next(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		n       []int
+		want    [][]byte
+		wantErr error
+	}{
+		{
+			name: "1",
+			b:    []byte{1, 2, 3},
+			n:    []int{1, 2},
+			want: [][]byte{{1}, {2, 3}},
+		},
+		{
+			name:    "2",
+			b:       []byte{1, 2, 3},
+			n:       []int{2, 2},
+			want:    [][]byte{{1, 2}},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "3",
+			b:       []byte{1},
+			n:       []int{1, 1},
+			want:    [][]byte{{1}},
+			wantErr: io.EOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &decoder{b: tt.b}
+			var got [][]byte
+			var err error
+			for _, n := range tt.n {
+				var b []byte
+				if b, err = d.next(n); err != nil {
+					break
+				}
+				got = append(got, b)
+			}
+			if err != tt.wantErr {
+				t.Errorf("decoder.next() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoder.next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestCachePut encodes OP_CACHE_PUT request message to w
+func writeTestCachePut(w io.Writer, key int64, value string) error {
+	req := NewRequestOperation(OpCachePut)
+	_ = WriteInt(req, HashCode("TestCache"))
+	_ = WriteBool(req, false)
+	_ = WriteOLong(req, key)
+	_ = WriteOString(req, value)
+	_, err := req.WriteTo(w)
+	return err
+}
+
+// testCacheGetResponse returns OP_CACHE_GET response message with string value
+func testCacheGetResponse(uid int64, value string) []byte {
+	b := &bytes.Buffer{}
+	_ = WriteInt(b, 0)
+	_ = WriteLong(b, uid)
+	_ = WriteInt(b, OperationStatusSuccess)
+	_ = WriteOString(b, value)
+	frame := b.Bytes()
+	frame[0] = byte(len(frame) - 4)
+	return frame
+}
+
+func TestEncoding_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not stable with race detector")
+	}
+	frame := testCacheGetResponse(1, "value")
+	longs := make([]int64, 1000)
+	tests := []struct {
+		name string
+		fn   func()
+		want float64
+	}{
+		{
+			name: "OP_CACHE_PUT request",
+			fn: func() {
+				msg := getBuffer()
+				_ = writeTestCachePut(msg, 1, "value")
+				putBuffer(msg)
+			},
+			// request object only
+			want: 1,
+		},
+		{
+			name: "OP_CACHE_GET response",
+			fn: func() {
+				res := NewResponseOperation(1)
+				d := getDecoder(frame)
+				_, _ = res.ReadFrom(d)
+				putDecoder(d)
+				_, _ = ReadOString(res)
+			},
+			// response object and value
+			want: 2,
+		},
+		{
+			name: "long array",
+			fn: func() {
+				msg := getBuffer()
+				_ = WriteOArrayLongs(msg, longs)
+				putBuffer(msg)
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(100, tt.fn); got > tt.want {
+				t.Errorf("allocations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteArray(t *testing.T) {
+	shorts := make([]int16, 1000)
+	doubles := make([]float64, 100)
+	bools := make([]bool, 100)
+	for i := range shorts {
+		shorts[i] = int16(i - 100)
+	}
+	for i := range doubles {
+		doubles[i] = float64(i) / 3
+	}
+	for i := range bools {
+		bools[i] = i%3 == 0
+	}
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "shorts", v: shorts},
+		{name: "doubles", v: doubles},
+		{name: "bools", v: bools},
+		{name: "empty", v: []int32{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// writer without memory buffer
+			b := &bytes.Buffer{}
+			w := struct{ io.Writer }{b}
+			if err := WriteObject(w, tt.v); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			got, err := ReadObject(b)
+			if err != nil {
+				t.Fatalf("ReadObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.v) {
+				t.Errorf("ReadObject() = %v, want %v", got, tt.v)
+			}
+		})
+	}
+}
+
+func TestEncoding_RoundTrip(t *testing.T) {
+	frame := testCacheGetResponse(123, "value")
+	res := NewResponseOperation(123)
+	d := getDecoder(frame)
+	if _, err := res.ReadFrom(d); err != nil {
+		t.Fatalf("ResponseOperation.ReadFrom() error = %v", err)
+	}
+	putDecoder(d)
+	got, err := ReadOString(res)
+	if err != nil {
+		t.Fatalf("ReadOString() error = %v", err)
+	}
+	if got != "value" {
+		t.Errorf("ReadOString() = %v, want %v", got, "value")
+	}
+	if _, err = ReadByte(res); err != io.EOF {
+		t.Errorf("ReadByte() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func BenchmarkEncodeCachePut(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg := getBuffer()
+		if err := writeTestCachePut(msg, int64(i), "value"); err != nil {
+			b.Fatal(err)
+		}
+		putBuffer(msg)
+	}
+}
+
+func BenchmarkEncodeCacheGet(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg := getBuffer()
+		req := NewRequestOperation(OpCacheGet)
+		_ = WriteInt(req, HashCode("TestCache"))
+		_ = WriteBool(req, false)
+		_ = WriteOLong(req, int64(i))
+		if _, err := req.WriteTo(msg); err != nil {
+			b.Fatal(err)
+		}
+		putBuffer(msg)
+	}
+}
+
+func BenchmarkDecodeCacheGet(b *testing.B) {
+	frame := testCacheGetResponse(1, "value")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res := NewResponseOperation(1)
+		d := getDecoder(frame)
+		if _, err := res.ReadFrom(d); err != nil {
+			b.Fatal(err)
+		}
+		putDecoder(d)
+		if _, err := ReadObject(res); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReadObject_InvalidLength(t *testing.T) {
+	// oversized is length which exceeds the message
+	oversized := []byte{0xFF, 0xFF, 0xFF, 0x0F}
+	negative := []byte{0xFE, 0xFF, 0xFF, 0xFF}
+
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{
+			name: "negative byte array length",
+			b:    append([]byte{typeByteArray}, negative...),
+		},
+		{
+			name: "oversized byte array length",
+			b:    append([]byte{typeByteArray}, oversized...),
+		},
+		{
+			name: "negative long array length",
+			b:    append([]byte{typeLongArray}, negative...),
+		},
+		{
+			name: "oversized long array length",
+			b:    append(append([]byte{typeLongArray}, 2, 0, 0, 0), make([]byte, 15)...),
+		},
+		{
+			name: "negative string length",
+			b:    append([]byte{typeString}, negative[:3]...),
+		},
+		{
+			name: "oversized string length",
+			b:    append([]byte{typeString}, oversized...),
+		},
+		{
+			name: "oversized string array length",
+			b:    append([]byte{typeStringArray}, oversized...),
+		},
+		{
+			name: "oversized inner string length",
+			b:    append([]byte{typeStringArray, 1, 0, 0, 0, typeString}, oversized...),
+		},
+		{
+			name: "negative inner byte array length",
+			b:    append([]byte{typeObjectArray, 0xFF, 0xFF, 0xFF, 0xFF, 1, 0, 0, 0, typeByteArray}, negative...),
+		},
+		{
+			name: "oversized object array length",
+			b:    append([]byte{typeObjectArray, 0xFF, 0xFF, 0xFF, 0xFF}, oversized...),
+		},
+		{
+			name: "oversized collection length",
+			b:    append(append([]byte{typeCollection}, oversized...), 1),
+		},
+		{
+			name: "oversized map length",
+			b:    append(append([]byte{typeMap}, 1, 0, 0, 0), 1, typeNULL),
+		},
+		{
+			name: "oversized decimal array length",
+			b:    append([]byte{typeDecimalArray}, oversized...),
+		},
+		{
+			name: "negative enum array length",
+			b:    append([]byte{typeEnumArray, 0, 0, 0, 0}, negative...),
+		},
+		{
+			name: "oversized complex object length",
+			b: []byte{typeComplexObject, 1, 0x2, 0, 1, 0, 0, 0, 0, 0, 0, 0,
+				0xFF, 0xFF, 0xFF, 0x0F, 0, 0, 0, 0, 24, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readers := map[string]io.Reader{
+				"decoder": &decoder{b: tt.b},
+				"reader":  bytes.NewReader(tt.b),
+				"stream":  io.LimitReader(bytes.NewReader(tt.b), int64(len(tt.b))),
+				// length of the message is unknown
+				"unsized": struct{ io.Reader }{bytes.NewReader(tt.b)},
+			}
+			for name, r := range readers {
+				if o, err := ReadObject(r); err == nil {
+					t.Errorf("ReadObject() from %s = %v, want error", name, o)
+				}
+			}
+		})
+	}
+}
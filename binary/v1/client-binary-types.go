@@ -0,0 +1,489 @@
+package ignite
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+const (
+	// PlatformJava is Java platform ID of the type name mapping
+	PlatformJava = 0
+	// PlatformDotNet is .NET platform ID of the type name mapping
+	PlatformDotNet = 1
+)
+
+// BinaryType is metadata of the binary type
+type BinaryType struct {
+	// TypeID is ID of the type
+	TypeID int32
+	// TypeName is name of the type
+	TypeName string
+	// AffinityKeyFieldName is name of the affinity key field, it is empty if the type has no affinity key field
+	AffinityKeyFieldName string
+	// Fields are fields of the type
+	Fields []BinaryField
+	// IsEnum is true if the type is enum
+	IsEnum bool
+	// EnumValues are values of the enum type
+	EnumValues []BinaryEnumValue
+	// Schemas are schemas of the type, every schema is set of fields of the object
+	Schemas []BinarySchema
+}
+
+// BinaryField is field of the binary type
+type BinaryField struct {
+	// Name is name of the field
+	Name string
+	// TypeCode is type code of the field value
+	TypeCode int32
+	// ID is ID of the field
+	ID int32
+}
+
+// BinaryEnumValue is value of the enum binary type
+type BinaryEnumValue struct {
+	// Name is name of the value
+	Name string
+	// Ordinal is ordinal of the value
+	Ordinal int32
+}
+
+// BinarySchema is schema of the binary type
+type BinarySchema struct {
+	// ID is ID of the schema
+	ID int32
+	// FieldIDs are IDs of the fields of the schema
+	FieldIDs []int32
+}
+
+// BinaryTypeResolver resolves the binary type information by type ID.
+// Client implements it using the metadata cache.
+type BinaryTypeResolver interface {
+	ResolveBinaryType(typeID int32) (*BinaryType, error)
+}
+
+// binaryTypeReloader is BinaryTypeResolver which can replace stale cached binary type information
+type binaryTypeReloader interface {
+	// reloadBinaryType gets the binary type information from the cluster and updates the cache
+	reloadBinaryType(typeID int32) (*BinaryType, error)
+}
+
+// binaryTypeCache is cache of the binary type information keyed by type ID
+type binaryTypeCache struct {
+	mutex sync.RWMutex
+	types map[int32]*BinaryType
+}
+
+// get returns cached binary type information
+func (c *binaryTypeCache) get(typeID int32) (*BinaryType, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	t, ok := c.types[typeID]
+	return t, ok
+}
+
+// put caches binary type information
+func (c *binaryTypeCache) put(t *BinaryType) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.types == nil {
+		c.types = map[int32]*BinaryType{}
+	}
+	c.types[t.TypeID] = t
+}
+
+// remove removes binary type information from the cache
+func (c *binaryTypeCache) remove(typeID int32) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.types, typeID)
+}
+
+// Binary Types
+// See for details:
+// https://apacheignite.readme.io/docs/binary-client-protocol-binary-type-operations
+
+// GetBinaryTypeName gets the platform-specific full binary type name by ID.
+func (c *client) GetBinaryTypeName(platformID byte, typeID int32) (string, error) {
+	return c.GetBinaryTypeNameContext(context.Background(), platformID, typeID)
+}
+
+// GetBinaryTypeNameContext is equal to GetBinaryTypeName but uses ctx to cancel the operation or limit its duration.
+func (c *client) GetBinaryTypeNameContext(ctx context.Context, platformID byte, typeID int32) (string, error) {
+	// request and response
+	req := c.newRequestOperation(OpGetBinaryTypeName)
+	res := NewResponseOperation(req.UID)
+
+	// set parameters
+	if err := WriteByte(req, platformID); err != nil {
+		return "", errors.Wrapf(err, "failed to write platform id")
+	}
+	if err := WriteInt(req, typeID); err != nil {
+		return "", errors.Wrapf(err, "failed to write type id")
+	}
+
+	// execute operation
+	if err := c.DoContext(ctx, req, res); err != nil {
+		return "", errors.Wrapf(err, "failed to execute OP_GET_BINARY_TYPE_NAME operation")
+	}
+	if err := res.CheckStatus(); err != nil {
+		return "", err
+	}
+
+	return ReadOString(res)
+}
+
+// RegisterBinaryTypeName registers the platform-specific full binary type name for the specified type ID.
+func (c *client) RegisterBinaryTypeName(platformID byte, typeID int32, typeName string) error {
+	return c.RegisterBinaryTypeNameContext(context.Background(), platformID, typeID, typeName)
+}
+
+// RegisterBinaryTypeNameContext is equal to RegisterBinaryTypeName but uses ctx to cancel the operation or limit its duration.
+func (c *client) RegisterBinaryTypeNameContext(ctx context.Context, platformID byte, typeID int32, typeName string) error {
+	// request and response
+	req := c.newRequestOperation(OpRegisterBinaryTypeName)
+	res := NewResponseOperation(req.UID)
+
+	// set parameters
+	if err := WriteByte(req, platformID); err != nil {
+		return errors.Wrapf(err, "failed to write platform id")
+	}
+	if err := WriteInt(req, typeID); err != nil {
+		return errors.Wrapf(err, "failed to write type id")
+	}
+	if err := WriteOString(req, typeName); err != nil {
+		return errors.Wrapf(err, "failed to write type name")
+	}
+
+	// execute operation
+	if err := c.DoContext(ctx, req, res); err != nil {
+		return errors.Wrapf(err, "failed to execute OP_REGISTER_BINARY_TYPE_NAME operation")
+	}
+
+	return res.CheckStatus()
+}
+
+// GetBinaryType gets the binary type information by ID.
+// Returns nil if the type is not registered.
+func (c *client) GetBinaryType(typeID int32) (*BinaryType, error) {
+	return c.GetBinaryTypeContext(context.Background(), typeID)
+}
+
+// GetBinaryTypeContext is equal to GetBinaryType but uses ctx to cancel the operation or limit its duration.
+func (c *client) GetBinaryTypeContext(ctx context.Context, typeID int32) (*BinaryType, error) {
+	// request and response
+	req := c.newRequestOperation(OpGetBinaryType)
+	res := NewResponseOperation(req.UID)
+
+	// set parameters
+	if err := WriteInt(req, typeID); err != nil {
+		return nil, errors.Wrapf(err, "failed to write type id")
+	}
+
+	// execute operation
+	if err := c.DoContext(ctx, req, res); err != nil {
+		return nil, errors.Wrapf(err, "failed to execute OP_GET_BINARY_TYPE operation")
+	}
+	if err := res.CheckStatus(); err != nil {
+		return nil, err
+	}
+
+	exists, err := ReadBool(res)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read type exists flag")
+	}
+	if !exists {
+		return nil, nil
+	}
+	t, err := readBinaryType(res)
+	if err != nil {
+		return nil, err
+	}
+	// objects of the type are written in order of the registered schemas
+	c.putSchemas(t)
+	return &t, nil
+}
+
+// PutBinaryType registers the binary type information in the cluster.
+func (c *client) PutBinaryType(t BinaryType) error {
+	return c.PutBinaryTypeContext(context.Background(), t)
+}
+
+// PutBinaryTypeContext is equal to PutBinaryType but uses ctx to cancel the operation or limit its duration.
+func (c *client) PutBinaryTypeContext(ctx context.Context, t BinaryType) error {
+	// request and response
+	req := c.newRequestOperation(OpPutBinaryType)
+	res := NewResponseOperation(req.UID)
+
+	// set parameters
+	if err := writeBinaryType(req, t); err != nil {
+		return err
+	}
+
+	// execute operation
+	if err := c.DoContext(ctx, req, res); err != nil {
+		return errors.Wrapf(err, "failed to execute OP_PUT_BINARY_TYPE operation")
+	}
+	if err := res.CheckStatus(); err != nil {
+		return err
+	}
+	// the cluster merges the fields with the registered ones, cached information is stale
+	c.binaryTypes().remove(t.TypeID)
+	c.putSchemas(t)
+	return nil
+}
+
+// ResolveBinaryType gets the binary type information by ID from the metadata cache.
+// The cache is filled from the cluster on the first request of the type.
+// Returns error if the type is not registered.
+func (c *client) ResolveBinaryType(typeID int32) (*BinaryType, error) {
+	return c.ResolveBinaryTypeContext(context.Background(), typeID)
+}
+
+// ResolveBinaryTypeContext is equal to ResolveBinaryType but uses ctx to cancel the operation or limit its duration.
+func (c *client) ResolveBinaryTypeContext(ctx context.Context, typeID int32) (*BinaryType, error) {
+	if t, ok := c.binaryTypes().get(typeID); ok {
+		return t, nil
+	}
+	return c.reloadBinaryTypeContext(ctx, typeID)
+}
+
+// reloadBinaryType gets the binary type information from the cluster and updates the cache
+func (c *client) reloadBinaryType(typeID int32) (*BinaryType, error) {
+	return c.reloadBinaryTypeContext(context.Background(), typeID)
+}
+
+// reloadBinaryTypeContext is equal to reloadBinaryType but uses ctx to cancel the operation or limit its duration.
+func (c *client) reloadBinaryTypeContext(ctx context.Context, typeID int32) (*BinaryType, error) {
+	t, err := c.GetBinaryTypeContext(ctx, typeID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.Errorf("binary type %d is not registered", typeID)
+	}
+	c.binaryTypes().put(t)
+	return t, nil
+}
+
+// resolveSchema gets the binary type from the cluster to read the object with compact footer
+// which schema is not known yet. It implements schemaResolver for the responses of the client.
+func (c *client) resolveSchema(typeID int32, schemaID int32) (*schema, error) {
+	t, err := c.reloadBinaryType(typeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range t.Schemas {
+		if s.ID == schemaID {
+			return &schema{id: s.ID, fields: s.FieldIDs}, nil
+		}
+	}
+	return nil, nil
+}
+
+// binaryTypes returns the metadata cache, clients of the transactions share the cache of the owner
+func (c *client) binaryTypes() *binaryTypeCache {
+	if c.owner != nil {
+		return c.owner.binaryTypes()
+	}
+	return &c.types
+}
+
+// registeredSchemas returns set of the schemas registered in the cluster,
+// nil is returned if compact footers are disabled
+func (c *client) registeredSchemas() *registeredSchemas {
+	if c.owner != nil {
+		return c.owner.registeredSchemas()
+	}
+	if !c.compactFooter {
+		return nil
+	}
+	return &c.schemas
+}
+
+// putSchemas caches schemas of the binary type registered in the cluster
+func (c *client) putSchemas(t BinaryType) {
+	rs := c.registeredSchemas()
+	for _, s := range t.Schemas {
+		schemas.put(t.TypeID, s.FieldIDs, true, true)
+		if rs != nil {
+			rs.add(t.TypeID, s.FieldIDs)
+		}
+	}
+}
+
+// newRequestOperation creates operation request, objects are written to it with compact footers
+// for the schemas registered in the cluster if compact footers are enabled
+func (c *client) newRequestOperation(code int16) *RequestOperation {
+	req := NewRequestOperation(code)
+	req.schemas = c.registeredSchemas()
+	return req
+}
+
+// writeOStringOrNull writes "string" object value or NULL if the string is empty
+func writeOStringOrNull(w io.Writer, v string) error {
+	if v == "" {
+		return WriteNull(w)
+	}
+	return WriteOString(w, v)
+}
+
+// writeBinaryType writes binary type metadata
+func writeBinaryType(w io.Writer, t BinaryType) error {
+	if err := WriteInt(w, t.TypeID); err != nil {
+		return errors.Wrapf(err, "failed to write type id")
+	}
+	if err := WriteOString(w, t.TypeName); err != nil {
+		return errors.Wrapf(err, "failed to write type name")
+	}
+	if err := writeOStringOrNull(w, t.AffinityKeyFieldName); err != nil {
+		return errors.Wrapf(err, "failed to write affinity key field name")
+	}
+
+	// fields
+	if err := WriteInt(w, int32(len(t.Fields))); err != nil {
+		return errors.Wrapf(err, "failed to write field count")
+	}
+	for i, f := range t.Fields {
+		if err := WriteOString(w, f.Name); err != nil {
+			return errors.Wrapf(err, "failed to write name of field with index %d", i)
+		}
+		if err := WriteInt(w, f.TypeCode); err != nil {
+			return errors.Wrapf(err, "failed to write type code of field with index %d", i)
+		}
+		if err := WriteInt(w, f.ID); err != nil {
+			return errors.Wrapf(err, "failed to write id of field with index %d", i)
+		}
+	}
+
+	// enum values
+	if err := WriteBool(w, t.IsEnum); err != nil {
+		return errors.Wrapf(err, "failed to write enum flag")
+	}
+	if t.IsEnum {
+		if err := WriteInt(w, int32(len(t.EnumValues))); err != nil {
+			return errors.Wrapf(err, "failed to write enum value count")
+		}
+		for i, v := range t.EnumValues {
+			if err := WriteOString(w, v.Name); err != nil {
+				return errors.Wrapf(err, "failed to write name of enum value with index %d", i)
+			}
+			if err := WriteInt(w, v.Ordinal); err != nil {
+				return errors.Wrapf(err, "failed to write ordinal of enum value with index %d", i)
+			}
+		}
+	}
+
+	// schemas
+	if err := WriteInt(w, int32(len(t.Schemas))); err != nil {
+		return errors.Wrapf(err, "failed to write schema count")
+	}
+	for i, s := range t.Schemas {
+		if err := WriteInt(w, s.ID); err != nil {
+			return errors.Wrapf(err, "failed to write id of schema with index %d", i)
+		}
+		if err := WriteInt(w, int32(len(s.FieldIDs))); err != nil {
+			return errors.Wrapf(err, "failed to write field count of schema with index %d", i)
+		}
+		for _, id := range s.FieldIDs {
+			if err := WriteInt(w, id); err != nil {
+				return errors.Wrapf(err, "failed to write field id of schema with index %d", i)
+			}
+		}
+	}
+	return nil
+}
+
+// readBinaryType reads binary type metadata
+func readBinaryType(r io.Reader) (BinaryType, error) {
+	var t BinaryType
+	var err error
+
+	if t.TypeID, err = ReadInt(r); err != nil {
+		return t, errors.Wrapf(err, "failed to read type id")
+	}
+	if t.TypeName, err = ReadOString(r); err != nil {
+		return t, errors.Wrapf(err, "failed to read type name")
+	}
+	if t.AffinityKeyFieldName, err = ReadOString(r); err != nil {
+		return t, errors.Wrapf(err, "failed to read affinity key field name")
+	}
+
+	// fields, every field is 9 bytes at least
+	count, err := readLength(r, 9)
+	if err != nil {
+		return t, errors.Wrapf(err, "failed to read field count")
+	}
+	if count > 0 {
+		t.Fields = make([]BinaryField, 0, capacity(r, count))
+		for i := 0; i < count; i++ {
+			var f BinaryField
+			if f.Name, err = ReadOString(r); err != nil {
+				return t, errors.Wrapf(err, "failed to read name of field with index %d", i)
+			}
+			if f.TypeCode, err = ReadInt(r); err != nil {
+				return t, errors.Wrapf(err, "failed to read type code of field with index %d", i)
+			}
+			if f.ID, err = ReadInt(r); err != nil {
+				return t, errors.Wrapf(err, "failed to read id of field with index %d", i)
+			}
+			t.Fields = append(t.Fields, f)
+		}
+	}
+
+	// enum values, every value is 5 bytes at least
+	if t.IsEnum, err = ReadBool(r); err != nil {
+		return t, errors.Wrapf(err, "failed to read enum flag")
+	}
+	if t.IsEnum {
+		if count, err = readLength(r, 5); err != nil {
+			return t, errors.Wrapf(err, "failed to read enum value count")
+		}
+		if count > 0 {
+			t.EnumValues = make([]BinaryEnumValue, 0, capacity(r, count))
+			for i := 0; i < count; i++ {
+				var v BinaryEnumValue
+				if v.Name, err = ReadOString(r); err != nil {
+					return t, errors.Wrapf(err, "failed to read name of enum value with index %d", i)
+				}
+				if v.Ordinal, err = ReadInt(r); err != nil {
+					return t, errors.Wrapf(err, "failed to read ordinal of enum value with index %d", i)
+				}
+				t.EnumValues = append(t.EnumValues, v)
+			}
+		}
+	}
+
+	// schemas, every schema is 8 bytes at least
+	if count, err = readLength(r, 8); err != nil {
+		return t, errors.Wrapf(err, "failed to read schema count")
+	}
+	if count > 0 {
+		t.Schemas = make([]BinarySchema, 0, capacity(r, count))
+		for i := 0; i < count; i++ {
+			var s BinarySchema
+			if s.ID, err = ReadInt(r); err != nil {
+				return t, errors.Wrapf(err, "failed to read id of schema with index %d", i)
+			}
+			n, err := readLength(r, 4)
+			if err != nil {
+				return t, errors.Wrapf(err, "failed to read field count of schema with index %d", i)
+			}
+			if n > 0 {
+				s.FieldIDs = make([]int32, 0, capacity(r, n))
+				for j := 0; j < n; j++ {
+					id, err := ReadInt(r)
+					if err != nil {
+						return t, errors.Wrapf(err, "failed to read field id of schema with index %d", i)
+					}
+					s.FieldIDs = append(s.FieldIDs, id)
+				}
+			}
+			t.Schemas = append(t.Schemas, s)
+		}
+	}
+	return t, nil
+}
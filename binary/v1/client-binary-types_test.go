@@ -0,0 +1,464 @@
+package ignite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+func Test_writeBinaryType(t *testing.T) {
+	tests := []struct {
+		name string
+		t    BinaryType
+	}{
+		{
+			name: "class",
+			t: BinaryType{
+				TypeID:               HashCode("Person"),
+				TypeName:             "Person",
+				AffinityKeyFieldName: "orgId",
+				Fields: []BinaryField{
+					{Name: "name", TypeCode: typeString, ID: HashCode("name")},
+					{Name: "orgId", TypeCode: typeLong, ID: HashCode("orgId")},
+				},
+				Schemas: []BinarySchema{
+					{ID: 123, FieldIDs: []int32{HashCode("name"), HashCode("orgId")}},
+				},
+			},
+		},
+		{
+			name: "enum",
+			t: BinaryType{
+				TypeID:   HashCode("Color"),
+				TypeName: "Color",
+				IsEnum:   true,
+				EnumValues: []BinaryEnumValue{
+					{Name: "RED", Ordinal: 0},
+					{Name: "GREEN", Ordinal: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := writeBinaryType(w, tt.t); err != nil {
+				t.Fatalf("writeBinaryType() error = %v", err)
+			}
+			got, err := readBinaryType(w)
+			if err != nil {
+				t.Fatalf("readBinaryType() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.t) {
+				t.Errorf("readBinaryType() = %#v, want %#v", got, tt.t)
+			}
+			if w.Len() != 0 {
+				t.Errorf("readBinaryType() left %d bytes unread", w.Len())
+			}
+		})
+	}
+}
+
+func Test_readBinaryType_InvalidLength(t *testing.T) {
+	// header is type ID, null type name and null affinity key field name
+	header := []byte{1, 0, 0, 0, typeNULL, typeNULL}
+	oversized := []byte{0xFF, 0xFF, 0xFF, 0x0F}
+	negative := []byte{0xFE, 0xFF, 0xFF, 0xFF}
+	message := func(parts ...[]byte) []byte {
+		return bytes.Join(append([][]byte{header}, parts...), nil)
+	}
+
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{
+			name: "oversized field count",
+			b:    message(oversized),
+		},
+		{
+			name: "negative field count",
+			b:    message(negative),
+		},
+		{
+			name: "oversized enum value count",
+			b:    message([]byte{0, 0, 0, 0, 1}, oversized),
+		},
+		{
+			name: "oversized schema count",
+			b:    message([]byte{0, 0, 0, 0, 0}, oversized),
+		},
+		{
+			name: "oversized schema field count",
+			b:    message([]byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 7, 0, 0, 0}, oversized),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readers := map[string]io.Reader{
+				"decoder": &decoder{b: tt.b},
+				// length of the message is unknown
+				"unsized": struct{ io.Reader }{bytes.NewReader(tt.b)},
+			}
+			for name, r := range readers {
+				if got, err := readBinaryType(r); err == nil {
+					t.Errorf("readBinaryType() from %s = %v, want error", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_BinaryTypes(t *testing.T) {
+	var mutex sync.Mutex
+	names := map[int32]string{}
+	types := map[int32][]byte{}
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		id := int32(binary.LittleEndian.Uint32(data))
+		switch code {
+		case OpGetBinaryTypeName:
+			id = int32(binary.LittleEndian.Uint32(data[1:]))
+			b := &bytes.Buffer{}
+			_ = writeOStringOrNull(b, names[id])
+			return b.Bytes(), nil
+		case OpRegisterBinaryTypeName:
+			id = int32(binary.LittleEndian.Uint32(data[1:]))
+			name, err := ReadOString(bytes.NewReader(data[5:]))
+			names[id] = name
+			return nil, err
+		case OpGetBinaryType:
+			if t, ok := types[id]; ok {
+				return append([]byte{1}, t...), nil
+			}
+			return []byte{0}, nil
+		case OpPutBinaryType:
+			types[id] = append([]byte(nil), data...)
+			return nil, nil
+		}
+		return nil, nil
+	})
+	defer s.close()
+	c, err := Connect(s.connInfo())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	id := HashCode("Person")
+	if err = c.RegisterBinaryTypeName(PlatformJava, id, "org.example.Person"); err != nil {
+		t.Fatalf("client.RegisterBinaryTypeName() error = %v", err)
+	}
+	name, err := c.GetBinaryTypeName(PlatformJava, id)
+	if err != nil {
+		t.Fatalf("client.GetBinaryTypeName() error = %v", err)
+	}
+	if name != "org.example.Person" {
+		t.Errorf("client.GetBinaryTypeName() = %v, want %v", name, "org.example.Person")
+	}
+
+	got, err := c.GetBinaryType(id)
+	if err != nil {
+		t.Fatalf("client.GetBinaryType() error = %v", err)
+	}
+	if got != nil {
+		t.Errorf("client.GetBinaryType() = %v for not registered type, want nil", got)
+	}
+	want := BinaryType{
+		TypeID:   id,
+		TypeName: "org.example.Person",
+		Fields:   []BinaryField{{Name: "name", TypeCode: typeString, ID: HashCode("name")}},
+		Schemas:  []BinarySchema{{ID: 1, FieldIDs: []int32{HashCode("name")}}},
+	}
+	if err = c.PutBinaryType(want); err != nil {
+		t.Fatalf("client.PutBinaryType() error = %v", err)
+	}
+	if got, err = c.GetBinaryType(id); err != nil {
+		t.Fatalf("client.GetBinaryType() error = %v", err)
+	}
+	if got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("client.GetBinaryType() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_ResolveBinaryType(t *testing.T) {
+	person := BinaryType{
+		TypeID:   HashCode("Person"),
+		TypeName: "Person",
+		Fields:   []BinaryField{{Name: "name", TypeCode: typeString, ID: HashCode("name")}},
+	}
+	var mutex sync.Mutex
+	gets := 0
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		if code != OpGetBinaryType {
+			return nil, nil
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		gets++
+		if int32(binary.LittleEndian.Uint32(data)) != person.TypeID {
+			return []byte{0}, nil
+		}
+		b := bytes.NewBuffer([]byte{1})
+		_ = writeBinaryType(b, person)
+		return b.Bytes(), nil
+	})
+	defer s.close()
+	c, err := Connect(s.connInfo())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+	requests := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return gets
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := c.ResolveBinaryType(person.TypeID)
+		if err != nil {
+			t.Fatalf("client.ResolveBinaryType() error = %v", err)
+		}
+		if !reflect.DeepEqual(*got, person) {
+			t.Errorf("client.ResolveBinaryType() = %v, want %v", *got, person)
+		}
+	}
+	if got := requests(); got != 1 {
+		t.Errorf("server received %d OP_GET_BINARY_TYPE requests, want 1", got)
+	}
+	if _, err = c.ResolveBinaryType(HashCode("Unknown")); err == nil {
+		t.Errorf("client.ResolveBinaryType() error = nil for not registered type")
+	}
+
+	// cached type is reloaded if the object has field added after the type is cached
+	mutex.Lock()
+	person.Fields = append(person.Fields, BinaryField{Name: "age", TypeCode: typeInt, ID: HashCode("age")})
+	mutex.Unlock()
+	o := NewComplexObject("Person")
+	o.Set("name", "Ivan")
+	o.Set("age", int32(30))
+	m, err := o.ToMap(c)
+	if err != nil {
+		t.Fatalf("ComplexObject.ToMap() error = %v", err)
+	}
+	if want := map[string]interface{}{"name": "Ivan", "age": int32(30)}; !reflect.DeepEqual(m, want) {
+		t.Errorf("ComplexObject.ToMap() = %v, want %v", m, want)
+	}
+	if got := requests(); got != 3 {
+		t.Errorf("server received %d OP_GET_BINARY_TYPE requests, want 3", got)
+	}
+}
+
+func TestClient_CompactFooter(t *testing.T) {
+	typeID := HashCode("TestClient_CompactFooter")
+	ids := []int32{HashCode("id")}
+	bt := BinaryType{
+		TypeID:   typeID,
+		TypeName: "TestClient_CompactFooter",
+		Fields:   []BinaryField{{Name: "id", TypeCode: typeInt, ID: ids[0]}},
+		Schemas:  []BinarySchema{{ID: schemaID(ids), FieldIDs: ids}},
+	}
+	tests := []struct {
+		name    string
+		compact bool
+		// other is true if the schema is registered by the client connected to another cluster
+		other bool
+		want  bool
+	}{
+		{
+			name: "compact footers are disabled",
+		},
+		{
+			name:    "schema is registered in another cluster",
+			compact: true,
+			other:   true,
+		},
+		{
+			name:    "compact footer",
+			compact: true,
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			var put []byte
+			handler := func(conn int, code int16, data []byte) ([]byte, error) {
+				if code == OpCachePut {
+					mutex.Lock()
+					put = append([]byte(nil), data...)
+					mutex.Unlock()
+				}
+				return nil, nil
+			}
+			s := newTestServer(t, handler)
+			defer s.close()
+			ci := s.connInfo()
+			ci.CompactFooter = tt.compact
+			c, err := Connect(ci)
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer c.Close()
+
+			registrar := c
+			if tt.other {
+				s2 := newTestServer(t, handler)
+				defer s2.close()
+				ci2 := s2.connInfo()
+				ci2.CompactFooter = true
+				if registrar, err = Connect(ci2); err != nil {
+					t.Fatalf("Connect() error = %v", err)
+				}
+				defer registrar.Close()
+			}
+			if err = registrar.PutBinaryType(bt); err != nil {
+				t.Fatalf("client.PutBinaryType() error = %v", err)
+			}
+
+			o := ComplexObject{Type: typeID, Fields: map[int32]interface{}{ids[0]: int32(1)}}
+			if err = c.CachePut("cache", false, int32(1), o); err != nil {
+				t.Fatalf("client.CachePut() error = %v", err)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			// cache ID, flags and key are followed by the object
+			flags := int16(binary.LittleEndian.Uint16(put[4+1+5+2:]))
+			if got := flags&ComplexObjectCompactFooter != 0; got != tt.want {
+				t.Errorf("compact footer = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_CompactFooterUnknownSchema(t *testing.T) {
+	ids := []int32{HashCode("id"), HashCode("name")}
+	// compactObject writes object with compact footer the way Java node does, its schema is not known to the client
+	compactObject := func(typeID int32) []byte {
+		w := NewRequestOperation(OpCachePut)
+		w.schemas = &registeredSchemas{}
+		w.schemas.add(typeID, ids)
+		c := NewComplexObjectWriter(typeID)
+		_ = WriteOInt(c.Field(ids[0]), 1)
+		_ = WriteOString(c.Field(ids[1]), "Ivan")
+		_ = c.WriteObject(w)
+		return w.payload.Bytes()
+	}
+	tests := []struct {
+		name string
+		// read reads the object received from the cluster
+		read func(c Client) (interface{}, error)
+		// response is response data of the operation with object o
+		response func(o []byte) []byte
+	}{
+		{
+			name: "CacheGet",
+			read: func(c Client) (interface{}, error) {
+				return c.CacheGet("cache", false, int32(1))
+			},
+			response: func(o []byte) []byte {
+				return o
+			},
+		},
+		{
+			name: "BinaryObject",
+			read: func(c Client) (interface{}, error) {
+				v, err := c.CacheGet("cache", true, int32(1))
+				if err != nil {
+					return nil, err
+				}
+				b, ok := v.(BinaryObject)
+				if !ok {
+					return nil, errors.Errorf("CacheGet() = %T, want BinaryObject", v)
+				}
+				if name, ok, err := b.Get("name"); err != nil || !ok || name != "Ivan" {
+					return nil, errors.Errorf("BinaryObject.Get() = %v, %v, %v, want Ivan", name, ok, err)
+				}
+				return b.Deserialize()
+			},
+			response: func(o []byte) []byte {
+				w := &bytes.Buffer{}
+				_ = WriteOBinaryObject(w, BinaryObject{data: o})
+				return w.Bytes()
+			},
+		},
+		{
+			name: "QueryScanEach",
+			read: func(c Client) (interface{}, error) {
+				var values []interface{}
+				r, err := c.QueryScanEach("cache", false, QueryScanData{}, func(key, value interface{}) error {
+					values = append(values, value)
+					return nil
+				})
+				if err != nil {
+					return nil, err
+				}
+				if len(values) != 3 || values[0] != "first" || !reflect.DeepEqual(values[1], values[2]) || !r.HasMore {
+					return nil, errors.Errorf("QueryScanEach() = %v, %v, want 3 rows and more pages", values, r.HasMore)
+				}
+				return values[1], nil
+			},
+			response: func(o []byte) []byte {
+				// the object follows the row which is read while the response is streamed
+				w := &bytes.Buffer{}
+				_ = WriteLong(w, 1)
+				_ = WriteInt(w, 3)
+				_ = WriteObject(w, int32(1))
+				_ = WriteObject(w, "first")
+				for _, key := range []int32{2, 3} {
+					_ = WriteObject(w, key)
+					_, _ = w.Write(o)
+				}
+				_ = WriteBool(w, true)
+				return w.Bytes()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeID := HashCode("TestClient_CompactFooterUnknownSchema" + tt.name)
+			bt := &bytes.Buffer{}
+			_ = writeBinaryType(bt, BinaryType{
+				TypeID:   typeID,
+				TypeName: "TestClient_CompactFooterUnknownSchema",
+				Fields: []BinaryField{
+					{Name: "id", TypeCode: typeInt, ID: ids[0]},
+					{Name: "name", TypeCode: typeString, ID: ids[1]},
+				},
+				Schemas: []BinarySchema{{ID: schemaID(ids), FieldIDs: ids}},
+			})
+			o := compactObject(typeID)
+			s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+				switch code {
+				case OpGetBinaryType:
+					return append([]byte{1}, bt.Bytes()...), nil
+				case OpCacheGet, OpQueryScan:
+					return tt.response(o), nil
+				}
+				return nil, nil
+			})
+			defer s.close()
+			c, err := Connect(s.connInfo())
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer c.Close()
+
+			got, err := tt.read(c)
+			if err != nil {
+				t.Fatalf("read error = %v", err)
+			}
+			want := ComplexObject{Type: typeID, Fields: map[int32]interface{}{ids[0]: int32(1), ids[1]: "Ivan"}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("read = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
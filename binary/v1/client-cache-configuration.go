@@ -1,6 +1,9 @@
 package ignite
 
 import (
+	"context"
+	"io"
+
 	"github.com/amsokol/ignite-go-client/binary/errors"
 )
 
@@ -41,6 +44,9 @@ const (
 	cacheConfigurationQueryEntitiesCode                 = 200
 )
 
+// queryFieldPrecisionVersion is the first protocol version with QueryField default value, precision and scale
+var queryFieldPrecisionVersion = ProtocolVersion{Major: 1, Minor: 2, Patch: 0}
+
 const (
 	// CacheAtomicityModeTransactional is TRANSACTIONAL = 0
 	CacheAtomicityModeTransactional = 0
@@ -119,6 +125,16 @@ type CacheConfiguration struct {
 	WriteSynchronizationMode      int32
 	CacheKeyConfigurations        []CacheKeyConfiguration
 	QueryEntities                 []QueryEntity
+	// ExpiryPolicy is nil if it is not set (protocol version 1.6.0 and above)
+	ExpiryPolicy *ExpiryPolicy
+}
+
+// ExpiryPolicy contains durations in milliseconds after which cache entry expires:
+// -1 means the entry never expires, -2 means the duration is not changed
+type ExpiryPolicy struct {
+	Create int64
+	Update int64
+	Access int64
 }
 
 // CacheKeyConfiguration is struct
@@ -145,6 +161,11 @@ type QueryField struct {
 	TypeName                 string
 	IsKeyField               bool
 	IsNotNullConstraintField bool
+	// DefaultValue, Precision and Scale are supported by protocol version 1.2.0 and above.
+	// Precision and Scale are not set if nil.
+	DefaultValue interface{}
+	Precision    *int32
+	Scale        *int32
 }
 
 // QueryIndex is struct
@@ -205,8 +226,13 @@ type CacheConfigurationRefs struct {
 // Cache template can be applied if there is a '*' in the cache name.
 // https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_create_with_name
 func (c *client) CacheCreateWithName(cache string) error {
+	return c.CacheCreateWithNameContext(context.Background(), cache)
+}
+
+// CacheCreateWithNameContext is equal to CacheCreateWithName but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheCreateWithNameContext(ctx context.Context, cache string) error {
 	// request and response
-	req := NewRequestOperation(OpCacheCreateWithName)
+	req := c.newRequestOperation(OpCacheCreateWithName)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
@@ -215,7 +241,7 @@ func (c *client) CacheCreateWithName(cache string) error {
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_CREATE_WITH_NAME operation")
 	}
 
@@ -226,8 +252,13 @@ func (c *client) CacheCreateWithName(cache string) error {
 // Cache template can be applied if there is a '*' in the cache name.
 // Does nothing if the cache exists.
 func (c *client) CacheGetOrCreateWithName(cache string) error {
+	return c.CacheGetOrCreateWithNameContext(context.Background(), cache)
+}
+
+// CacheGetOrCreateWithNameContext is equal to CacheGetOrCreateWithName but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetOrCreateWithNameContext(ctx context.Context, cache string) error {
 	// request and response
-	req := NewRequestOperation(OpCacheGetOrCreateWithName)
+	req := c.newRequestOperation(OpCacheGetOrCreateWithName)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
@@ -236,7 +267,7 @@ func (c *client) CacheGetOrCreateWithName(cache string) error {
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_GET_OR_CREATE_WITH_NAME operation")
 	}
 
@@ -245,12 +276,17 @@ func (c *client) CacheGetOrCreateWithName(cache string) error {
 
 // CacheGetNames returns existing cache names.
 func (c *client) CacheGetNames() ([]string, error) {
+	return c.CacheGetNamesContext(context.Background())
+}
+
+// CacheGetNamesContext is equal to CacheGetNames but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetNamesContext(ctx context.Context) ([]string, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGetNames)
+	req := c.newRequestOperation(OpCacheGetNames)
 	res := NewResponseOperation(req.UID)
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET_NAMES operation")
 	}
 
@@ -279,8 +315,15 @@ func (c *client) CacheGetNames() ([]string, error) {
 
 // CacheGetConfiguration gets configuration for the given cache.
 func (c *client) CacheGetConfiguration(cache string, flag byte) (*CacheConfiguration, error) {
+	return c.CacheGetConfigurationContext(context.Background(), cache, flag)
+}
+
+// CacheGetConfigurationContext is equal to CacheGetConfiguration but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetConfigurationContext(ctx context.Context, cache string, flag byte) (*CacheConfiguration, error) {
+	version := c.ProtocolVersion()
+
 	// request and response
-	req := NewRequestOperation(OpCacheGetConfiguration)
+	req := c.newRequestOperation(OpCacheGetConfiguration)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
@@ -292,7 +335,7 @@ func (c *client) CacheGetConfiguration(cache string, flag byte) (*CacheConfigura
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET_CONFIGURATION operation")
 	}
 
@@ -450,6 +493,18 @@ func (c *client) CacheGetConfiguration(cache string, flag byte) (*CacheConfigura
 			if qf.IsNotNullConstraintField, err = ReadBool(res); err != nil {
 				return nil, errors.Wrapf(err, "failed to read QueryField.IsNotNullConstraintField")
 			}
+			if !version.Less(queryFieldPrecisionVersion) {
+				if qf.DefaultValue, err = ReadObject(res); err != nil {
+					return nil, errors.Wrapf(err, "failed to read QueryField.DefaultValue")
+				}
+				if qf.Precision, err = readQueryFieldScale(res); err != nil {
+					return nil, errors.Wrapf(err, "failed to read QueryField.Precision")
+				}
+				if qf.Scale, err = readQueryFieldScale(res); err != nil {
+					return nil, errors.Wrapf(err, "failed to read QueryField.Scale")
+				}
+			}
+			qe.QueryFields = append(qe.QueryFields, qf)
 		}
 
 		// read FieldNameAliases
@@ -506,22 +561,54 @@ func (c *client) CacheGetConfiguration(cache string, flag byte) (*CacheConfigura
 		cc.QueryEntities = append(cc.QueryEntities, qe)
 	}
 
+	if !version.Less(expiryPolicyVersion) {
+		var ok bool
+		if ok, err = ReadBool(res); err != nil {
+			return nil, errors.Wrapf(err, "failed to read ExpiryPolicy flag")
+		}
+		if ok {
+			var ep ExpiryPolicy
+			if ep.Create, err = ReadLong(res); err != nil {
+				return nil, errors.Wrapf(err, "failed to read ExpiryPolicy.Create")
+			}
+			if ep.Update, err = ReadLong(res); err != nil {
+				return nil, errors.Wrapf(err, "failed to read ExpiryPolicy.Update")
+			}
+			if ep.Access, err = ReadLong(res); err != nil {
+				return nil, errors.Wrapf(err, "failed to read ExpiryPolicy.Access")
+			}
+			cc.ExpiryPolicy = &ep
+		}
+	}
+
 	return &cc, nil
 }
 
 // CacheCreateWithConfiguration creates cache with provided configuration.
 // An error is returned if the name is already in use.
 func (c *client) CacheCreateWithConfiguration(cc *CacheConfigurationRefs) error {
-	return c.cacheCreateWithConfiguration(OpCacheCreateWithConfiguration, cc)
+	return c.CacheCreateWithConfigurationContext(context.Background(), cc)
+}
+
+// CacheCreateWithConfigurationContext is equal to CacheCreateWithConfiguration but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheCreateWithConfigurationContext(ctx context.Context, cc *CacheConfigurationRefs) error {
+	return c.cacheCreateWithConfiguration(ctx, OpCacheCreateWithConfiguration, cc)
 }
 
 // CacheGetOrCreateWithConfiguration creates cache with provided configuration.
 // Does nothing if the name is already in use.
 func (c *client) CacheGetOrCreateWithConfiguration(cc *CacheConfigurationRefs) error {
-	return c.cacheCreateWithConfiguration(OpCacheGetOrCreateWithConfiguration, cc)
+	return c.CacheGetOrCreateWithConfigurationContext(context.Background(), cc)
+}
+
+// CacheGetOrCreateWithConfigurationContext is equal to CacheGetOrCreateWithConfiguration but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetOrCreateWithConfigurationContext(ctx context.Context, cc *CacheConfigurationRefs) error {
+	return c.cacheCreateWithConfiguration(ctx, OpCacheGetOrCreateWithConfiguration, cc)
 }
 
-func (c *client) cacheCreateWithConfiguration(code int16, cc *CacheConfigurationRefs) error {
+func (c *client) cacheCreateWithConfiguration(ctx context.Context, code int16, cc *CacheConfigurationRefs) error {
+	version := c.ProtocolVersion()
+
 	// request and response
 	req := NewRequestCacheCreateWithConfiguration(code)
 	res := NewResponseOperation(req.UID)
@@ -849,6 +936,17 @@ func (c *client) cacheCreateWithConfiguration(code int16, cc *CacheConfiguration
 					if err := WriteBool(req, v2.IsNotNullConstraintField); err != nil {
 						return errors.Wrapf(err, "failed to write QueryField.IsNotNullConstraintField with index %d", j)
 					}
+					if !version.Less(queryFieldPrecisionVersion) {
+						if err := WriteObject(req, v2.DefaultValue); err != nil {
+							return errors.Wrapf(err, "failed to write QueryField.DefaultValue with index %d", j)
+						}
+						if err := writeQueryFieldScale(req, v2.Precision); err != nil {
+							return errors.Wrapf(err, "failed to write QueryField.Precision with index %d", j)
+						}
+						if err := writeQueryFieldScale(req, v2.Scale); err != nil {
+							return errors.Wrapf(err, "failed to write QueryField.Scale with index %d", j)
+						}
+					}
 				}
 			}
 			// write FieldNameAliases
@@ -913,17 +1011,39 @@ func (c *client) cacheCreateWithConfiguration(code int16, cc *CacheConfiguration
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute operation to create cache with configuration")
 	}
 
 	return res.CheckStatus()
 }
 
+// writeQueryFieldScale writes QueryField precision or scale, -1 means the value is not set
+func writeQueryFieldScale(w io.Writer, v *int32) error {
+	if v == nil {
+		return WriteInt(w, -1)
+	}
+	return WriteInt(w, *v)
+}
+
+// readQueryFieldScale reads QueryField precision or scale, returns nil if the value is not set
+func readQueryFieldScale(r io.Reader) (*int32, error) {
+	v, err := ReadInt(r)
+	if err != nil || v == -1 {
+		return nil, err
+	}
+	return &v, nil
+}
+
 // CacheDestroy destroys cache with a given name.
 func (c *client) CacheDestroy(cache string) error {
+	return c.CacheDestroyContext(context.Background(), cache)
+}
+
+// CacheDestroyContext is equal to CacheDestroy but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheDestroyContext(ctx context.Context, cache string) error {
 	// request and response
-	req := NewRequestOperation(OpCacheDestroy)
+	req := c.newRequestOperation(OpCacheDestroy)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
@@ -932,7 +1052,7 @@ func (c *client) CacheDestroy(cache string) error {
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_DESTROY operation")
 	}
 
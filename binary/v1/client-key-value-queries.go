@@ -1,6 +1,8 @@
 package ignite
 
 import (
+	"context"
+	"io"
 	"time"
 
 	"github.com/amsokol/ignite-go-client/binary/errors"
@@ -23,15 +25,20 @@ const (
 
 // CacheGet retrieves a value from cache by key.
 func (c *client) CacheGet(cache string, binary bool, key interface{}) (interface{}, error) {
+	return c.CacheGetContext(context.Background(), cache, binary, key)
+}
+
+// CacheGetContext is equal to CacheGet but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetContext(ctx context.Context, cache string, binary bool, key interface{}) (interface{}, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGet)
+	req := c.newRequestOperation(OpCacheGet)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return nil, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -39,40 +46,37 @@ func (c *client) CacheGet(cache string, binary bool, key interface{}) (interface
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET operation")
 	}
 	if err := res.CheckStatus(); err != nil {
 		return nil, err
 	}
 
-	return ReadObject((res))
+	return readCacheObject(res, binary)
 }
 
 func (c *client) CacheGetAndExtendingTTL(cache string, key interface{}, ttl time.Duration) (interface{}, error) {
+	return c.CacheGetAndExtendingTTLContext(context.Background(), cache, key, ttl)
+}
+
+// CacheGetAndExtendingTTLContext is equal to CacheGetAndExtendingTTL but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetAndExtendingTTLContext(ctx context.Context, cache string, key interface{}, ttl time.Duration) (interface{}, error) {
 	if ttl.Milliseconds() == 0 {
 		return nil, errors.NewError(1, "TTL should be more than a millisecond")
 	}
 
 	// request and response
-	req := NewRequestOperation(OpCacheGet)
+	req := c.newRequestOperation(OpCacheGet)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteByte(req, WithExpiryPolicyFlagMask); err != nil {
-		return nil, errors.Wrapf(err, "failed to write binary flag")
-	}
-	if err := WriteLong(req, DurUnchanged); err != nil {
-		return nil, errors.Wrapf(err, "failed to write ttl")
-	}
-	if err := WriteLong(req, DurUnchanged); err != nil {
-		return nil, errors.Wrapf(err, "failed to write ttl")
-	}
-	if err := WriteLong(req, ttl.Milliseconds()); err != nil {
-		return nil, errors.Wrapf(err, "failed to write ttl")
+	expiry := &ExpiryPolicy{Create: DurUnchanged, Update: DurUnchanged, Access: ttl.Milliseconds()}
+	if err := c.writeCacheFlags(req, false, expiry); err != nil {
+		return nil, errors.Wrapf(err, "failed to write expiry policy")
 	}
 
 	if err := WriteObject(req, key); err != nil {
@@ -80,7 +84,7 @@ func (c *client) CacheGetAndExtendingTTL(cache string, key interface{}, ttl time
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -92,15 +96,20 @@ func (c *client) CacheGetAndExtendingTTL(cache string, key interface{}, ttl time
 
 // CacheGetAll retrieves multiple key-value pairs from cache.
 func (c *client) CacheGetAll(cache string, binary bool, keys []interface{}) (map[interface{}]interface{}, error) {
+	return c.CacheGetAllContext(context.Background(), cache, binary, keys)
+}
+
+// CacheGetAllContext is equal to CacheGetAll but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetAllContext(ctx context.Context, cache string, binary bool, keys []interface{}) (map[interface{}]interface{}, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGetAll)
+	req := c.newRequestOperation(OpCacheGetAll)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return nil, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteInt(req, int32(len(keys))); err != nil {
@@ -113,7 +122,7 @@ func (c *client) CacheGetAll(cache string, binary bool, keys []interface{}) (map
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET_ALL operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -131,7 +140,7 @@ func (c *client) CacheGetAll(cache string, binary bool, keys []interface{}) (map
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read key with index %d", i)
 		}
-		value, err := ReadObject(res)
+		value, err := readCacheObject(res, binary)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read value with index %d", i)
 		}
@@ -143,15 +152,20 @@ func (c *client) CacheGetAll(cache string, binary bool, keys []interface{}) (map
 
 // CachePut puts a value with a given key to cache (overwriting existing value if any).
 func (c *client) CachePut(cache string, binary bool, key interface{}, value interface{}) error {
+	return c.CachePutContext(context.Background(), cache, binary, key, value)
+}
+
+// CachePutContext is equal to CachePut but uses ctx to cancel the operation or limit its duration.
+func (c *client) CachePutContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) error {
 	// request and response
-	req := NewRequestOperation(OpCachePut)
+	req := c.newRequestOperation(OpCachePut)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -162,7 +176,7 @@ func (c *client) CachePut(cache string, binary bool, key interface{}, value inte
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_PUT operation")
 	}
 
@@ -170,30 +184,26 @@ func (c *client) CachePut(cache string, binary bool, key interface{}, value inte
 }
 
 func (c *client) CachePutWithTTL(cache string, key interface{}, value interface{}, ttl time.Duration) error {
+	return c.CachePutWithTTLContext(context.Background(), cache, key, value, ttl)
+}
+
+// CachePutWithTTLContext is equal to CachePutWithTTL but uses ctx to cancel the operation or limit its duration.
+func (c *client) CachePutWithTTLContext(ctx context.Context, cache string, key interface{}, value interface{}, ttl time.Duration) error {
 	if ttl.Milliseconds() == 0 {
 		return errors.NewError(1, "TTL should be more than a millisecond")
 	}
 
 	// request and response
-	req := NewRequestOperation(OpCachePut)
+	req := c.newRequestOperation(OpCachePut)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteByte(req, WithExpiryPolicyFlagMask); err != nil {
-		return errors.Wrapf(err, "failed to write binary flag")
-	}
-
-	if err := WriteLong(req, ttl.Milliseconds()); err != nil {
-		return errors.Wrapf(err, "failed to write ttl")
-	}
-	if err := WriteLong(req, DurUnchanged); err != nil {
-		return errors.Wrapf(err, "failed to write ttl")
-	}
-	if err := WriteLong(req, DurUnchanged); err != nil {
-		return errors.Wrapf(err, "failed to write ttl")
+	expiry := &ExpiryPolicy{Create: ttl.Milliseconds(), Update: DurUnchanged, Access: DurUnchanged}
+	if err := c.writeCacheFlags(req, false, expiry); err != nil {
+		return errors.Wrapf(err, "failed to write expiry policy")
 	}
 
 	if err := WriteObject(req, key); err != nil {
@@ -204,7 +214,7 @@ func (c *client) CachePutWithTTL(cache string, key interface{}, value interface{
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_PUT operation")
 	}
 
@@ -213,15 +223,20 @@ func (c *client) CachePutWithTTL(cache string, key interface{}, value interface{
 
 // CachePutAll puts a value with a given key to cache (overwriting existing value if any).
 func (c *client) CachePutAll(cache string, binary bool, data map[interface{}]interface{}) error {
+	return c.CachePutAllContext(context.Background(), cache, binary, data)
+}
+
+// CachePutAllContext is equal to CachePutAll but uses ctx to cancel the operation or limit its duration.
+func (c *client) CachePutAllContext(ctx context.Context, cache string, binary bool, data map[interface{}]interface{}) error {
 	// request and response
-	req := NewRequestOperation(OpCachePutAll)
+	req := c.newRequestOperation(OpCachePutAll)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteInt(req, int32(len(data))); err != nil {
@@ -237,7 +252,7 @@ func (c *client) CachePutAll(cache string, binary bool, data map[interface{}]int
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_PUT_ALL operation")
 	}
 
@@ -245,30 +260,26 @@ func (c *client) CachePutAll(cache string, binary bool, data map[interface{}]int
 }
 
 func (c *client) CachePutAllWithTTL(cache string, data map[interface{}]interface{}, ttl time.Duration) error {
+	return c.CachePutAllWithTTLContext(context.Background(), cache, data, ttl)
+}
+
+// CachePutAllWithTTLContext is equal to CachePutAllWithTTL but uses ctx to cancel the operation or limit its duration.
+func (c *client) CachePutAllWithTTLContext(ctx context.Context, cache string, data map[interface{}]interface{}, ttl time.Duration) error {
 	if ttl.Milliseconds() == 0 {
 		return errors.NewError(1, "TTL should be more than a millisecond")
 	}
 
 	// request and response
-	req := NewRequestOperation(OpCachePutAll)
+	req := c.newRequestOperation(OpCachePutAll)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteByte(req, WithExpiryPolicyFlagMask); err != nil {
-		return errors.Wrapf(err, "failed to write binary flag")
-	}
-
-	if err := WriteLong(req, ttl.Milliseconds()); err != nil {
-		return errors.Wrapf(err, "failed to write ttl")
-	}
-	if err := WriteLong(req, DurUnchanged); err != nil {
-		return errors.Wrapf(err, "failed to write ttl")
-	}
-	if err := WriteLong(req, DurUnchanged); err != nil {
-		return errors.Wrapf(err, "failed to write ttl")
+	expiry := &ExpiryPolicy{Create: ttl.Milliseconds(), Update: DurUnchanged, Access: DurUnchanged}
+	if err := c.writeCacheFlags(req, false, expiry); err != nil {
+		return errors.Wrapf(err, "failed to write expiry policy")
 	}
 
 	if err := WriteInt(req, int32(len(data))); err != nil {
@@ -284,7 +295,7 @@ func (c *client) CachePutAllWithTTL(cache string, data map[interface{}]interface
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_PUT_ALL operation")
 	}
 
@@ -293,15 +304,20 @@ func (c *client) CachePutAllWithTTL(cache string, data map[interface{}]interface
 
 // CacheContainsKey returns a value indicating whether given key is present in cache.
 func (c *client) CacheContainsKey(cache string, binary bool, key interface{}) (bool, error) {
+	return c.CacheContainsKeyContext(context.Background(), cache, binary, key)
+}
+
+// CacheContainsKeyContext is equal to CacheContainsKey but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheContainsKeyContext(ctx context.Context, cache string, binary bool, key interface{}) (bool, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheContainsKey)
+	req := c.newRequestOperation(OpCacheContainsKey)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return false, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return false, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -309,7 +325,7 @@ func (c *client) CacheContainsKey(cache string, binary bool, key interface{}) (b
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return false, errors.Wrapf(err, "failed to execute OP_CACHE_CONTAINS_KEY operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -321,15 +337,20 @@ func (c *client) CacheContainsKey(cache string, binary bool, key interface{}) (b
 
 // CacheContainsKeys returns a value indicating whether all given keys are present in cache.
 func (c *client) CacheContainsKeys(cache string, binary bool, keys []interface{}) (bool, error) {
+	return c.CacheContainsKeysContext(context.Background(), cache, binary, keys)
+}
+
+// CacheContainsKeysContext is equal to CacheContainsKeys but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheContainsKeysContext(ctx context.Context, cache string, binary bool, keys []interface{}) (bool, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheContainsKeys)
+	req := c.newRequestOperation(OpCacheContainsKeys)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return false, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return false, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteInt(req, int32(len(keys))); err != nil {
@@ -342,7 +363,7 @@ func (c *client) CacheContainsKeys(cache string, binary bool, keys []interface{}
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return false, errors.Wrapf(err, "failed to execute OP_CACHE_CONTAINS_KEYS operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -354,15 +375,20 @@ func (c *client) CacheContainsKeys(cache string, binary bool, keys []interface{}
 
 // CacheGetAndPut puts a value with a given key to cache, and returns the previous value for that key.
 func (c *client) CacheGetAndPut(cache string, binary bool, key interface{}, value interface{}) (interface{}, error) {
+	return c.CacheGetAndPutContext(context.Background(), cache, binary, key, value)
+}
+
+// CacheGetAndPutContext is equal to CacheGetAndPut but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetAndPutContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (interface{}, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGetAndPut)
+	req := c.newRequestOperation(OpCacheGetAndPut)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return nil, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -373,28 +399,33 @@ func (c *client) CacheGetAndPut(cache string, binary bool, key interface{}, valu
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET_AND_PUT operation")
 	}
 	if err := res.CheckStatus(); err != nil {
 		return nil, err
 	}
 
-	return ReadObject(res)
+	return readCacheObject(res, binary)
 }
 
 // CacheGetAndReplace puts a value with a given key to cache, returning previous value for that key,
 // if and only if there is a value currently mapped for that key.
 func (c *client) CacheGetAndReplace(cache string, binary bool, key interface{}, value interface{}) (interface{}, error) {
+	return c.CacheGetAndReplaceContext(context.Background(), cache, binary, key, value)
+}
+
+// CacheGetAndReplaceContext is equal to CacheGetAndReplace but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetAndReplaceContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (interface{}, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGetAndReplace)
+	req := c.newRequestOperation(OpCacheGetAndReplace)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return nil, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -405,27 +436,32 @@ func (c *client) CacheGetAndReplace(cache string, binary bool, key interface{},
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET_AND_REPLACE operation")
 	}
 	if err := res.CheckStatus(); err != nil {
 		return nil, err
 	}
 
-	return ReadObject(res)
+	return readCacheObject(res, binary)
 }
 
 // CacheGetAndRemove removes the cache entry with specified key, returning the value.
 func (c *client) CacheGetAndRemove(cache string, binary bool, key interface{}) (interface{}, error) {
+	return c.CacheGetAndRemoveContext(context.Background(), cache, binary, key)
+}
+
+// CacheGetAndRemoveContext is equal to CacheGetAndRemove but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetAndRemoveContext(ctx context.Context, cache string, binary bool, key interface{}) (interface{}, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGetAndRemove)
+	req := c.newRequestOperation(OpCacheGetAndRemove)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return nil, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -433,27 +469,32 @@ func (c *client) CacheGetAndRemove(cache string, binary bool, key interface{}) (
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET_AND_REMOVE operation")
 	}
 	if err := res.CheckStatus(); err != nil {
 		return nil, err
 	}
 
-	return ReadObject(res)
+	return readCacheObject(res, binary)
 }
 
 // CachePutIfAbsent puts a value with a given key to cache only if the key does not already exist.
 func (c *client) CachePutIfAbsent(cache string, binary bool, key interface{}, value interface{}) (bool, error) {
+	return c.CachePutIfAbsentContext(context.Background(), cache, binary, key, value)
+}
+
+// CachePutIfAbsentContext is equal to CachePutIfAbsent but uses ctx to cancel the operation or limit its duration.
+func (c *client) CachePutIfAbsentContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (bool, error) {
 	// request and response
-	req := NewRequestOperation(OpCachePutIfAbsent)
+	req := c.newRequestOperation(OpCachePutIfAbsent)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return false, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return false, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -464,7 +505,7 @@ func (c *client) CachePutIfAbsent(cache string, binary bool, key interface{}, va
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return false, errors.Wrapf(err, "failed to execute OP_CACHE_PUT_IF_ABSENT operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -476,15 +517,20 @@ func (c *client) CachePutIfAbsent(cache string, binary bool, key interface{}, va
 
 // CacheGetAndPutIfAbsent puts a value with a given key to cache only if the key does not already exist.
 func (c *client) CacheGetAndPutIfAbsent(cache string, binary bool, key interface{}, value interface{}) (interface{}, error) {
+	return c.CacheGetAndPutIfAbsentContext(context.Background(), cache, binary, key, value)
+}
+
+// CacheGetAndPutIfAbsentContext is equal to CacheGetAndPutIfAbsent but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetAndPutIfAbsentContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (interface{}, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGetAndPutIfAbsent)
+	req := c.newRequestOperation(OpCacheGetAndPutIfAbsent)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return nil, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -495,27 +541,32 @@ func (c *client) CacheGetAndPutIfAbsent(cache string, binary bool, key interface
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_CACHE_GET_AND_PUT_IF_ABSENT operation")
 	}
 	if err := res.CheckStatus(); err != nil {
 		return nil, err
 	}
 
-	return ReadObject(res)
+	return readCacheObject(res, binary)
 }
 
 // CacheReplace puts a value with a given key to cache only if the key already exists.
 func (c *client) CacheReplace(cache string, binary bool, key interface{}, value interface{}) (bool, error) {
+	return c.CacheReplaceContext(context.Background(), cache, binary, key, value)
+}
+
+// CacheReplaceContext is equal to CacheReplace but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheReplaceContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (bool, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheReplace)
+	req := c.newRequestOperation(OpCacheReplace)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return false, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return false, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -526,7 +577,7 @@ func (c *client) CacheReplace(cache string, binary bool, key interface{}, value
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return false, errors.Wrapf(err, "failed to execute OP_CACHE_REPLACE operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -539,15 +590,20 @@ func (c *client) CacheReplace(cache string, binary bool, key interface{}, value
 // CacheReplaceIfEquals puts a value with a given key to cache only if
 // the key already exists and value equals provided value.
 func (c *client) CacheReplaceIfEquals(cache string, binary bool, key interface{}, valueCompare interface{}, valueNew interface{}) (bool, error) {
+	return c.CacheReplaceIfEqualsContext(context.Background(), cache, binary, key, valueCompare, valueNew)
+}
+
+// CacheReplaceIfEqualsContext is equal to CacheReplaceIfEquals but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheReplaceIfEqualsContext(ctx context.Context, cache string, binary bool, key interface{}, valueCompare interface{}, valueNew interface{}) (bool, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheReplaceIfEquals)
+	req := c.newRequestOperation(OpCacheReplaceIfEquals)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return false, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return false, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -561,7 +617,7 @@ func (c *client) CacheReplaceIfEquals(cache string, binary bool, key interface{}
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return false, errors.Wrapf(err, "failed to execute OP_CACHE_REPLACE_IF_EQUALS operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -573,20 +629,25 @@ func (c *client) CacheReplaceIfEquals(cache string, binary bool, key interface{}
 
 // CacheClear clears the cache without notifying listeners or cache writers.
 func (c *client) CacheClear(cache string, binary bool) error {
+	return c.CacheClearContext(context.Background(), cache, binary)
+}
+
+// CacheClearContext is equal to CacheClear but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheClearContext(ctx context.Context, cache string, binary bool) error {
 	// request and response
-	req := NewRequestOperation(OpCacheClear)
+	req := c.newRequestOperation(OpCacheClear)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return errors.Wrapf(err, "failed to write binary flag")
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_CLEAR operation")
 	}
 	return res.CheckStatus()
@@ -594,15 +655,20 @@ func (c *client) CacheClear(cache string, binary bool) error {
 
 // CacheClearKey clears the cache key without notifying listeners or cache writers.
 func (c *client) CacheClearKey(cache string, binary bool, key interface{}) error {
+	return c.CacheClearKeyContext(context.Background(), cache, binary, key)
+}
+
+// CacheClearKeyContext is equal to CacheClearKey but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheClearKeyContext(ctx context.Context, cache string, binary bool, key interface{}) error {
 	// request and response
-	req := NewRequestOperation(OpCacheClearKey)
+	req := c.newRequestOperation(OpCacheClearKey)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -610,7 +676,7 @@ func (c *client) CacheClearKey(cache string, binary bool, key interface{}) error
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_CLEAR_KEY operation")
 	}
 	return res.CheckStatus()
@@ -618,15 +684,20 @@ func (c *client) CacheClearKey(cache string, binary bool, key interface{}) error
 
 // CacheClearKeys clears the cache keys without notifying listeners or cache writers.
 func (c *client) CacheClearKeys(cache string, binary bool, keys []interface{}) error {
+	return c.CacheClearKeysContext(context.Background(), cache, binary, keys)
+}
+
+// CacheClearKeysContext is equal to CacheClearKeys but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheClearKeysContext(ctx context.Context, cache string, binary bool, keys []interface{}) error {
 	// request and response
-	req := NewRequestOperation(OpCacheClearKeys)
+	req := c.newRequestOperation(OpCacheClearKeys)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteInt(req, int32(len(keys))); err != nil {
@@ -639,7 +710,7 @@ func (c *client) CacheClearKeys(cache string, binary bool, keys []interface{}) e
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_CLEAR_KEYS operation")
 	}
 
@@ -648,15 +719,20 @@ func (c *client) CacheClearKeys(cache string, binary bool, keys []interface{}) e
 
 // CacheRemoveKey removes an entry with a given key, notifying listeners and cache writers.
 func (c *client) CacheRemoveKey(cache string, binary bool, key interface{}) (bool, error) {
+	return c.CacheRemoveKeyContext(context.Background(), cache, binary, key)
+}
+
+// CacheRemoveKeyContext is equal to CacheRemoveKey but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheRemoveKeyContext(ctx context.Context, cache string, binary bool, key interface{}) (bool, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheRemoveKey)
+	req := c.newRequestOperation(OpCacheRemoveKey)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return false, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return false, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -664,7 +740,7 @@ func (c *client) CacheRemoveKey(cache string, binary bool, key interface{}) (boo
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return false, errors.Wrapf(err, "failed to execute OP_CACHE_REMOVE_KEY operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -677,15 +753,20 @@ func (c *client) CacheRemoveKey(cache string, binary bool, key interface{}) (boo
 // CacheRemoveIfEquals removes an entry with a given key if provided value is equal to actual value,
 // notifying listeners and cache writers.
 func (c *client) CacheRemoveIfEquals(cache string, binary bool, key interface{}, value interface{}) (bool, error) {
+	return c.CacheRemoveIfEqualsContext(context.Background(), cache, binary, key, value)
+}
+
+// CacheRemoveIfEqualsContext is equal to CacheRemoveIfEquals but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheRemoveIfEqualsContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (bool, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheRemoveIfEquals)
+	req := c.newRequestOperation(OpCacheRemoveIfEquals)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return false, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return false, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteObject(req, key); err != nil {
@@ -696,7 +777,7 @@ func (c *client) CacheRemoveIfEquals(cache string, binary bool, key interface{},
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return false, errors.Wrapf(err, "failed to execute OP_CACHE_REMOVE_IF_EQUALS operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -708,15 +789,20 @@ func (c *client) CacheRemoveIfEquals(cache string, binary bool, key interface{},
 
 // CacheGetSize gets the number of entries in cache.
 func (c *client) CacheGetSize(cache string, binary bool, modes []byte) (int64, error) {
+	return c.CacheGetSizeContext(context.Background(), cache, binary, modes)
+}
+
+// CacheGetSizeContext is equal to CacheGetSize but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheGetSizeContext(ctx context.Context, cache string, binary bool, modes []byte) (int64, error) {
 	// request and response
-	req := NewRequestOperation(OpCacheGetSize)
+	req := c.newRequestOperation(OpCacheGetSize)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return 0, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return 0, errors.Wrapf(err, "failed to write binary flag")
 	}
 	var count int32
@@ -735,7 +821,7 @@ func (c *client) CacheGetSize(cache string, binary bool, modes []byte) (int64, e
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return 0, errors.Wrapf(err, "failed to execute OP_CACHE_GET_SIZE operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -747,15 +833,20 @@ func (c *client) CacheGetSize(cache string, binary bool, modes []byte) (int64, e
 
 // CacheRemoveKeys removes entries with given keys, notifying listeners and cache writers.
 func (c *client) CacheRemoveKeys(cache string, binary bool, keys []interface{}) error {
+	return c.CacheRemoveKeysContext(context.Background(), cache, binary, keys)
+}
+
+// CacheRemoveKeysContext is equal to CacheRemoveKeys but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheRemoveKeysContext(ctx context.Context, cache string, binary bool, keys []interface{}) error {
 	// request and response
-	req := NewRequestOperation(OpCacheRemoveKeys)
+	req := c.newRequestOperation(OpCacheRemoveKeys)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err := WriteInt(req, int32(len(keys))); err != nil {
@@ -768,7 +859,7 @@ func (c *client) CacheRemoveKeys(cache string, binary bool, keys []interface{})
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_REMOVE_KEYS operation")
 	}
 
@@ -777,22 +868,56 @@ func (c *client) CacheRemoveKeys(cache string, binary bool, keys []interface{})
 
 // CacheRemoveAll destroys cache with a given name.
 func (c *client) CacheRemoveAll(cache string, binary bool) error {
+	return c.CacheRemoveAllContext(context.Background(), cache, binary)
+}
+
+// CacheRemoveAllContext is equal to CacheRemoveAll but uses ctx to cancel the operation or limit its duration.
+func (c *client) CacheRemoveAllContext(ctx context.Context, cache string, binary bool) error {
 	// request and response
-	req := NewRequestOperation(OpCacheRemoveAll)
+	req := c.newRequestOperation(OpCacheRemoveAll)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return errors.Wrapf(err, "failed to write binary flag")
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_CACHE_REMOVE_ALL operation")
 	}
 
 	return res.CheckStatus()
 }
+
+// writeCacheFlags writes flags of Key-Value operation or SQL query request followed by expiry policy if it is not nil
+// and ID of the transaction if the client is bound to the transaction
+func (c *client) writeCacheFlags(w io.Writer, binary bool, expiry *ExpiryPolicy) error {
+	var flags byte
+	if binary {
+		flags |= KeepBinaryFlagMask
+	}
+	if expiry != nil {
+		flags |= WithExpiryPolicyFlagMask
+	}
+	if c.owner != nil {
+		flags |= TransactionalFlagMask
+	}
+	if err := WriteByte(w, flags); err != nil {
+		return err
+	}
+	if expiry != nil {
+		for _, d := range []int64{expiry.Create, expiry.Update, expiry.Access} {
+			if err := WriteLong(w, d); err != nil {
+				return err
+			}
+		}
+	}
+	if c.owner != nil {
+		return WriteInt(w, c.txID)
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package ignite
 
 import (
+	"context"
+	stderrors "errors"
+	"io"
+	"io/ioutil"
+
 	"github.com/amsokol/ignite-go-client/binary/errors"
 )
 
@@ -167,8 +172,13 @@ type QueryScanResult struct {
 }
 
 func (c *client) QuerySQL(cache string, binary bool, data QuerySQLData) (QuerySQLResult, error) {
+	return c.QuerySQLContext(context.Background(), cache, binary, data)
+}
+
+// QuerySQLContext is equal to QuerySQL but uses ctx to cancel the operation or limit its duration.
+func (c *client) QuerySQLContext(ctx context.Context, cache string, binary bool, data QuerySQLData) (QuerySQLResult, error) {
 	// request and response
-	req := NewRequestOperation(OpQuerySQL)
+	req := c.newRequestOperation(OpQuerySQL)
 	res := NewResponseOperation(req.UID)
 
 	r := QuerySQLResult{QuerySQLPage: QuerySQLPage{Rows: map[interface{}]interface{}{}}}
@@ -178,7 +188,7 @@ func (c *client) QuerySQL(cache string, binary bool, data QuerySQLData) (QuerySQ
 	if err = WriteInt(req, HashCode(cache)); err != nil {
 		return r, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err = WriteBool(req, binary); err != nil {
+	if err = c.writeCacheFlags(req, binary, nil); err != nil {
 		return r, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if err = WriteOString(req, data.Table); err != nil {
@@ -221,7 +231,7 @@ func (c *client) QuerySQL(cache string, binary bool, data QuerySQLData) (QuerySQ
 	}
 
 	// execute operation
-	if err = c.Do(req, res); err != nil {
+	if err = c.DoContext(ctx, req, res); err != nil {
 		return r, errors.Wrapf(err, "failed to execute OP_QUERY_SQL operation")
 	}
 	if err = res.CheckStatus(); err != nil {
@@ -256,8 +266,13 @@ func (c *client) QuerySQL(cache string, binary bool, data QuerySQLData) (QuerySQ
 
 // QuerySQLCursorGetPage retrieves the next SQL query cursor page by cursor id from QuerySQL.
 func (c *client) QuerySQLCursorGetPage(id int64) (QuerySQLPage, error) {
+	return c.QuerySQLCursorGetPageContext(context.Background(), id)
+}
+
+// QuerySQLCursorGetPageContext is equal to QuerySQLCursorGetPage but uses ctx to cancel the operation or limit its duration.
+func (c *client) QuerySQLCursorGetPageContext(ctx context.Context, id int64) (QuerySQLPage, error) {
 	// request and response
-	req := NewRequestOperation(OpQuerySQLCursorGetPage)
+	req := c.newRequestOperation(OpQuerySQLCursorGetPage)
 	res := NewResponseOperation(req.UID)
 
 	r := QuerySQLPage{Rows: map[interface{}]interface{}{}}
@@ -269,7 +284,7 @@ func (c *client) QuerySQLCursorGetPage(id int64) (QuerySQLPage, error) {
 	}
 
 	// execute operation
-	if err = c.Do(req, res); err != nil {
+	if err = c.DoContext(ctx, req, res); err != nil {
 		return r, errors.Wrapf(err, "failed to execute OP_QUERY_SQL_CURSOR_GET_PAGE operation")
 	}
 	if err = res.CheckStatus(); err != nil {
@@ -301,8 +316,13 @@ func (c *client) QuerySQLCursorGetPage(id int64) (QuerySQLPage, error) {
 }
 
 func (c *client) QuerySQLFieldsRaw(cache string, binary bool, data QuerySQLFieldsData) (*ResponseOperation, error) {
+	return c.QuerySQLFieldsRawContext(context.Background(), cache, binary, data)
+}
+
+// QuerySQLFieldsRawContext is equal to QuerySQLFieldsRaw but uses ctx to cancel the operation or limit its duration.
+func (c *client) QuerySQLFieldsRawContext(ctx context.Context, cache string, binary bool, data QuerySQLFieldsData) (*ResponseOperation, error) {
 	// request and response
-	req := NewRequestOperation(OpQuerySQLFields)
+	req := c.newRequestOperation(OpQuerySQLFields)
 	res := NewResponseOperation(req.UID)
 
 	var err error
@@ -311,7 +331,7 @@ func (c *client) QuerySQLFieldsRaw(cache string, binary bool, data QuerySQLField
 	if err := WriteInt(req, HashCode(cache)); err != nil {
 		return nil, errors.Wrapf(err, "failed to write cache name")
 	}
-	if err := WriteBool(req, binary); err != nil {
+	if err := c.writeCacheFlags(req, binary, nil); err != nil {
 		return nil, errors.Wrapf(err, "failed to write binary flag")
 	}
 	if len(data.Schema) > 0 {
@@ -378,7 +398,7 @@ func (c *client) QuerySQLFieldsRaw(cache string, binary bool, data QuerySQLField
 	}
 
 	// execute operation
-	if err = c.Do(req, res); err != nil {
+	if err = c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_QUERY_SQL_FIELDS operation")
 	}
 	if err = res.CheckStatus(); err != nil {
@@ -390,9 +410,14 @@ func (c *client) QuerySQLFieldsRaw(cache string, binary bool, data QuerySQLField
 
 // QuerySQLFields performs SQL fields query.
 func (c *client) QuerySQLFields(cache string, binary bool, data QuerySQLFieldsData) (QuerySQLFieldsResult, error) {
+	return c.QuerySQLFieldsContext(context.Background(), cache, binary, data)
+}
+
+// QuerySQLFieldsContext is equal to QuerySQLFields but uses ctx to cancel the operation or limit its duration.
+func (c *client) QuerySQLFieldsContext(ctx context.Context, cache string, binary bool, data QuerySQLFieldsData) (QuerySQLFieldsResult, error) {
 	var r QuerySQLFieldsResult
 
-	res, err := c.QuerySQLFieldsRaw(cache, binary, data)
+	res, err := c.QuerySQLFieldsRawContext(ctx, cache, binary, data)
 	if err != nil {
 		return r, err
 	}
@@ -442,8 +467,13 @@ func (c *client) QuerySQLFields(cache string, binary bool, data QuerySQLFieldsDa
 }
 
 func (c *client) QuerySQLFieldsCursorGetPageRaw(id int64) (*ResponseOperation, error) {
+	return c.QuerySQLFieldsCursorGetPageRawContext(context.Background(), id)
+}
+
+// QuerySQLFieldsCursorGetPageRawContext is equal to QuerySQLFieldsCursorGetPageRaw but uses ctx to cancel the operation or limit its duration.
+func (c *client) QuerySQLFieldsCursorGetPageRawContext(ctx context.Context, id int64) (*ResponseOperation, error) {
 	// request and response
-	req := NewRequestOperation(OpQuerySQLFieldsCursorGetPage)
+	req := c.newRequestOperation(OpQuerySQLFieldsCursorGetPage)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
@@ -452,7 +482,7 @@ func (c *client) QuerySQLFieldsCursorGetPageRaw(id int64) (*ResponseOperation, e
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute OP_QUERY_SQL_FIELDS_CURSOR_GET_PAGE operation")
 	}
 	if err := res.CheckStatus(); err != nil {
@@ -464,9 +494,14 @@ func (c *client) QuerySQLFieldsCursorGetPageRaw(id int64) (*ResponseOperation, e
 
 // QuerySQLFieldsCursorGetPage retrieves the next query result page by cursor id from QuerySQLFields.
 func (c *client) QuerySQLFieldsCursorGetPage(id int64, fieldCount int) (QuerySQLFieldsPage, error) {
+	return c.QuerySQLFieldsCursorGetPageContext(context.Background(), id, fieldCount)
+}
+
+// QuerySQLFieldsCursorGetPageContext is equal to QuerySQLFieldsCursorGetPage but uses ctx to cancel the operation or limit its duration.
+func (c *client) QuerySQLFieldsCursorGetPageContext(ctx context.Context, id int64, fieldCount int) (QuerySQLFieldsPage, error) {
 	var r QuerySQLFieldsPage
 
-	res, err := c.QuerySQLFieldsCursorGetPageRaw(id)
+	res, err := c.QuerySQLFieldsCursorGetPageRawContext(ctx, id)
 	if err != nil {
 		return r, err
 	}
@@ -494,37 +529,22 @@ func (c *client) QuerySQLFieldsCursorGetPage(id int64, fieldCount int) (QuerySQL
 }
 
 func (c *client) QueryScan(cache string, binary bool, data QueryScanData) (QueryScanResult, error) {
+	return c.QueryScanContext(context.Background(), cache, binary, data)
+}
+
+// QueryScanContext is equal to QueryScan but uses ctx to cancel the operation or limit its duration.
+func (c *client) QueryScanContext(ctx context.Context, cache string, binary bool, data QueryScanData) (QueryScanResult, error) {
 	// request and response
-	req := NewRequestOperation(OpQueryScan)
+	req, err := c.newQueryScanRequest(cache, binary, data)
+	if err != nil {
+		return QueryScanResult{}, err
+	}
 	res := NewResponseOperation(req.UID)
 
 	r := QueryScanResult{QueryScanPage: QueryScanPage{Rows: map[interface{}]interface{}{}}}
-	var err error
-
-	// set parameters
-	if err = WriteInt(req, HashCode(cache)); err != nil {
-		return r, errors.Wrapf(err, "failed to write cache name")
-	}
-	if err = WriteBool(req, binary); err != nil {
-		return r, errors.Wrapf(err, "failed to write binary flag")
-	}
-	// filtering is not supported
-	if err = WriteNull(req); err != nil {
-		return r, errors.Wrapf(err, "failed to write null as filter object")
-	}
-
-	if err = WriteInt(req, int32(data.PageSize)); err != nil {
-		return r, errors.Wrapf(err, "failed to write page size")
-	}
-	if err = WriteInt(req, int32(data.Partitions)); err != nil {
-		return r, errors.Wrapf(err, "failed to write number of partitions to query")
-	}
-	if err = WriteBool(req, data.LocalQuery); err != nil {
-		return r, errors.Wrapf(err, "failed to write local query flag")
-	}
 
 	// execute operation
-	if err = c.Do(req, res); err != nil {
+	if err = c.DoContext(ctx, req, res); err != nil {
 		return r, errors.Wrapf(err, "failed to execute OP_QUERY_SCAN operation")
 	}
 	if err = res.CheckStatus(); err != nil {
@@ -535,32 +555,63 @@ func (c *client) QueryScan(cache string, binary bool, data QueryScanData) (Query
 	if r.ID, err = ReadLong(res); err != nil {
 		return r, errors.Wrapf(err, "failed to read cursor ID")
 	}
-	count, err := ReadInt(res)
+	r.HasMore, err = readQueryScanRows(res, func(key, value interface{}) error {
+		r.Rows[key] = value
+		return nil
+	})
+	return r, err
+}
+
+// QueryScanEach is equal to QueryScan but calls fn for every row of the first page instead of collecting them.
+func (c *client) QueryScanEach(cache string, binary bool, data QueryScanData,
+	fn func(key, value interface{}) error) (QueryScanResult, error) {
+	return c.QueryScanEachContext(context.Background(), cache, binary, data, fn)
+}
+
+// QueryScanEachContext is equal to QueryScanEach but uses ctx to cancel the operation or limit its duration.
+func (c *client) QueryScanEachContext(ctx context.Context, cache string, binary bool, data QueryScanData,
+	fn func(key, value interface{}) error) (QueryScanResult, error) {
+	// request and response
+	req, err := c.newQueryScanRequest(cache, binary, data)
 	if err != nil {
-		return r, errors.Wrapf(err, "failed to read row count")
+		return QueryScanResult{}, err
 	}
-	// read data
-	for i := 0; i < int(count); i++ {
-		key, err := ReadObject(res)
-		if err != nil {
-			return r, errors.Wrapf(err, "failed to read key with index %d", i)
+	res := NewResponseOperation(req.UID)
+
+	var r QueryScanResult
+	var page scanPage
+
+	// execute operation and process result
+	err = c.streamContext(ctx, req, res, func() error {
+		if err := res.CheckStatus(); err != nil {
+			return err
 		}
-		value, err := ReadObject(res)
-		if err != nil {
-			return r, errors.Wrapf(err, "failed to read value with index %d", i)
+		var err error
+		if r.ID, err = ReadLong(res); err != nil {
+			return errors.Wrapf(err, "failed to read cursor ID")
 		}
-		r.Rows[key] = value
+		page, err = readQueryScanPage(res)
+		return err
+	})
+	if err != nil {
+		return r, errors.Wrapf(err, "failed to execute OP_QUERY_SCAN operation")
 	}
-	if r.HasMore, err = ReadBool(res); err != nil {
-		return r, errors.Wrapf(err, "failed to read has more flag")
+	if err = c.readScanPageRest(&page); err != nil {
+		return r, err
 	}
-	return r, nil
+	r.HasMore = page.hasMore
+	return r, eachScanRow(page.rows, fn)
 }
 
 // QueryScanCursorGetPage fetches the next SQL query cursor page by cursor id that is obtained from OP_QUERY_SCAN.
 func (c *client) QueryScanCursorGetPage(id int64) (QueryScanPage, error) {
+	return c.QueryScanCursorGetPageContext(context.Background(), id)
+}
+
+// QueryScanCursorGetPageContext is equal to QueryScanCursorGetPage but uses ctx to cancel the operation or limit its duration.
+func (c *client) QueryScanCursorGetPageContext(ctx context.Context, id int64) (QueryScanPage, error) {
 	// request and response
-	req := NewRequestOperation(OpQueryScanCursorGetPage)
+	req := c.newRequestOperation(OpQueryScanCursorGetPage)
 	res := NewResponseOperation(req.UID)
 
 	r := QueryScanPage{Rows: map[interface{}]interface{}{}}
@@ -572,7 +623,7 @@ func (c *client) QueryScanCursorGetPage(id int64) (QueryScanPage, error) {
 	}
 
 	// execute operation
-	if err = c.Do(req, res); err != nil {
+	if err = c.DoContext(ctx, req, res); err != nil {
 		return r, errors.Wrapf(err, "failed to execute OP_QUERY_SCAN_CURSOR_GET_PAGE operation")
 	}
 	if err = res.CheckStatus(); err != nil {
@@ -580,33 +631,233 @@ func (c *client) QueryScanCursorGetPage(id int64) (QueryScanPage, error) {
 	}
 
 	// process result
-	count, err := ReadInt(res)
+	r.HasMore, err = readQueryScanRows(res, func(key, value interface{}) error {
+		r.Rows[key] = value
+		return nil
+	})
+	return r, err
+}
+
+// QueryScanCursorGetPageEach is equal to QueryScanCursorGetPage but calls fn for every row of the page instead of collecting them.
+func (c *client) QueryScanCursorGetPageEach(id int64, fn func(key, value interface{}) error) (QueryScanPage, error) {
+	return c.QueryScanCursorGetPageEachContext(context.Background(), id, fn)
+}
+
+// QueryScanCursorGetPageEachContext is equal to QueryScanCursorGetPageEach but uses ctx to cancel the operation or limit its duration.
+func (c *client) QueryScanCursorGetPageEachContext(ctx context.Context, id int64,
+	fn func(key, value interface{}) error) (QueryScanPage, error) {
+	// request and response
+	req := c.newRequestOperation(OpQueryScanCursorGetPage)
+	res := NewResponseOperation(req.UID)
+
+	var r QueryScanPage
+	var page scanPage
+
+	// set parameters
+	if err := WriteLong(req, id); err != nil {
+		return r, errors.Wrapf(err, "failed to write cursor id")
+	}
+
+	// execute operation and process result
+	err := c.streamContext(ctx, req, res, func() error {
+		if err := res.CheckStatus(); err != nil {
+			return err
+		}
+		var err error
+		page, err = readQueryScanPage(res)
+		return err
+	})
 	if err != nil {
-		return r, errors.Wrapf(err, "failed to read row count")
+		return r, errors.Wrapf(err, "failed to execute OP_QUERY_SCAN_CURSOR_GET_PAGE operation")
 	}
-	// read data
-	for i := 0; i < int(count); i++ {
-		key, err := ReadObject(res)
+	if err = c.readScanPageRest(&page); err != nil {
+		return r, err
+	}
+	r.HasMore = page.hasMore
+	return r, eachScanRow(page.rows, fn)
+}
+
+// newQueryScanRequest creates OP_QUERY_SCAN request
+func (c *client) newQueryScanRequest(cache string, binary bool, data QueryScanData) (*RequestOperation, error) {
+	req := c.newRequestOperation(OpQueryScan)
+
+	if err := WriteInt(req, HashCode(cache)); err != nil {
+		return nil, errors.Wrapf(err, "failed to write cache name")
+	}
+	if err := WriteBool(req, binary); err != nil {
+		return nil, errors.Wrapf(err, "failed to write binary flag")
+	}
+	// filtering is not supported
+	if err := WriteNull(req); err != nil {
+		return nil, errors.Wrapf(err, "failed to write null as filter object")
+	}
+
+	if err := WriteInt(req, int32(data.PageSize)); err != nil {
+		return nil, errors.Wrapf(err, "failed to write page size")
+	}
+	if err := WriteInt(req, int32(data.Partitions)); err != nil {
+		return nil, errors.Wrapf(err, "failed to write number of partitions to query")
+	}
+	if err := WriteBool(req, data.LocalQuery); err != nil {
+		return nil, errors.Wrapf(err, "failed to write local query flag")
+	}
+	return req, nil
+}
+
+// scanRow is row of the scan query page
+type scanRow struct {
+	key   interface{}
+	value interface{}
+}
+
+// scanPage is scan query page read from the response stream
+type scanPage struct {
+	rows    []scanRow
+	hasMore bool
+	// rest is unread part of the page starting from the row with the object which schema is not known.
+	// The schema can't be got from the cluster while the response is streamed, so the rest is read after that.
+	rest []byte
+	// left is count of the rows in rest
+	left int
+}
+
+// readQueryScanPage reads rows of the scan query page streamed from the connection in order they are received.
+// Reading is stopped at the row with the object with compact footer which schema is not known,
+// the rest of the page is kept in memory then.
+func readQueryScanPage(r io.Reader) (scanPage, error) {
+	var p scanPage
+	count, err := readLength(r, 2)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to read row count")
+	}
+	p.rows = make([]scanRow, 0, capacity(r, count))
+	row := &recordingReader{r: r}
+	for i := 0; i < count; i++ {
+		row.b = row.b[:0]
+		key, value, err := readScanRow(row, i)
+		var serr *unknownSchemaError
+		if stderrors.As(err, &serr) {
+			rest, err := ioutil.ReadAll(r)
+			if err != nil {
+				return p, errors.Wrapf(err, "failed to read rows")
+			}
+			p.rest, p.left = append(row.b, rest...), count-i
+			return p, nil
+		}
 		if err != nil {
-			return r, errors.Wrapf(err, "failed to read key with index %d", i)
+			return p, err
 		}
-		value, err := ReadObject(res)
+		p.rows = append(p.rows, scanRow{key: key, value: value})
+	}
+	if p.hasMore, err = ReadBool(r); err != nil {
+		return p, errors.Wrapf(err, "failed to read has more flag")
+	}
+	return p, nil
+}
+
+// readScanPageRest reads the rest of the page with the objects which schemas are got from the cluster
+func (c *client) readScanPageRest(p *scanPage) error {
+	if p.rest == nil {
+		return nil
+	}
+	d := &decoder{b: p.rest, resolver: c}
+	for i := len(p.rows); p.left > 0; i, p.left = i+1, p.left-1 {
+		key, value, err := readScanRow(d, i)
 		if err != nil {
-			return r, errors.Wrapf(err, "failed to read value with index %d", i)
+			return err
 		}
-		r.Rows[key] = value
+		p.rows = append(p.rows, scanRow{key: key, value: value})
 	}
-	if r.HasMore, err = ReadBool(res); err != nil {
-		return r, errors.Wrapf(err, "failed to read has more flag")
+	p.rest = nil
+	var err error
+	if p.hasMore, err = ReadBool(d); err != nil {
+		return errors.Wrapf(err, "failed to read has more flag")
 	}
+	return nil
+}
 
-	return r, nil
+// readScanRow reads key and value of the row with index i
+func readScanRow(r io.Reader, i int) (interface{}, interface{}, error) {
+	key, err := ReadObject(r)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read key with index %d", i)
+	}
+	value, err := ReadObject(r)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read value with index %d", i)
+	}
+	return key, value, nil
+}
+
+// recordingReader keeps bytes read from r
+type recordingReader struct {
+	r io.Reader
+	b []byte
+}
+
+// Read reads up to len(p) bytes from r into p
+func (rr *recordingReader) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	rr.b = append(rr.b, p[:n]...)
+	return n, err
+}
+
+// next returns the next n bytes read from r
+func (rr *recordingReader) next(n int) ([]byte, error) {
+	b, err := readNext(rr.r, n)
+	rr.b = append(rr.b, b...)
+	return b, err
+}
+
+// remaining returns count of the unread bytes of r, -1 if it is unknown
+func (rr *recordingReader) remaining() int64 {
+	return remaining(rr.r)
+}
+
+// eachScanRow calls fn for every row of the page.
+// It is called after the response is received, so fn may use the client.
+func eachScanRow(rows []scanRow, fn func(key, value interface{}) error) error {
+	for _, row := range rows {
+		if err := fn(row.key, row.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readQueryScanRows reads rows of the scan query page and calls fn for every row.
+// Returns has more flag of the page.
+func readQueryScanRows(r io.Reader, fn func(key, value interface{}) error) (bool, error) {
+	count, err := ReadInt(r)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read row count")
+	}
+	// read data
+	for i := 0; i < int(count); i++ {
+		key, value, err := readScanRow(r, i)
+		if err != nil {
+			return false, err
+		}
+		if err = fn(key, value); err != nil {
+			return false, err
+		}
+	}
+	hasMore, err := ReadBool(r)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read has more flag")
+	}
+	return hasMore, nil
 }
 
 // ResourceClose closes a resource, such as query cursor.
 func (c *client) ResourceClose(id int64) error {
+	return c.ResourceCloseContext(context.Background(), id)
+}
+
+// ResourceCloseContext is equal to ResourceClose but uses ctx to cancel the operation or limit its duration.
+func (c *client) ResourceCloseContext(ctx context.Context, id int64) error {
 	// request and response
-	req := NewRequestOperation(OpResourceClose)
+	req := c.newRequestOperation(OpResourceClose)
 	res := NewResponseOperation(req.UID)
 
 	// set parameters
@@ -615,7 +866,7 @@ func (c *client) ResourceClose(id int64) error {
 	}
 
 	// execute operation
-	if err := c.Do(req, res); err != nil {
+	if err := c.DoContext(ctx, req, res); err != nil {
 		return errors.Wrapf(err, "failed to execute OP_RESOURCE_CLOSE operation")
 	}
 
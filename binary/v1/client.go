@@ -1,38 +1,152 @@
 package ignite
 
 import (
+	"context"
 	"crypto/tls"
-	"fmt"
+	stderrors "errors"
+	"io"
 	"net"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/amsokol/ignite-go-client/binary/errors"
 	"github.com/amsokol/ignite-go-client/debug"
 )
 
 // ConnInfo contains connections parameters
 type ConnInfo struct {
-	Network, Host       string
-	Port                int
+	Network, Host string
+	Port          int
+	// Protocol version requested by the client. If the server does not support it the highest version
+	// supported by both sides is used. The highest version supported by the client is used if it is not set.
 	Major, Minor, Patch int
 	Username, Password  string
 	Dialer              net.Dialer
 	TLSConfig           *tls.Config
+
+	// Endpoints is list of the cluster nodes to connect to.
+	// If connection to the node fails the next one is used.
+	// Host and Port are used if the list is empty.
+	Endpoints []Endpoint
+
+	// Reconnect contains parameters of reconnection after the connection is broken
+	Reconnect ReconnectConfig
+
+	// PartitionAwareness enables sending of key-value operations straight to the primary node of the key.
+	// The client connects to all Endpoints. Protocol version 1.4.0 or above is required.
+	PartitionAwareness bool
+
+	// UserAttributes are sent to the server in the handshake (protocol version 1.5.0 and above)
+	UserAttributes map[string]string
+
+	// HeartbeatInterval enables heartbeats if positive. The client pings the server when the connection is idle
+	// during the interval, the connection is broken if the server does not reply within the interval.
+	// OP_HEARTBEAT is used if the server supports it, cheap OP_CACHE_GET_NAMES otherwise.
+	HeartbeatInterval time.Duration
+
+	// MaxFrameSize is the maximum length of the response message. Longer response is skipped
+	// and the operation fails with FrameSizeError. DefaultMaxFrameSize is used if value is not positive.
+	MaxFrameSize int
+
+	// ReadBufferSize is size of the buffer responses are read from the connection through.
+	// DefaultReadBufferSize is used if value is not positive.
+	ReadBufferSize int
+
+	// CompactFooter enables compact footers of the complex objects written by the client.
+	// Field IDs are not written in the compact footer, the schema is identified by schema ID.
+	// Compact footer is written only if the schema is registered in the cluster: it is received from
+	// the cluster by GetBinaryType (ResolveBinaryType) or registered by PutBinaryType of the client.
+	// Compact footers must be enabled in the binary configuration of the cluster (BinaryConfiguration.compactFooter).
+	CompactFooter bool
+}
+
+// ErrClientClosed is returned by operations of the closed client
+var ErrClientClosed = errors.Errorf("client is closed")
+
+// ConnectionState is state of the client connection to the cluster
+type ConnectionState int
+
+const (
+	// StateConnecting means the client is reconnecting to the cluster after the connection is broken
+	StateConnecting ConnectionState = iota
+	// StateReady means the client is connected to the cluster
+	StateReady
+	// StateBroken means the connection is broken and the first reconnection attempt failed,
+	// reconnection continues in background
+	StateBroken
+	// StateClosed means the client is closed
+	StateClosed
+)
+
+// String returns name of the state
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateReady:
+		return "ready"
+	case StateBroken:
+		return "broken"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// Endpoint is address of the cluster node
+type Endpoint struct {
+	Host string
+	Port int
+}
+
+// String returns address of the endpoint in "host:port" form
+func (e Endpoint) String() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
 }
 
 // Client is interface to communicate with Apache Ignite cluster.
-// Client is thread safe.
+// Client is thread safe. Requests of concurrent goroutines are pipelined over the connection.
 type Client interface {
 	// Connected return true if connection to the cluster is active
 	Connected() bool
 
+	// State returns state of the connection to the cluster
+	State() ConnectionState
+
+	// ActiveEndpoint returns endpoint of the cluster node the client is connected to
+	ActiveEndpoint() Endpoint
+
+	// ProtocolVersion returns binary protocol version negotiated with the cluster node
+	ProtocolVersion() ProtocolVersion
+
+	// NodeID returns ID of the cluster node the client is connected to (protocol version 1.4.0 and above)
+	NodeID() uuid.UUID
+
+	// Features returns optional protocol features supported by both the client and the cluster node
+	// (protocol version 1.7.0 and above)
+	Features() Features
+
+	// HasFeature returns true if optional protocol feature f is supported by both the client and the cluster node
+	HasFeature(f Feature) bool
+
 	// Do sends request and receives response
 	Do(req Request, res Response) error
 
-	// Close closes connection.
+	// DoContext sends request and receives response.
+	// Requests of concurrent calls are pipelined over the connection.
+	// If ctx is done before response is received the call returns immediately with ctx error,
+	// request is not sent if it is still in queue, and its response is skipped.
+	DoContext(ctx context.Context, req Request, res Response) error
+
+	// Close closes connection. Requests in progress, including the ones waiting for the connection
+	// to be opened, fail with ErrClientClosed, Close returns when all operations in progress are finished.
+	// Operations of the closed client return ErrClientClosed.
 	// Returns:
 	// nil in case of success.
 	// error object in case of error.
@@ -47,34 +161,55 @@ type Client interface {
 	// https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_create_with_name
 	CacheCreateWithName(cache string) error
 
+	// CacheCreateWithNameContext is equal to CacheCreateWithName but uses ctx to cancel the operation or limit its duration.
+	CacheCreateWithNameContext(ctx context.Context, cache string) error
+
 	// CacheGetOrCreateWithName creates a cache with a given name.
 	// Cache template can be applied if there is a '*' in the cache name.
 	// Does nothing if the cache exists.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_get_or_create_with_name
 	CacheGetOrCreateWithName(cache string) error
 
+	// CacheGetOrCreateWithNameContext is equal to CacheGetOrCreateWithName but uses ctx to cancel the operation or limit its duration.
+	CacheGetOrCreateWithNameContext(ctx context.Context, cache string) error
+
 	// CacheGetNames returns existing cache names.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_get_names
 	CacheGetNames() ([]string, error)
 
+	// CacheGetNamesContext is equal to CacheGetNames but uses ctx to cancel the operation or limit its duration.
+	CacheGetNamesContext(ctx context.Context) ([]string, error)
+
 	// CacheGetConfiguration gets configuration for the given cache.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_get_configuration
 	CacheGetConfiguration(cache string, flag byte) (*CacheConfiguration, error)
 
+	// CacheGetConfigurationContext is equal to CacheGetConfiguration but uses ctx to cancel the operation or limit its duration.
+	CacheGetConfigurationContext(ctx context.Context, cache string, flag byte) (*CacheConfiguration, error)
+
 	// CacheCreateWithConfiguration creates cache with provided configuration.
 	// An error is returned if the name is already in use.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_create_with_configuration
 	CacheCreateWithConfiguration(cc *CacheConfigurationRefs) error
 
+	// CacheCreateWithConfigurationContext is equal to CacheCreateWithConfiguration but uses ctx to cancel the operation or limit its duration.
+	CacheCreateWithConfigurationContext(ctx context.Context, cc *CacheConfigurationRefs) error
+
 	// CacheGetOrCreateWithConfiguration creates cache with provided configuration.
 	// Does nothing if the name is already in use.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_get_or_create_with_configuration
 	CacheGetOrCreateWithConfiguration(cc *CacheConfigurationRefs) error
 
+	// CacheGetOrCreateWithConfigurationContext is equal to CacheGetOrCreateWithConfiguration but uses ctx to cancel the operation or limit its duration.
+	CacheGetOrCreateWithConfigurationContext(ctx context.Context, cc *CacheConfigurationRefs) error
+
 	// CacheDestroy destroys cache with a given name.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-cache-configuration-operations#section-op_cache_destroy
 	CacheDestroy(cache string) error
 
+	// CacheDestroyContext is equal to CacheDestroy but uses ctx to cancel the operation or limit its duration.
+	CacheDestroyContext(ctx context.Context, cache string) error
+
 	// Key-Value Queries
 	// See for details:
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations
@@ -83,93 +218,162 @@ type Client interface {
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_get
 	CacheGet(cache string, binary bool, key interface{}) (interface{}, error)
 
+	// CacheGetContext is equal to CacheGet but uses ctx to cancel the operation or limit its duration.
+	CacheGetContext(ctx context.Context, cache string, binary bool, key interface{}) (interface{}, error)
+
 	// CacheGetAll retrieves multiple key-value pairs from cache.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_get_all
 	CacheGetAll(cache string, binary bool, keys []interface{}) (map[interface{}]interface{}, error)
 
+	// CacheGetAllContext is equal to CacheGetAll but uses ctx to cancel the operation or limit its duration.
+	CacheGetAllContext(ctx context.Context, cache string, binary bool, keys []interface{}) (map[interface{}]interface{}, error)
+
 	// CachePut puts a value with a given key to cache (overwriting existing value if any).
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_put
 	CachePut(cache string, binary bool, key interface{}, value interface{}) error
 
+	// CachePutContext is equal to CachePut but uses ctx to cancel the operation or limit its duration.
+	CachePutContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) error
+
 	CachePutWithTTL(cache string, key interface{}, value interface{}, ttl time.Duration) error
 
+	// CachePutWithTTLContext is equal to CachePutWithTTL but uses ctx to cancel the operation or limit its duration.
+	CachePutWithTTLContext(ctx context.Context, cache string, key interface{}, value interface{}, ttl time.Duration) error
+
 	// CachePutAll puts a value with a given key to cache (overwriting existing value if any).
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_put_all
 	CachePutAll(cache string, binary bool, data map[interface{}]interface{}) error
 
+	// CachePutAllContext is equal to CachePutAll but uses ctx to cancel the operation or limit its duration.
+	CachePutAllContext(ctx context.Context, cache string, binary bool, data map[interface{}]interface{}) error
+
 	CachePutAllWithTTL(cache string, data map[interface{}]interface{}, ttl time.Duration) error
 
+	// CachePutAllWithTTLContext is equal to CachePutAllWithTTL but uses ctx to cancel the operation or limit its duration.
+	CachePutAllWithTTLContext(ctx context.Context, cache string, data map[interface{}]interface{}, ttl time.Duration) error
+
 	// CacheContainsKey returns a value indicating whether given key is present in cache.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_contains_key
 	CacheContainsKey(cache string, binary bool, key interface{}) (bool, error)
 
+	// CacheContainsKeyContext is equal to CacheContainsKey but uses ctx to cancel the operation or limit its duration.
+	CacheContainsKeyContext(ctx context.Context, cache string, binary bool, key interface{}) (bool, error)
+
 	// CacheContainsKeys returns a value indicating whether all given keys are present in cache.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_contains_keys
 	CacheContainsKeys(cache string, binary bool, keys []interface{}) (bool, error)
 
+	// CacheContainsKeysContext is equal to CacheContainsKeys but uses ctx to cancel the operation or limit its duration.
+	CacheContainsKeysContext(ctx context.Context, cache string, binary bool, keys []interface{}) (bool, error)
+
 	// CacheGetAndPut puts a value with a given key to cache, and returns the previous value for that key.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_get_and_put
 	CacheGetAndPut(cache string, binary bool, key interface{}, value interface{}) (interface{}, error)
 
+	// CacheGetAndPutContext is equal to CacheGetAndPut but uses ctx to cancel the operation or limit its duration.
+	CacheGetAndPutContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (interface{}, error)
+
 	// CacheGetAndReplace puts a value with a given key to cache, returning previous value for that key,
 	// if and only if there is a value currently mapped for that key.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_get_and_replace
 	CacheGetAndReplace(cache string, binary bool, key interface{}, value interface{}) (interface{}, error)
 
+	// CacheGetAndReplaceContext is equal to CacheGetAndReplace but uses ctx to cancel the operation or limit its duration.
+	CacheGetAndReplaceContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (interface{}, error)
+
 	// CacheGetAndRemove removes the cache entry with specified key, returning the value.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_get_and_remove
 	CacheGetAndRemove(cache string, binary bool, key interface{}) (interface{}, error)
 
+	// CacheGetAndRemoveContext is equal to CacheGetAndRemove but uses ctx to cancel the operation or limit its duration.
+	CacheGetAndRemoveContext(ctx context.Context, cache string, binary bool, key interface{}) (interface{}, error)
+
 	// CachePutIfAbsent puts a value with a given key to cache only if the key does not already exist.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_put_if_absent
 	CachePutIfAbsent(cache string, binary bool, key interface{}, value interface{}) (bool, error)
 
+	// CachePutIfAbsentContext is equal to CachePutIfAbsent but uses ctx to cancel the operation or limit its duration.
+	CachePutIfAbsentContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (bool, error)
+
 	// CacheGetAndPutIfAbsent puts a value with a given key to cache only if the key does not already exist.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_get_and_put_if_absent
 	CacheGetAndPutIfAbsent(cache string, binary bool, key interface{}, value interface{}) (interface{}, error)
 
+	// CacheGetAndPutIfAbsentContext is equal to CacheGetAndPutIfAbsent but uses ctx to cancel the operation or limit its duration.
+	CacheGetAndPutIfAbsentContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (interface{}, error)
+
 	// CacheReplace puts a value with a given key to cache only if the key already exists.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_replace
 	CacheReplace(cache string, binary bool, key interface{}, value interface{}) (bool, error)
 
+	// CacheReplaceContext is equal to CacheReplace but uses ctx to cancel the operation or limit its duration.
+	CacheReplaceContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (bool, error)
+
 	// CacheReplaceIfEquals puts a value with a given key to cache only if
 	// the key already exists and value equals provided value.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_replace_if_equals
 	CacheReplaceIfEquals(cache string, binary bool, key interface{}, valueCompare interface{}, valueNew interface{}) (bool, error)
 
+	// CacheReplaceIfEqualsContext is equal to CacheReplaceIfEquals but uses ctx to cancel the operation or limit its duration.
+	CacheReplaceIfEqualsContext(ctx context.Context, cache string, binary bool, key interface{}, valueCompare interface{}, valueNew interface{}) (bool, error)
+
 	// CacheClear clears the cache without notifying listeners or cache writers.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_clear
 	CacheClear(cache string, binary bool) error
 
+	// CacheClearContext is equal to CacheClear but uses ctx to cancel the operation or limit its duration.
+	CacheClearContext(ctx context.Context, cache string, binary bool) error
+
 	// CacheClearKey clears the cache key without notifying listeners or cache writers.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_clear_key
 	CacheClearKey(cache string, binary bool, key interface{}) error
 
+	// CacheClearKeyContext is equal to CacheClearKey but uses ctx to cancel the operation or limit its duration.
+	CacheClearKeyContext(ctx context.Context, cache string, binary bool, key interface{}) error
+
 	// CacheClearKeys clears the cache keys without notifying listeners or cache writers.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_clear_keys
 	CacheClearKeys(cache string, binary bool, keys []interface{}) error
 
+	// CacheClearKeysContext is equal to CacheClearKeys but uses ctx to cancel the operation or limit its duration.
+	CacheClearKeysContext(ctx context.Context, cache string, binary bool, keys []interface{}) error
+
 	// CacheRemoveKey removes an entry with a given key, notifying listeners and cache writers.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_remove_key
 	CacheRemoveKey(cache string, binary bool, key interface{}) (bool, error)
 
+	// CacheRemoveKeyContext is equal to CacheRemoveKey but uses ctx to cancel the operation or limit its duration.
+	CacheRemoveKeyContext(ctx context.Context, cache string, binary bool, key interface{}) (bool, error)
+
 	// CacheRemoveIfEquals removes an entry with a given key if provided value is equal to actual value,
 	// notifying listeners and cache writers.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_remove_if_equals
 	CacheRemoveIfEquals(cache string, binary bool, key interface{}, value interface{}) (bool, error)
 
+	// CacheRemoveIfEqualsContext is equal to CacheRemoveIfEquals but uses ctx to cancel the operation or limit its duration.
+	CacheRemoveIfEqualsContext(ctx context.Context, cache string, binary bool, key interface{}, value interface{}) (bool, error)
+
 	// CacheGetSize gets the number of entries in cache.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_get_size
 	CacheGetSize(cache string, binary bool, modes []byte) (int64, error)
 
+	// CacheGetSizeContext is equal to CacheGetSize but uses ctx to cancel the operation or limit its duration.
+	CacheGetSizeContext(ctx context.Context, cache string, binary bool, modes []byte) (int64, error)
+
 	// CacheRemoveKeys removes entries with given keys, notifying listeners and cache writers.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_remove_keys
 	CacheRemoveKeys(cache string, binary bool, keys []interface{}) error
 
+	// CacheRemoveKeysContext is equal to CacheRemoveKeys but uses ctx to cancel the operation or limit its duration.
+	CacheRemoveKeysContext(ctx context.Context, cache string, binary bool, keys []interface{}) error
+
 	// CacheRemoveAll destroys cache with a given name.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-key-value-operations#section-op_cache_remove_all
 	CacheRemoveAll(cache string, binary bool) error
 
+	// CacheRemoveAllContext is equal to CacheRemoveAll but uses ctx to cancel the operation or limit its duration.
+	CacheRemoveAllContext(ctx context.Context, cache string, binary bool) error
+
 	// SQL and Scan Queries
 	// See for details:
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations
@@ -178,119 +382,492 @@ type Client interface {
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations#section-op_query_sql
 	QuerySQL(cache string, binary bool, data QuerySQLData) (QuerySQLResult, error)
 
+	// QuerySQLContext is equal to QuerySQL but uses ctx to cancel the operation or limit its duration.
+	QuerySQLContext(ctx context.Context, cache string, binary bool, data QuerySQLData) (QuerySQLResult, error)
+
 	// QuerySQLCursorGetPage retrieves the next SQL query cursor page by cursor id from QuerySQL.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations#section-op_query_sql_cursor_get_page
 	QuerySQLCursorGetPage(id int64) (QuerySQLPage, error)
 
+	// QuerySQLCursorGetPageContext is equal to QuerySQLCursorGetPage but uses ctx to cancel the operation or limit its duration.
+	QuerySQLCursorGetPageContext(ctx context.Context, id int64) (QuerySQLPage, error)
+
 	// QuerySQLFieldsRaw is equal to QuerySQLFields but return raw Response object.
 	// Used for SQL driver to reduce memory allocations.
 	QuerySQLFieldsRaw(cache string, binary bool, data QuerySQLFieldsData) (*ResponseOperation, error)
 
+	// QuerySQLFieldsRawContext is equal to QuerySQLFieldsRaw but uses ctx to cancel the operation or limit its duration.
+	QuerySQLFieldsRawContext(ctx context.Context, cache string, binary bool, data QuerySQLFieldsData) (*ResponseOperation, error)
+
 	// QuerySQLFields performs SQL fields query.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations#section-op_query_sql_fields
 	QuerySQLFields(cache string, binary bool, data QuerySQLFieldsData) (QuerySQLFieldsResult, error)
 
+	// QuerySQLFieldsContext is equal to QuerySQLFields but uses ctx to cancel the operation or limit its duration.
+	QuerySQLFieldsContext(ctx context.Context, cache string, binary bool, data QuerySQLFieldsData) (QuerySQLFieldsResult, error)
+
 	// QuerySQLFieldsCursorGetPageRaw is equal to QuerySQLFieldsCursorGetPage but return raw Response object.
 	// Used for SQL driver to reduce memory allocations.
 	QuerySQLFieldsCursorGetPageRaw(id int64) (*ResponseOperation, error)
 
+	// QuerySQLFieldsCursorGetPageRawContext is equal to QuerySQLFieldsCursorGetPageRaw but uses ctx to cancel the operation or limit its duration.
+	QuerySQLFieldsCursorGetPageRawContext(ctx context.Context, id int64) (*ResponseOperation, error)
+
 	// QuerySQLFieldsCursorGetPage retrieves the next query result page by cursor id from QuerySQLFields.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations#section-op_query_sql_fields_cursor_get_page
 	QuerySQLFieldsCursorGetPage(id int64, fieldCount int) (QuerySQLFieldsPage, error)
 
+	// QuerySQLFieldsCursorGetPageContext is equal to QuerySQLFieldsCursorGetPage but uses ctx to cancel the operation or limit its duration.
+	QuerySQLFieldsCursorGetPageContext(ctx context.Context, id int64, fieldCount int) (QuerySQLFieldsPage, error)
+
 	// QueryScan performs scan query.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations#section-op_query_scan
 	QueryScan(cache string, binary bool, data QueryScanData) (QueryScanResult, error)
 
+	// QueryScanContext is equal to QueryScan but uses ctx to cancel the operation or limit its duration.
+	QueryScanContext(ctx context.Context, cache string, binary bool, data QueryScanData) (QueryScanResult, error)
+
 	// QueryScanCursorGetPage fetches the next SQL query cursor page by cursor id that is obtained from OP_QUERY_SCAN.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations#section-op_query_scan_cursor_get_page
 	QueryScanCursorGetPage(id int64) (QueryScanPage, error)
 
+	// QueryScanCursorGetPageContext is equal to QueryScanCursorGetPage but uses ctx to cancel the operation or limit its duration.
+	QueryScanCursorGetPageContext(ctx context.Context, id int64) (QueryScanPage, error)
+
+	// QueryScanEach is equal to QueryScan but calls fn for every row of the first page in order the rows are received
+	// instead of collecting them to QueryScanResult.Rows. The page is decoded straight from the connection
+	// without buffering of the whole response, fn is called after the page is received, so it may use the client.
+	QueryScanEach(cache string, binary bool, data QueryScanData, fn func(key, value interface{}) error) (QueryScanResult, error)
+
+	// QueryScanEachContext is equal to QueryScanEach but uses ctx to cancel the operation or limit its duration.
+	QueryScanEachContext(ctx context.Context, cache string, binary bool, data QueryScanData,
+		fn func(key, value interface{}) error) (QueryScanResult, error)
+
+	// QueryScanCursorGetPageEach is equal to QueryScanCursorGetPage but calls fn for every row of the page
+	// instead of collecting them to QueryScanPage.Rows. The page is decoded straight from the connection,
+	// fn is called after the page is received, so it may use the client.
+	QueryScanCursorGetPageEach(id int64, fn func(key, value interface{}) error) (QueryScanPage, error)
+
+	// QueryScanCursorGetPageEachContext is equal to QueryScanCursorGetPageEach but uses ctx to cancel the operation or limit its duration.
+	QueryScanCursorGetPageEachContext(ctx context.Context, id int64, fn func(key, value interface{}) error) (QueryScanPage, error)
+
 	// ResourceClose closes a resource, such as query cursor.
 	// https://apacheignite.readme.io/docs/binary-client-protocol-sql-operations#section-op_resource_close
 	ResourceClose(id int64) error
+
+	// ResourceCloseContext is equal to ResourceClose but uses ctx to cancel the operation or limit its duration.
+	ResourceCloseContext(ctx context.Context, id int64) error
+
+	// Binary Types
+	// See for details:
+	// https://apacheignite.readme.io/docs/binary-client-protocol-binary-type-operations
+
+	// GetBinaryTypeName gets the platform-specific full binary type name by ID.
+	// platformID is PlatformJava or PlatformDotNet.
+	// https://apacheignite.readme.io/docs/binary-client-protocol-binary-type-operations#section-op_get_binary_type_name
+	GetBinaryTypeName(platformID byte, typeID int32) (string, error)
+
+	// GetBinaryTypeNameContext is equal to GetBinaryTypeName but uses ctx to cancel the operation or limit its duration.
+	GetBinaryTypeNameContext(ctx context.Context, platformID byte, typeID int32) (string, error)
+
+	// RegisterBinaryTypeName registers the platform-specific full binary type name for the specified type ID.
+	// https://apacheignite.readme.io/docs/binary-client-protocol-binary-type-operations#section-op_register_binary_type_name
+	RegisterBinaryTypeName(platformID byte, typeID int32, typeName string) error
+
+	// RegisterBinaryTypeNameContext is equal to RegisterBinaryTypeName but uses ctx to cancel the operation or limit its duration.
+	RegisterBinaryTypeNameContext(ctx context.Context, platformID byte, typeID int32, typeName string) error
+
+	// GetBinaryType gets the binary type information by ID.
+	// Returns nil if the type is not registered.
+	// https://apacheignite.readme.io/docs/binary-client-protocol-binary-type-operations#section-op_get_binary_type
+	GetBinaryType(typeID int32) (*BinaryType, error)
+
+	// GetBinaryTypeContext is equal to GetBinaryType but uses ctx to cancel the operation or limit its duration.
+	GetBinaryTypeContext(ctx context.Context, typeID int32) (*BinaryType, error)
+
+	// PutBinaryType registers the binary type information in the cluster.
+	// https://apacheignite.readme.io/docs/binary-client-protocol-binary-type-operations#section-op_put_binary_type
+	PutBinaryType(t BinaryType) error
+
+	// PutBinaryTypeContext is equal to PutBinaryType but uses ctx to cancel the operation or limit its duration.
+	PutBinaryTypeContext(ctx context.Context, t BinaryType) error
+
+	// ResolveBinaryType gets the binary type information by ID from the metadata cache of the client.
+	// The cache is filled from the cluster on the first request of the type.
+	// Returns error if the type is not registered.
+	ResolveBinaryType(typeID int32) (*BinaryType, error)
+
+	// ResolveBinaryTypeContext is equal to ResolveBinaryType but uses ctx to cancel the operation or limit its duration.
+	ResolveBinaryTypeContext(ctx context.Context, typeID int32) (*BinaryType, error)
+
+	// Transactions
+	// See for details:
+	// https://apacheignite.readme.io/docs/binary-client-protocol-transactions
+
+	// TxStart starts the transaction (protocol version 1.5.0 and above).
+	// Key-Value operations and SQL queries of the returned Tx are executed in the transaction.
+	TxStart(concurrency TxConcurrency, isolation TxIsolation, timeout time.Duration, label string) (Tx, error)
+
+	// TxStartContext is equal to TxStart but uses ctx to cancel the operation or limit its duration.
+	TxStartContext(ctx context.Context, concurrency TxConcurrency, isolation TxIsolation,
+		timeout time.Duration, label string) (Tx, error)
+}
+
+// transport sends operation request messages and receives response messages
+type transport interface {
+	// do sends request message and waits for response message
+	do(ctx context.Context, message []byte) ([]byte, error)
+	// alive returns true if transport is not closed or broken
+	alive() bool
+	// state returns state of the transport
+	state() ConnectionState
+	// endpoint returns endpoint of the last connected cluster node
+	endpoint() Endpoint
+	// stream sends request message and calls fn with reader of the response message
+	stream(ctx context.Context, message []byte, fn func(r io.Reader) error) error
+	// session returns parameters negotiated with the last connected cluster node
+	session() session
+	// close closes transport
+	close() error
 }
 
 type client struct {
 	debugID string
-	conn    net.Conn
-	mutex   *sync.Mutex
+	conn    transport
+	// count of retries of idempotent operation failed because of broken connection
+	retries int
+
+	mutex  sync.Mutex
+	closed bool
+	// operations in progress
+	inflight sync.WaitGroup
+
+	// owner is the client which started the transaction, it is nil if the client is not bound to the transaction
+	owner *client
+	// txID is ID of the transaction Key-Value operations are executed in if owner is not nil
+	txID int32
+
+	// types is the binary type metadata cache
+	types binaryTypeCache
+	// schemas are schemas registered in the cluster, objects of them are written with compact footers
+	// if compactFooter is true
+	schemas       registeredSchemas
+	compactFooter bool
 
 	Client
 }
 
 // IsConnected return true if connection to the cluster is active
 func (c *client) Connected() bool {
-	return c.conn != nil
+	return c.State() == StateReady
 }
 
-// Do sends request and receives response
-func (c *client) Do(req Request, res Response) error {
+// State returns state of the connection to the cluster
+func (c *client) State() ConnectionState {
+	if c.owner != nil {
+		return c.owner.State()
+	}
+	c.mutex.Lock()
+	closed := c.closed
+	c.mutex.Unlock()
+	if closed {
+		return StateClosed
+	}
+	return c.conn.state()
+}
+
+// begin registers operation in progress.
+// Returns ErrClientClosed if the client is closed.
+func (c *client) begin() error {
+	if c.owner != nil {
+		return c.owner.begin()
+	}
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	c.inflight.Add(1)
+	return nil
+}
 
-	// send request
-	if _, err := req.WriteTo(c.conn); err != nil {
-		return errors.Wrapf(err, "failed to send request to server")
+// end unregisters operation in progress
+func (c *client) end() {
+	if c.owner != nil {
+		c.owner.end()
+		return
 	}
+	c.inflight.Done()
+}
+
+// ActiveEndpoint returns endpoint of the cluster node the client is connected to
+func (c *client) ActiveEndpoint() Endpoint {
+	return c.conn.endpoint()
+}
+
+// ProtocolVersion returns binary protocol version negotiated with the cluster node
+func (c *client) ProtocolVersion() ProtocolVersion {
+	return c.conn.session().version
+}
 
-	// receive response
-	_, err := res.ReadFrom(c.conn)
+// NodeID returns ID of the cluster node the client is connected to
+func (c *client) NodeID() uuid.UUID {
+	return c.conn.session().nodeID
+}
+
+// Features returns optional protocol features supported by both the client and the cluster node
+func (c *client) Features() Features {
+	return c.conn.session().features
+}
+
+// HasFeature returns true if optional protocol feature f is supported by both the client and the cluster node
+func (c *client) HasFeature(f Feature) bool {
+	return c.conn.session().features.Has(f)
+}
+
+// Do sends request and receives response
+func (c *client) Do(req Request, res Response) error {
+	return c.DoContext(context.Background(), req, res)
+}
+
+// DoContext sends request and receives response.
+// Requests of concurrent calls are pipelined over the connection.
+// If ctx is done before response is received the call returns immediately with ctx error,
+// request is not sent if it is still in queue, and its response is skipped.
+// Idempotent operation failed because of broken connection is retried over the connection to the next endpoint.
+func (c *client) DoContext(ctx context.Context, req Request, res Response) error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+	defer c.end()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// prepare request message
+	msg := getBuffer()
+	if _, err := req.WriteTo(msg); err != nil {
+		putBuffer(msg)
+		return errors.Wrapf(err, "failed to prepare request")
+	}
+
+	retries := 0
+	if isIdempotent(msg.Bytes()) {
+		retries = c.retries
+	}
+	var frame []byte
+	var err error
+	for attempt := 0; ; attempt++ {
+		frame, err = c.conn.do(ctx, msg.Bytes())
+		var cerr *connectionError
+		if err == nil || attempt >= retries || ctx.Err() != nil || !stderrors.As(err, &cerr) {
+			break
+		}
+	}
+	if err != nil {
+		// message may still be written by the connection if the call is interrupted
+		return err
+	}
+	putBuffer(msg)
+
+	d := getDecoder(frame)
+	_, err = res.ReadFrom(d)
+	putDecoder(d)
+	// unknown schemas of the objects with compact footers are got from the cluster
+	// when the response is read, the connection is not used by the operation already
+	if r, ok := res.(interface{ setResolver(schemaResolver) }); ok {
+		r.setResolver(c)
+	}
 
 	return err
 }
 
+// streamContext sends request and calls fn when the response is ready to be read from res.
+// The response is decoded from the connection directly without buffering of the whole message.
+// Other responses are not received until fn returns, so fn must only decode the response and not use the client.
+func (c *client) streamContext(ctx context.Context, req Request, res Response, fn func() error) error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+	defer c.end()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// prepare request message
+	msg := getBuffer()
+	if _, err := req.WriteTo(msg); err != nil {
+		putBuffer(msg)
+		return errors.Wrapf(err, "failed to prepare request")
+	}
+
+	retries := 0
+	if isIdempotent(msg.Bytes()) {
+		retries = c.retries
+	}
+	for attempt := 0; ; attempt++ {
+		// request is not retried if the response is received
+		received := false
+		err := c.conn.stream(ctx, msg.Bytes(), func(r io.Reader) error {
+			received = true
+			if _, err := res.ReadFrom(&frameReader{r}); err != nil {
+				return err
+			}
+			return fn()
+		})
+		if received {
+			// message is written if the response is received
+			putBuffer(msg)
+		}
+		var cerr *connectionError
+		if err == nil || received || attempt >= retries || ctx.Err() != nil || !stderrors.As(err, &cerr) {
+			return err
+		}
+	}
+}
+
 // Close closes connection.
 // Returns:
 // nil in case of success.
 // error object in case of error.
 func (c *client) Close() error {
-	if c.Connected() {
-		defer func() { c.conn = nil }()
-		return c.conn.Close()
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
 	}
-	return nil
+	c.closed = true
+	c.mutex.Unlock()
+
+	err := c.conn.close()
+	c.inflight.Wait()
+	return err
 }
 
 // Connect connects to the Apache Ignite cluster
 // Returns: client
 func Connect(ci ConnInfo) (Client, error) {
-	address := fmt.Sprintf("%s:%d", ci.Host, ci.Port)
+	return ConnectContext(context.Background(), ci)
+}
+
+// ConnectContext connects to the Apache Ignite cluster.
+// ctx is used to cancel or limit duration of dialing and handshake.
+// Returns: client
+func ConnectContext(ctx context.Context, ci ConnInfo) (Client, error) {
+	var conn transport
+	var err error
+	if ci.PartitionAwareness {
+		conn, err = newPartitioned(ctx, ci)
+	} else {
+		conn, err = newFailover(ctx, ci)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{conn: conn, debugID: ci.debugID(), retries: len(ci.endpoints()), compactFooter: ci.CompactFooter}
+	runtime.SetFinalizer(c, clientFinalizer)
+
+	// return connected client
+	return c, nil
+}
+
+// dial opens connection to the first available endpoint starting from the endpoint with index first.
+// Returns the connection and index of its endpoint.
+func dial(ctx context.Context, ci ConnInfo, first int) (*connection, int, error) {
+	endpoints := ci.endpoints()
+	var err error
+	for i := 0; i < len(endpoints); i++ {
+		index := (first + i) % len(endpoints)
+		var conn *connection
+		if conn, err = dialEndpoint(ctx, ci, endpoints[index]); err == nil {
+			return conn, index, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(endpoints) > 1 {
+		err = errors.Wrapf(err, "failed to connect to any of %d endpoints", len(endpoints))
+	}
+	return nil, 0, err
+}
+
+// dialEndpoint opens connection to the cluster node and makes handshake.
+// If the server does not support requested protocol version
+// the handshake is repeated with the highest version supported by both sides.
+func dialEndpoint(ctx context.Context, ci ConnInfo, e Endpoint) (*connection, error) {
+	version := ci.version()
+	for {
+		conn, res, err := handshake(ctx, ci, e, version)
+		if err != nil {
+			return nil, err
+		}
+		if res.Success {
+			return newConnection(conn, session{version: version, nodeID: res.NodeID,
+				features: clientFeatures.intersect(res.Features)}, &ci), nil
+		}
+		conn.Close()
+
+		server := ProtocolVersion{Major: res.Major, Minor: res.Minor, Patch: res.Patch}
+		next, ok := negotiate(version, server)
+		if !ok && server == version {
+			return nil, errors.Errorf("handshake with %s failed: %s", e, res.Message)
+		}
+		if !ok {
+			return nil, errors.Errorf("handshake with %s failed: %s, server supported protocol version is v%s",
+				e, res.Message, server)
+		}
+		version = next
+	}
+}
+
+// handshake opens connection to the cluster node and makes handshake with protocol version
+func handshake(ctx context.Context, ci ConnInfo, e Endpoint, version ProtocolVersion) (net.Conn, *ResponseHandshake, error) {
+	address := e.String()
 
 	// connect
 	var conn net.Conn
 	var err error
 	if ci.TLSConfig != nil {
-		conn, err = tls.DialWithDialer(&ci.Dialer, ci.Network, address, ci.TLSConfig)
+		d := &tls.Dialer{NetDialer: &ci.Dialer, Config: ci.TLSConfig}
+		conn, err = d.DialContext(ctx, ci.Network, address)
 	} else {
-		conn, err = ci.Dialer.Dial(ci.Network, address)
+		conn, err = ci.Dialer.DialContext(ctx, ci.Network, address)
 	}
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to open connection")
+		return nil, nil, errors.Wrapf(err, "failed to open connection to %s", address)
 	}
 
-	c := &client{conn: conn, debugID: strings.Join([]string{"network=", ci.Network, "', address='", address, "'"}, ""),
-		mutex: &sync.Mutex{}}
-	runtime.SetFinalizer(c, clientFinalizer)
-
 	// request and response
-	req := NewRequestHandshake(ci.Major, ci.Minor, ci.Patch, ci.Username, ci.Password)
-	res := &ResponseHandshake{}
+	req := NewRequestHandshakeAttributes(version.Major, version.Minor, version.Patch, ci.Username, ci.Password,
+		ci.UserAttributes, clientFeatures)
+	res := NewResponseHandshake(version.Major, version.Minor, version.Patch)
 
 	// make handshake
-	if err = c.Do(req, res); err != nil {
-		c.Close()
-		return nil, errors.Wrapf(err, "failed to make handshake")
+	if err = exchange(ctx, conn, req, res); err != nil {
+		conn.Close()
+		return nil, nil, errors.Wrapf(err, "failed to make handshake with %s", address)
 	}
 
-	if !res.Success {
-		c.Close()
-		return nil, errors.Errorf("handshake failed: %s, server supported protocol version is v%d.%d.%d",
-			res.Message, res.Major, res.Minor, res.Patch)
+	return conn, res, nil
+}
+
+// endpoints returns list of the cluster nodes to connect to
+func (ci *ConnInfo) endpoints() []Endpoint {
+	if len(ci.Endpoints) > 0 {
+		return ci.Endpoints
 	}
+	return []Endpoint{{Host: ci.Host, Port: ci.Port}}
+}
 
-	// return connected client
-	return c, nil
+// debugID returns connection description for logging
+func (ci *ConnInfo) debugID() string {
+	var addresses []string
+	for _, e := range ci.endpoints() {
+		addresses = append(addresses, e.String())
+	}
+	return strings.Join([]string{"network='", ci.Network, "', address='", strings.Join(addresses, ","), "'"}, "")
 }
 
 // clientFinalizer is resource leak spy
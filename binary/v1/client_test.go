@@ -1,8 +1,15 @@
 package ignite
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/binary"
+	stderrors "errors"
+	"io"
+	"net"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestConnect(t *testing.T) {
@@ -66,3 +73,242 @@ func TestConnect(t *testing.T) {
 		})
 	}
 }
+
+// serveOperation reads one operation request from the connection.
+// Returns function to send successful response for it.
+func serveOperation(t *testing.T, conn net.Conn) func() {
+	var l int32
+	if err := binary.Read(conn, binary.LittleEndian, &l); err != nil {
+		t.Errorf("failed to read request length: %v", err)
+		return func() {}
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		t.Errorf("failed to read request: %v", err)
+		return func() {}
+	}
+	uid := b[2:10]
+	return func() {
+		res := append([]byte{12, 0, 0, 0}, uid...)
+		res = append(res, 0, 0, 0, 0)
+		if _, err := conn.Write(res); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}
+}
+
+func Test_client_DoContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name: "deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "cancel",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, server := net.Pipe()
+			defer server.Close()
+			c := &client{conn: startFailover(ConnInfo{}, newConnection(conn, session{version: ProtocolVersion{Major: 1, Minor: 1}}, &ConnInfo{}), 0)}
+			defer c.Close()
+
+			release := make(chan struct{})
+			go func() {
+				// response to the first request is delayed
+				respond := serveOperation(t, server)
+				<-release
+				respond()
+				serveOperation(t, server)()
+			}()
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			req := NewRequestOperation(OpCacheGetNames)
+			err := c.DoContext(ctx, req, NewResponseOperation(req.UID))
+			if !stderrors.Is(err, tt.wantErr) {
+				t.Fatalf("client.DoContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			close(release)
+
+			// connection must be usable after interrupted request
+			req = NewRequestOperation(OpCacheGetNames)
+			res := NewResponseOperation(req.UID)
+			if err = c.DoContext(context.Background(), req, res); err != nil {
+				t.Fatalf("client.DoContext() error = %v", err)
+			}
+			if err = res.CheckStatus(); err != nil {
+				t.Errorf("client.DoContext() status error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_client_DoContext_Pipelining(t *testing.T) {
+	conn, server := net.Pipe()
+	defer server.Close()
+	c := &client{conn: startFailover(ConnInfo{}, newConnection(conn, session{version: ProtocolVersion{Major: 1, Minor: 1}}, &ConnInfo{}), 0)}
+	defer c.Close()
+
+	const count = 10
+	go func() {
+		// all requests are received before the first response is sent,
+		// responses are sent in reverse order
+		responses := make([]func(), 0, count)
+		for i := 0; i < count; i++ {
+			responses = append(responses, serveOperation(t, server))
+		}
+		for i := len(responses) - 1; i >= 0; i-- {
+			responses[i]()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := NewRequestOperation(OpCacheGetNames)
+			res := NewResponseOperation(req.UID)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := c.DoContext(ctx, req, res); err != nil {
+				t.Errorf("client.DoContext() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_client_Close(t *testing.T) {
+	conn, server := net.Pipe()
+	defer server.Close()
+	c := &client{conn: startFailover(ConnInfo{}, newConnection(conn, session{version: ProtocolVersion{Major: 1, Minor: 1}}, &ConnInfo{}), 0)}
+
+	go serveOperation(t, server)
+
+	errs := make(chan error, 1)
+	go func() {
+		req := NewRequestOperation(OpCacheGetNames)
+		errs <- c.Do(req, NewResponseOperation(req.UID))
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("client.Close() error = %v", err)
+	}
+	// operation in progress is finished when Close returns
+	select {
+	case err := <-errs:
+		if !stderrors.Is(err, ErrClientClosed) {
+			t.Errorf("client.Do() error = %v, want %v", err, ErrClientClosed)
+		}
+	default:
+		t.Errorf("client.Do() is not finished when client.Close() returns")
+	}
+	if c.Connected() {
+		t.Errorf("client.Connected() = true for closed connection")
+	}
+	if got := c.State(); got != StateClosed {
+		t.Errorf("client.State() = %v, want %v", got, StateClosed)
+	}
+	req := NewRequestOperation(OpCacheGetNames)
+	if err := c.Do(req, NewResponseOperation(req.UID)); !stderrors.Is(err, ErrClientClosed) {
+		t.Errorf("client.Do() error = %v, want %v", err, ErrClientClosed)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("second client.Close() error = %v", err)
+	}
+}
+
+func Test_client_CloseDialing(t *testing.T) {
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+	silent, accepted := newSilentListener(t)
+	defer silent.Close()
+
+	tests := []struct {
+		name string
+		// connect returns client which dials the silent node for the next operation
+		connect func() (Client, error)
+	}{
+		{
+			name: "failover is reconnecting",
+			connect: func() (Client, error) {
+				ci := s.connInfo()
+				ci.Endpoints = []Endpoint{s.endpoint(), endpointOf(silent)}
+				c, err := Connect(ci)
+				if err == nil {
+					s.drop()
+				}
+				return c, err
+			},
+		},
+		{
+			name: "pool is opening connection",
+			connect: func() (Client, error) {
+				ci := s.connInfo()
+				ci.Endpoints = []Endpoint{endpointOf(silent)}
+				return ConnectPool(PoolConfig{ConnInfo: ci})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.connect()
+			if err != nil {
+				t.Fatalf("connect error = %v", err)
+			}
+			errs := make(chan error, 1)
+			go func() {
+				_, err := c.CacheGetNames()
+				errs <- err
+			}()
+			// dial is pending and the operation is blocked
+			select {
+			case conn := <-accepted:
+				defer conn.Close()
+			case <-time.After(5 * time.Second):
+				t.Fatalf("silent node is not dialed in time")
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			closed := make(chan error, 1)
+			go func() {
+				closed <- c.Close()
+			}()
+			select {
+			case err = <-closed:
+				if err != nil {
+					t.Errorf("client.Close() error = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("client.Close() is not finished in time")
+			}
+			select {
+			case err = <-errs:
+				if !stderrors.Is(err, ErrClientClosed) {
+					t.Errorf("client.CacheGetNames() error = %v, want %v", err, ErrClientClosed)
+				}
+			default:
+				t.Errorf("client.CacheGetNames() is not finished when client.Close() returns")
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package ignite
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// BinaryMarshaler is implemented by types which write themselves as complex object.
+// WriteObject uses WriteBinary instead of reflection, methods are generated by cmd/ignite-gen.
+type BinaryMarshaler interface {
+	// WriteBinary writes the value as complex object including type code
+	WriteBinary(w io.Writer) error
+}
+
+// BinaryUnmarshaler is implemented by types which read themselves from complex object.
+// ReadObjectInto uses ReadBinary instead of reflection, methods are generated by cmd/ignite-gen.
+type BinaryUnmarshaler interface {
+	// ReadBinary reads complex object including type code, NULL resets the value
+	ReadBinary(r io.Reader) error
+}
+
+// ComplexObjectWriter writes complex object field by field.
+// It is used by WriteOComplexObject and by the generated WriteBinary methods.
+type ComplexObjectWriter struct {
+	typeID int32
+	fields *bytes.Buffer
+	ids    []int32
+	// offsets are offsets of the fields from the object start
+	offsets []int32
+	// schema is cached schema the fields are written in order of, it is nil if the schema is not known
+	schema *schema
+	// rawOffset is offset of the raw data from the object start, 0 if object has no raw data
+	rawOffset int32
+	// registry is registry of the writer the object is written to, it is nil if it is not known yet
+	registry schemaRegistry
+}
+
+// NewComplexObjectWriter returns writer of complex object of the type typeID
+func NewComplexObjectWriter(typeID int32) *ComplexObjectWriter {
+	return &ComplexObjectWriter{typeID: typeID, fields: getBuffer()}
+}
+
+// Field starts the field with ID fieldID, the field value must be written to the returned writer
+// before the next field is started
+func (c *ComplexObjectWriter) Field(fieldID int32) io.Writer {
+	c.ids = append(c.ids, fieldID)
+	c.offsets = append(c.offsets, int32(ComplexObjectHeaderLength+c.fields.Len()))
+	return fieldWriter{c: c}
+}
+
+// Raw starts the raw data section of the object, raw data must be written to the returned writer.
+// Raw data is written after all fields, fields must not be started after that.
+func (c *ComplexObjectWriter) Raw() io.Writer {
+	if c.rawOffset == 0 {
+		c.rawOffset = int32(ComplexObjectHeaderLength + c.fields.Len())
+	}
+	return fieldWriter{c: c}
+}
+
+// fieldWriter writes fields and raw data to the buffer of the complex object writer.
+// Nested objects written to it are written with compact footers if the parent object is.
+type fieldWriter struct {
+	c *ComplexObjectWriter
+}
+
+// Write appends p to the fields of the object
+func (f fieldWriter) Write(p []byte) (int, error) {
+	return f.c.fields.Write(p)
+}
+
+// buffer returns buffer of the fields of the object
+func (f fieldWriter) buffer() *bytes.Buffer {
+	return f.c.fields
+}
+
+// isRegistered returns true if the schema is registered in the cluster the parent object is written to
+func (f fieldWriter) isRegistered(typeID int32, schemaID int32) bool {
+	return f.c.registry != nil && f.c.registry.isRegistered(typeID, schemaID)
+}
+
+// WriteObject writes the complex object to w.
+// Compact footer is written if w is the request of the client with compact footers enabled
+// and the schema is registered in its cluster.
+// The writer must not be used after that.
+func (c *ComplexObjectWriter) WriteObject(w io.Writer) error {
+	defer func() {
+		putBuffer(c.fields)
+		c.fields = nil
+	}()
+
+	var id int32
+	if c.schema != nil {
+		id = c.schema.id
+	} else {
+		id = schemaID(c.ids)
+	}
+
+	// fields and raw data end at schema offset
+	schemaOffset := ComplexObjectHeaderLength + c.fields.Len()
+	flags := int16(ComplexObjectUserType)
+	// footer contains field IDs (omitted in compact footer) and offsets of the smallest width
+	idSize, offsetSize := 4, 4
+	if len(c.ids) > 0 {
+		flags |= ComplexObjectHasSchema
+		if c.registry == nil {
+			c.registry, _ = w.(schemaRegistry)
+		}
+		if c.registry != nil && c.registry.isRegistered(c.typeID, id) {
+			flags |= ComplexObjectCompactFooter
+			idSize = 0
+		}
+		switch last := c.offsets[len(c.offsets)-1]; {
+		case last < 1<<8:
+			flags |= ComplexObjectOffsetOneByte
+			offsetSize = 1
+		case last < 1<<16:
+			flags |= ComplexObjectOffsetTwoBytes
+			offsetSize = 2
+		}
+	}
+	length := ComplexObjectHeaderLength + c.fields.Len() + (idSize+offsetSize)*len(c.ids)
+	if c.rawOffset != 0 {
+		flags |= ComplexObjectHasRaw
+		if len(c.ids) > 0 {
+			// raw data offset follows the footer
+			length += 4
+		} else {
+			// object without fields has no footer, raw data offset is written instead of schema offset
+			schemaOffset = int(c.rawOffset)
+		}
+	} else if len(c.ids) == 0 {
+		// object without fields has no footer
+		schemaOffset = 0
+	}
+
+	// header
+	if err := WriteType(w, typeComplexObject); err != nil {
+		return err
+	}
+	if err := WriteByte(w, ComplexObjectVersion); err != nil {
+		return err
+	}
+	if err := WriteShort(w, flags); err != nil {
+		return err
+	}
+	if err := WriteInt(w, c.typeID); err != nil {
+		return err
+	}
+	// hash code, Java-style hash of contents without header, necessary for comparisons
+	if err := WriteInt(w, HashCodeForSlice(c.fields.Bytes())); err != nil {
+		return err
+	}
+	// length, including header
+	if err := WriteInt(w, int32(length)); err != nil {
+		return err
+	}
+	if err := WriteInt(w, id); err != nil {
+		return err
+	}
+	// schema offset from the header start, position where fields and raw data end
+	if err := WriteInt(w, int32(schemaOffset)); err != nil {
+		return err
+	}
+
+	// fields and footer
+	if err := WriteBytes(w, c.fields.Bytes()); err != nil {
+		return err
+	}
+	for i, id := range c.ids {
+		if idSize != 0 {
+			if err := WriteInt(w, id); err != nil {
+				return errors.Wrapf(err, "failed to write field ID with hash %d", id)
+			}
+		}
+		var err error
+		switch offsetSize {
+		case 1:
+			err = WriteByte(w, byte(c.offsets[i]))
+		case 2:
+			err = WriteShort(w, int16(c.offsets[i]))
+		default:
+			err = WriteInt(w, c.offsets[i])
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to write field offset with hash %d", id)
+		}
+	}
+	if c.rawOffset != 0 && len(c.ids) > 0 {
+		if err := WriteInt(w, c.rawOffset); err != nil {
+			return errors.Wrapf(err, "failed to write raw data offset")
+		}
+	}
+	return nil
+}
+
+// schemaID calculates ID of the schema with fields fieldIDs, FNV1 hash of the field IDs
+func schemaID(fieldIDs []int32) int32 {
+	if len(fieldIDs) == 0 {
+		return 0
+	}
+	id := uint32(0x811C9DC5)
+	for _, f := range fieldIDs {
+		fieldID := uint32(f)
+		id = id ^ (fieldID & 0xFF)
+		id = id * uint32(0x01000193)
+		id = id ^ ((fieldID >> 8) & 0xFF)
+		id = id * uint32(0x01000193)
+		id = id ^ ((fieldID >> 16) & 0xFF)
+		id = id * uint32(0x01000193)
+		id = id ^ ((fieldID >> 24) & 0xFF)
+		id = id * uint32(0x01000193)
+	}
+	return int32(id)
+}
+
+// ReadOComplexObjectFields reads "complex object" value including type code and passes data of every field to fn.
+// Raw data of the object is skipped. It is used by the generated ReadBinary methods.
+// Returns type ID of the object, false is returned if the value is NULL.
+func ReadOComplexObjectFields(r io.Reader, fn func(fieldID int32, field io.Reader) error) (int32, bool, error) {
+	t, err := ReadByte(r)
+	if err != nil {
+		return 0, false, err
+	}
+	switch t {
+	case typeNULL:
+		return 0, false, nil
+	case typeComplexObject:
+		typeID, _, err := readComplexObject(r, fn)
+		return typeID, err == nil, err
+	default:
+		return 0, false, errors.Errorf("invalid type code %d, but expected %d", t, typeComplexObject)
+	}
+}
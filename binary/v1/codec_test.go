@@ -0,0 +1,219 @@
+package ignite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComplexObjectWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []int32
+		want   []byte
+	}{
+		{
+			name: "no fields",
+			want: []byte{
+				103, 1, 1, 0, // type code, version, flags
+				1, 0, 0, 0, // type ID
+				1, 0, 0, 0, // hash code
+				24, 0, 0, 0, // length
+				0, 0, 0, 0, // schema ID
+				0, 0, 0, 0, // schema offset
+			},
+		},
+		{
+			name:   "fields",
+			fields: []int32{10, 20},
+			want: []byte{
+				103, 1, 11, 0, // type code, version, flags
+				1, 0, 0, 0, // type ID
+				240, 124, 7, 168, // hash code
+				44, 0, 0, 0, // length
+				91, 59, 58, 254, // schema ID, FNV1 hash of field IDs
+				34, 0, 0, 0, // schema offset
+				3, 10, 0, 0, 0, // field 10
+				9, 0, 0, 0, 0, // field 20
+				10, 0, 0, 0, 24, // footer with one byte offsets
+				20, 0, 0, 0, 29,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComplexObjectWriter(1)
+			for _, f := range tt.fields {
+				switch f {
+				case 10:
+					_ = WriteOInt(c.Field(f), 10)
+				default:
+					_ = WriteOString(c.Field(f), "")
+				}
+			}
+			w := &bytes.Buffer{}
+			if err := c.WriteObject(w); err != nil {
+				t.Fatalf("ComplexObjectWriter.WriteObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(w.Bytes(), tt.want) {
+				t.Errorf("ComplexObjectWriter.WriteObject() = %v, want %v", w.Bytes(), tt.want)
+			}
+
+			fields := map[int32]interface{}{}
+			typeID, ok, err := ReadOComplexObjectFields(w, func(fieldID int32, field io.Reader) error {
+				o, err := ReadObject(field)
+				fields[fieldID] = o
+				return err
+			})
+			if err != nil || !ok || typeID != 1 {
+				t.Fatalf("ReadOComplexObjectFields() = %v, %v, %v", typeID, ok, err)
+			}
+			if len(fields) != len(tt.fields) {
+				t.Errorf("ReadOComplexObjectFields() read %d fields, want %d", len(fields), len(tt.fields))
+			}
+		})
+	}
+}
+
+func TestComplexObjectWriter_Footer(t *testing.T) {
+	// every field is string of the length, the last field offset sets the offset width
+	tests := []struct {
+		name       string
+		lengths    []int
+		registered bool
+		// other is true if the schema is registered by another client
+		other     bool
+		compact   bool
+		wantFlags int16
+		wantSize  int
+	}{
+		{
+			name:      "one byte offsets",
+			lengths:   []int{1, 200},
+			wantFlags: ComplexObjectOffsetOneByte,
+			wantSize:  2 * (4 + 1),
+		},
+		{
+			name:      "two bytes offsets",
+			lengths:   []int{300, 1},
+			wantFlags: ComplexObjectOffsetTwoBytes,
+			wantSize:  2 * (4 + 2),
+		},
+		{
+			name:      "two bytes offsets above int16",
+			lengths:   []int{40000, 1},
+			wantFlags: ComplexObjectOffsetTwoBytes,
+			wantSize:  2 * (4 + 2),
+		},
+		{
+			name:     "four bytes offsets",
+			lengths:  []int{70000, 1},
+			wantSize: 2 * (4 + 4),
+		},
+		{
+			name:       "compact footers are disabled",
+			lengths:    []int{1, 2},
+			registered: true,
+			wantFlags:  ComplexObjectOffsetOneByte,
+			wantSize:   2 * (4 + 1),
+		},
+		{
+			name:      "compact footer of not registered schema",
+			lengths:   []int{1, 2},
+			compact:   true,
+			wantFlags: ComplexObjectOffsetOneByte,
+			wantSize:  2 * (4 + 1),
+		},
+		{
+			name:       "compact footer",
+			lengths:    []int{300, 1},
+			registered: true,
+			compact:    true,
+			wantFlags:  ComplexObjectCompactFooter | ComplexObjectOffsetTwoBytes,
+			wantSize:   2 * 2,
+		},
+		{
+			name:      "compact footer of schema registered by another client",
+			lengths:   []int{1, 2},
+			other:     true,
+			compact:   true,
+			wantFlags: ComplexObjectOffsetOneByte,
+			wantSize:  2 * (4 + 1),
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeID := HashCode("TestComplexObjectWriter_Footer") + int32(i)
+			ids := []int32{HashCode("a"), HashCode("b")}
+			// schema is received from the cluster by the client of the request or by another client
+			req, other := NewRequestOperation(OpCachePut), NewRequestOperation(OpCachePut)
+			set := &registeredSchemas{}
+			other.schemas = &registeredSchemas{}
+			if tt.compact {
+				req.schemas = set
+			}
+			if tt.registered || tt.other {
+				schemas.put(typeID, ids, true, true)
+			}
+			if tt.registered {
+				set.add(typeID, ids)
+			}
+			if tt.other {
+				other.schemas.add(typeID, ids)
+			}
+
+			o := ComplexObject{Type: typeID, Fields: map[int32]interface{}{}}
+			c := NewComplexObjectWriter(typeID)
+			for j, l := range tt.lengths {
+				v := strings.Repeat("x", l)
+				o.Fields[ids[j]] = v
+				_ = WriteOString(c.Field(ids[j]), v)
+			}
+			if err := c.WriteObject(req); err != nil {
+				t.Fatalf("ComplexObjectWriter.WriteObject() error = %v", err)
+			}
+			b := req.payload.Bytes()
+			flags := int16(binary.LittleEndian.Uint16(b[2:]))
+			want := int16(ComplexObjectUserType|ComplexObjectHasSchema) | tt.wantFlags
+			if flags != want {
+				t.Errorf("flags = %#x, want %#x", flags, want)
+			}
+			length := int(binary.LittleEndian.Uint32(b[12:]))
+			schemaOffset := int(binary.LittleEndian.Uint32(b[20:]))
+			if got := length - schemaOffset; got != tt.wantSize {
+				t.Errorf("footer size = %d, want %d", got, tt.wantSize)
+			}
+			if length != len(b) {
+				t.Errorf("length = %d, but object is written as %d bytes", length, len(b))
+			}
+
+			got, err := ReadObject(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("ReadObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, o) {
+				t.Errorf("ReadObject() = %v, want %v", got, o)
+			}
+		})
+	}
+}
+
+func TestReadComplexObject_UnknownCompactSchema(t *testing.T) {
+	typeID := HashCode("TestReadComplexObject_UnknownCompactSchema")
+	c := NewComplexObjectWriter(typeID)
+	_ = WriteOInt(c.Field(HashCode("a")), 1)
+	w := &bytes.Buffer{}
+	_ = c.WriteObject(w)
+	b := w.Bytes()
+	// compact footer without field ID
+	b[2] |= ComplexObjectCompactFooter
+	b = append(b[:len(b)-5], b[len(b)-1])
+	binary.LittleEndian.PutUint32(b[12:], uint32(len(b)))
+
+	if _, err := ReadObject(bytes.NewReader(b)); err == nil {
+		t.Errorf("ReadObject() error = nil for unknown schema of compact footer")
+	}
+}
@@ -0,0 +1,221 @@
+package ignite
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// CollectionKind is kind of the Java collection
+type CollectionKind int8
+
+const (
+	// CollectionUserSet is user set (java.util.Set implementation)
+	CollectionUserSet CollectionKind = -1
+	// CollectionUserCollection is user collection (java.util.Collection implementation)
+	CollectionUserCollection CollectionKind = 0
+	// CollectionArrayList is java.util.ArrayList
+	CollectionArrayList CollectionKind = 1
+	// CollectionLinkedList is java.util.LinkedList
+	CollectionLinkedList CollectionKind = 2
+	// CollectionHashSet is java.util.HashSet
+	CollectionHashSet CollectionKind = 3
+	// CollectionLinkedHashSet is java.util.LinkedHashSet
+	CollectionLinkedHashSet CollectionKind = 4
+	// CollectionSingletonList is java.util.Collections$SingletonList
+	CollectionSingletonList CollectionKind = 5
+)
+
+// MapKind is kind of the Java map
+type MapKind int8
+
+const (
+	// MapUserMap is user map (java.util.Map implementation)
+	MapUserMap MapKind = 0
+	// MapHashMap is java.util.HashMap
+	MapHashMap MapKind = mapKindHashMap
+	// MapLinkedHashMap is java.util.LinkedHashMap
+	MapLinkedHashMap MapKind = 2
+)
+
+// Collection is "collection" type, Items are elements of the Java collection of the kind Kind
+type Collection struct {
+	Kind  CollectionKind
+	Items []interface{}
+}
+
+// NewCollection returns collection of the kind with the items
+func NewCollection(kind CollectionKind, items ...interface{}) Collection {
+	return Collection{Kind: kind, Items: items}
+}
+
+// MapEntry is key-value pair of the map
+type MapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Map is "map" type, Entries are key-value pairs of the Java map of the kind Kind in order they are read.
+// Entries are kept as slice because keys read from the cluster (complex objects for example)
+// can't be keys of the Go map.
+type Map struct {
+	Kind    MapKind
+	Entries []MapEntry
+}
+
+// Get returns value of the key
+func (m *Map) Get(key interface{}) (interface{}, bool) {
+	for _, e := range m.Entries {
+		if reflect.DeepEqual(e.Key, key) {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// ToMap converts entries to Go map, it fails if any key can't be the key of the Go map
+func (m *Map) ToMap() (map[interface{}]interface{}, error) {
+	r := make(map[interface{}]interface{}, len(m.Entries))
+	for _, e := range m.Entries {
+		if e.Key != nil && !reflect.TypeOf(e.Key).Comparable() {
+			return nil, errors.Errorf("key of type %T can't be the key of the Go map", e.Key)
+		}
+		r[e.Key] = e.Value
+	}
+	return r, nil
+}
+
+// WriteOCollection writes "collection" object value
+func WriteOCollection(w io.Writer, v Collection) error {
+	if err := WriteType(w, typeCollection); err != nil {
+		return err
+	}
+	if err := WriteInt(w, int32(len(v.Items))); err != nil {
+		return err
+	}
+	if err := WriteByte(w, byte(v.Kind)); err != nil {
+		return err
+	}
+	for i, o := range v.Items {
+		if err := WriteObject(w, o); err != nil {
+			return errors.Wrapf(err, "failed to write element with index %d", i)
+		}
+	}
+	return nil
+}
+
+// WriteOMap writes "map" object value
+func WriteOMap(w io.Writer, v Map) error {
+	if err := WriteType(w, typeMap); err != nil {
+		return err
+	}
+	if err := WriteInt(w, int32(len(v.Entries))); err != nil {
+		return err
+	}
+	if err := WriteByte(w, byte(v.Kind)); err != nil {
+		return err
+	}
+	for i, e := range v.Entries {
+		if err := WriteObject(w, e.Key); err != nil {
+			return errors.Wrapf(err, "failed to write key of entry with index %d", i)
+		}
+		if err := WriteObject(w, e.Value); err != nil {
+			return errors.Wrapf(err, "failed to write value of entry with index %d", i)
+		}
+	}
+	return nil
+}
+
+// ReadCollection reads "collection" value
+func ReadCollection(r io.Reader) (Collection, error) {
+	l, err := ReadInt(r)
+	if err != nil {
+		return Collection{}, err
+	}
+	// kind precedes the elements
+	if err = checkLength(r, l, 1); err != nil {
+		return Collection{}, errors.Wrapf(err, "invalid collection length")
+	}
+	kind, err := ReadByte(r)
+	if err != nil {
+		return Collection{}, err
+	}
+	c := Collection{Kind: CollectionKind(kind), Items: make([]interface{}, 0, capacity(r, int(l)))}
+	for i := 0; i < int(l); i++ {
+		o, err := ReadObject(r)
+		if err != nil {
+			return Collection{}, errors.Wrapf(err, "failed to read element with index %d", i)
+		}
+		c.Items = append(c.Items, o)
+	}
+	return c, nil
+}
+
+// ReadMap reads "map" value
+func ReadMap(r io.Reader) (Map, error) {
+	l, err := ReadInt(r)
+	if err != nil {
+		return Map{}, err
+	}
+	// every entry has key and value
+	if err = checkLength(r, l, 2); err != nil {
+		return Map{}, errors.Wrapf(err, "invalid map length")
+	}
+	kind, err := ReadByte(r)
+	if err != nil {
+		return Map{}, err
+	}
+	m := Map{Kind: MapKind(kind), Entries: make([]MapEntry, 0, capacity(r, int(l)))}
+	for i := 0; i < int(l); i++ {
+		var e MapEntry
+		if e.Key, err = ReadObject(r); err != nil {
+			return Map{}, errors.Wrapf(err, "failed to read key of entry with index %d", i)
+		}
+		if e.Value, err = ReadObject(r); err != nil {
+			return Map{}, errors.Wrapf(err, "failed to read value of entry with index %d", i)
+		}
+		m.Entries = append(m.Entries, e)
+	}
+	return m, nil
+}
+
+// marshalMap converts the Go map to the hash map supported by WriteObject
+func marshalMap(v reflect.Value, typ string) (Map, error) {
+	m := Map{Kind: MapHashMap, Entries: make([]MapEntry, 0, v.Len())}
+	it := v.MapRange()
+	for it.Next() {
+		k, err := marshalValue(it.Key(), "")
+		if err != nil {
+			return Map{}, errors.Wrapf(err, "failed to marshal key %v", it.Key())
+		}
+		e, err := marshalValue(it.Value(), typ)
+		if err != nil {
+			return Map{}, errors.Wrapf(err, "failed to marshal value of key %v", it.Key())
+		}
+		m.Entries = append(m.Entries, MapEntry{Key: k, Value: e})
+	}
+	return m, nil
+}
+
+// setMap stores entries of the map m in the Go map v
+func setMap(v reflect.Value, m Map) error {
+	t := v.Type()
+	r := reflect.MakeMapWithSize(t, len(m.Entries))
+	for i, e := range m.Entries {
+		k := reflect.New(t.Key()).Elem()
+		if err := setValue(k, reflect.ValueOf(e.Key)); err != nil {
+			return errors.Wrapf(err, "failed to set key of entry with index %d", i)
+		}
+		if k.Kind() == reflect.Interface && !k.IsNil() && !k.Elem().Type().Comparable() {
+			return errors.Errorf("key of type %T can't be the key of the Go map", e.Key)
+		}
+		val := reflect.New(t.Elem()).Elem()
+		if err := setValue(val, reflect.ValueOf(e.Value)); err != nil {
+			return errors.Wrapf(err, "failed to set value of entry with index %d", i)
+		}
+		r.SetMapIndex(k, val)
+	}
+	v.Set(r)
+	return nil
+}
@@ -0,0 +1,135 @@
+package ignite
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCollection(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want []byte
+	}{
+		{
+			name: "array list",
+			v:    NewCollection(CollectionArrayList, int32(1), "a"),
+			want: []byte{24, 2, 0, 0, 0, 1, 3, 1, 0, 0, 0, 9, 1, 0, 0, 0, 97},
+		},
+		{
+			name: "user set",
+			v:    NewCollection(CollectionUserSet, nil),
+			want: []byte{24, 1, 0, 0, 0, 0xFF, 101},
+		},
+		{
+			name: "empty linked list",
+			v:    Collection{Kind: CollectionLinkedList, Items: []interface{}{}},
+			want: []byte{24, 0, 0, 0, 0, 2},
+		},
+		{
+			name: "linked hash map",
+			v: Map{Kind: MapLinkedHashMap, Entries: []MapEntry{
+				{Key: "b", Value: int32(2)},
+				{Key: "a", Value: nil},
+			}},
+			want: []byte{25, 2, 0, 0, 0, 2, 9, 1, 0, 0, 0, 98, 3, 2, 0, 0, 0, 9, 1, 0, 0, 0, 97, 101},
+		},
+		{
+			name: "nested",
+			v: Map{Kind: MapHashMap, Entries: []MapEntry{
+				{Key: int64(1), Value: NewCollection(CollectionHashSet, true)},
+			}},
+			want: []byte{25, 1, 0, 0, 0, 1, 4, 1, 0, 0, 0, 0, 0, 0, 0, 24, 1, 0, 0, 0, 3, 8, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := WriteObject(w, tt.v); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(w.Bytes(), tt.want) {
+				t.Errorf("WriteObject() = %v, want %v", w.Bytes(), tt.want)
+			}
+			got, err := ReadObject(bytes.NewReader(tt.want))
+			if err != nil {
+				t.Fatalf("ReadObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.v) {
+				t.Errorf("ReadObject() = %#v, want %#v", got, tt.v)
+			}
+		})
+	}
+}
+
+func TestWriteObject_GoMap(t *testing.T) {
+	w := &bytes.Buffer{}
+	if err := WriteObject(w, map[string]int16{"a": 1}); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+	want := []byte{25, 1, 0, 0, 0, 1, 9, 1, 0, 0, 0, 97, 2, 1, 0}
+	if !reflect.DeepEqual(w.Bytes(), want) {
+		t.Errorf("WriteObject() = %v, want %v", w.Bytes(), want)
+	}
+
+	var got map[string]int
+	if err := ReadObjectInto(bytes.NewReader(want), &got); err != nil {
+		t.Fatalf("ReadObjectInto() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]int{"a": 1}) {
+		t.Errorf("ReadObjectInto() = %v, want %v", got, map[string]int{"a": 1})
+	}
+}
+
+func TestMap_ToMap(t *testing.T) {
+	m := Map{Kind: MapHashMap, Entries: []MapEntry{{Key: "a", Value: int32(1)}, {Key: int64(2), Value: "b"}}}
+	got, err := m.ToMap()
+	if err != nil {
+		t.Fatalf("Map.ToMap() error = %v", err)
+	}
+	want := map[interface{}]interface{}{"a": int32(1), int64(2): "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map.ToMap() = %v, want %v", got, want)
+	}
+	if v, ok := m.Get(int64(2)); !ok || v != "b" {
+		t.Errorf("Map.Get() = %v, %v, want %v, true", v, ok, "b")
+	}
+
+	m.Entries = append(m.Entries, MapEntry{Key: []byte{1}})
+	if _, err = m.ToMap(); err == nil {
+		t.Errorf("Map.ToMap() error = nil for []byte key")
+	}
+}
+
+func TestMarshal_Collections(t *testing.T) {
+	type Order struct {
+		Tags   map[string]int32 `ignite:"tags"`
+		Items  []interface{}    `ignite:"items"`
+		Values []int64          `ignite:"values"`
+	}
+	v := Order{
+		Tags:  map[string]int32{"x": 1},
+		Items: []interface{}{"a", int32(2)},
+	}
+	w := &bytes.Buffer{}
+	if err := WriteObject(w, v); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+	var got Order
+	if err := ReadObjectInto(bytes.NewReader(w.Bytes()), &got); err != nil {
+		t.Fatalf("ReadObjectInto() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("ReadObjectInto() = %v, want %v", got, v)
+	}
+
+	// collection is read into Go slice
+	c := NewCollection(CollectionArrayList, int64(1), int64(2))
+	if err := UnmarshalObject(c, &got.Values); err != nil {
+		t.Fatalf("UnmarshalObject() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Values, []int64{1, 2}) {
+		t.Errorf("UnmarshalObject() = %v, want %v", got.Values, []int64{1, 2})
+	}
+}
@@ -0,0 +1,547 @@
+package ignite
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+const (
+	// operation request header is message length, operation code and request ID
+	requestHeaderLength = 4 + 2 + 8
+	// operation response header is message length and request ID
+	responseHeaderLength = 4 + 8
+)
+
+const (
+	// responseFlagError means response contains status and error message (protocol version 1.4.0 and above)
+	responseFlagError = 0x01
+	// responseFlagAffinityTopologyChanged means response contains new affinity topology version
+	responseFlagAffinityTopologyChanged = 0x02
+)
+
+const (
+	// callQueued means request is waiting to be sent
+	callQueued = iota
+	// callSent means request is sent or is sending
+	callSent
+	// callAbandoned means caller does not wait for response anymore
+	callAbandoned
+)
+
+// aLongTimeAgo is a non-zero time in the past, used to interrupt blocked connection I/O
+var aLongTimeAgo = time.Unix(1, 0)
+
+// connectionError is error of broken connection.
+// Idempotent operation failed with this error can be retried over another connection.
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectionError) Unwrap() error {
+	return e.err
+}
+
+// call is operation request waiting for the response
+type call struct {
+	uid      int64
+	message  []byte
+	state    int32
+	response []byte
+	err      error
+	done     chan struct{}
+
+	// stream receives reader of the response message if the response is streamed to the caller
+	stream chan io.Reader
+	// consumed is closed when the streamed response is read by the caller
+	consumed chan struct{}
+	// abandoned is closed when the caller does not wait for the streamed response anymore
+	abandoned chan struct{}
+}
+
+// connection is connection to the cluster node with pipelined transport.
+// Requests are sent back to back by the single writer goroutine,
+// responses are read by the single reader goroutine and routed to the waiters by request ID.
+type connection struct {
+	conn  net.Conn
+	queue chan *call
+	session
+
+	mutex   sync.Mutex
+	pending map[int64]*call
+	err     error
+	done    chan struct{}
+	// the latest affinity topology version received from the server
+	topology affinityVersion
+
+	// time of the last sent request in nanoseconds, accessed atomically
+	lastSent int64
+	// count of responses being streamed and count of all streamed responses, accessed atomically
+	streaming int32
+	streamed  int64
+
+	maxFrameSize   int64
+	readBufferSize int
+}
+
+// session contains parameters negotiated in the handshake
+type session struct {
+	version ProtocolVersion
+	// ID of the cluster node (protocol version 1.4.0 and above)
+	nodeID uuid.UUID
+	// features supported by both the client and the cluster node (protocol version 1.7.0 and above)
+	features Features
+}
+
+// affinityVersion is version of the cluster affinity topology
+type affinityVersion struct {
+	major int64
+	minor int32
+}
+
+// less returns true if version v is lower than version o
+func (v affinityVersion) less(o affinityVersion) bool {
+	return v.major < o.major || (v.major == o.major && v.minor < o.minor)
+}
+
+// newConnection starts pipelined transport over the connection after handshake
+func newConnection(conn net.Conn, s session, ci *ConnInfo) *connection {
+	c := &connection{
+		conn:           conn,
+		queue:          make(chan *call),
+		session:        s,
+		pending:        map[int64]*call{},
+		done:           make(chan struct{}),
+		lastSent:       time.Now().UnixNano(),
+		maxFrameSize:   int64(ci.MaxFrameSize),
+		readBufferSize: ci.ReadBufferSize,
+	}
+	if c.maxFrameSize <= 0 {
+		c.maxFrameSize = DefaultMaxFrameSize
+	}
+	if c.readBufferSize <= 0 {
+		c.readBufferSize = DefaultReadBufferSize
+	}
+	go c.writeLoop()
+	go c.readLoop()
+	if ci.HeartbeatInterval > 0 {
+		go c.heartbeat(ci.HeartbeatInterval)
+	}
+	return c
+}
+
+// alive returns true if the connection is not closed or broken
+func (c *connection) alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// do sends operation request message and waits for response message.
+// If ctx is done before response is received the request is abandoned:
+// it is not sent if it is still in queue, and its response is skipped.
+func (c *connection) do(ctx context.Context, message []byte) ([]byte, error) {
+	cl, err := c.send(ctx, message, false)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-cl.done:
+		return cl.response, cl.err
+	case <-ctx.Done():
+		c.abandon(cl)
+		return nil, ctx.Err()
+	}
+}
+
+// stream sends operation request message and calls fn with reader of the response message.
+// The response is decoded by fn directly from the connection through the bounded read buffer,
+// other responses are not received until fn returns. Unread part of the response is skipped.
+// If ctx is done before response is received the request is abandoned.
+func (c *connection) stream(ctx context.Context, message []byte, fn func(r io.Reader) error) error {
+	cl, err := c.send(ctx, message, true)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case r := <-cl.stream:
+		err = fn(&contextReader{ctx: ctx, r: r})
+		close(cl.consumed)
+		return err
+	case <-cl.done:
+		return cl.err
+	case <-ctx.Done():
+		c.abandon(cl)
+		return ctx.Err()
+	}
+}
+
+// send registers the call and puts it to the send queue
+func (c *connection) send(ctx context.Context, message []byte, stream bool) (*call, error) {
+	if len(message) < requestHeaderLength {
+		return nil, errors.Errorf("invalid operation request length %d", len(message))
+	}
+	cl := &call{
+		uid:     int64(binary.LittleEndian.Uint64(message[6:])),
+		message: message,
+		done:    make(chan struct{}),
+	}
+	if stream {
+		cl.stream = make(chan io.Reader)
+		cl.consumed = make(chan struct{})
+		cl.abandoned = make(chan struct{})
+	}
+
+	c.mutex.Lock()
+	if c.err != nil {
+		c.mutex.Unlock()
+		return nil, c.err
+	}
+	if _, ok := c.pending[cl.uid]; ok {
+		c.mutex.Unlock()
+		return nil, errors.Errorf("request with ID %d is already in progress", cl.uid)
+	}
+	c.pending[cl.uid] = cl
+	c.mutex.Unlock()
+
+	select {
+	case c.queue <- cl:
+		return cl, nil
+	case <-cl.done:
+		return nil, cl.err
+	case <-ctx.Done():
+		c.abandon(cl)
+		return nil, ctx.Err()
+	}
+}
+
+// ping sends cheap operation request to check the connection:
+// OP_HEARTBEAT if the server supports it, OP_CACHE_GET_NAMES otherwise.
+// Any response from the server means the connection is alive.
+func (c *connection) ping(ctx context.Context) error {
+	code := int16(OpCacheGetNames)
+	if c.features.Has(FeatureHeartbeat) {
+		code = OpHeartbeat
+	}
+	msg := &bytes.Buffer{}
+	if _, err := NewRequestOperation(code).WriteTo(msg); err != nil {
+		return errors.Wrapf(err, "failed to prepare request")
+	}
+	_, err := c.do(ctx, msg.Bytes())
+	return err
+}
+
+// heartbeat pings the server when no requests are sent during interval.
+// The connection is broken if the server does not reply within interval.
+func (c *connection) heartbeat(interval time.Duration) {
+	t := time.NewTimer(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-c.done:
+			return
+		}
+		idle := time.Since(time.Unix(0, atomic.LoadInt64(&c.lastSent)))
+		if idle < interval {
+			t.Reset(interval - idle)
+			continue
+		}
+		// the connection is busy while the response is streamed
+		if atomic.LoadInt32(&c.streaming) > 0 {
+			t.Reset(interval)
+			continue
+		}
+
+		streamed := atomic.LoadInt64(&c.streamed)
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := c.ping(ctx)
+		cancel()
+		// reply can be delayed by the streamed response
+		if err != nil && atomic.LoadInt32(&c.streaming) == 0 && atomic.LoadInt64(&c.streamed) == streamed {
+			c.shutdown(&connectionError{err: errors.Wrapf(err, "heartbeat is not answered")})
+			return
+		}
+		t.Reset(interval)
+	}
+}
+
+// abandon stops waiting for the response
+func (c *connection) abandon(cl *call) {
+	atomic.CompareAndSwapInt32(&cl.state, callQueued, callAbandoned)
+	c.mutex.Lock()
+	if c.pending[cl.uid] == cl {
+		delete(c.pending, cl.uid)
+	}
+	c.mutex.Unlock()
+	if cl.abandoned != nil {
+		close(cl.abandoned)
+	}
+}
+
+// writeLoop sends queued requests. Buffer is flushed when the queue is empty.
+func (c *connection) writeLoop() {
+	w := bufio.NewWriter(c.conn)
+	for {
+		var cl *call
+		select {
+		case cl = <-c.queue:
+		case <-c.done:
+			return
+		}
+		for cl != nil {
+			if atomic.CompareAndSwapInt32(&cl.state, callQueued, callSent) {
+				if _, err := w.Write(cl.message); err != nil {
+					c.shutdown(&connectionError{err: errors.Wrapf(err, "failed to send request to server")})
+					return
+				}
+			}
+			select {
+			case cl = <-c.queue:
+			default:
+				cl = nil
+			}
+		}
+		if err := w.Flush(); err != nil {
+			c.shutdown(&connectionError{err: errors.Wrapf(err, "failed to send request to server")})
+			return
+		}
+		atomic.StoreInt64(&c.lastSent, time.Now().UnixNano())
+	}
+}
+
+// readLoop receives responses and routes them to the waiters
+func (c *connection) readLoop() {
+	r := bufio.NewReaderSize(c.conn, c.readBufferSize)
+	for {
+		if err := c.receive(r); err != nil {
+			c.shutdown(&connectionError{err: err})
+			return
+		}
+	}
+}
+
+// receive reads response message and routes it to the waiter.
+// Message longer than the maximum frame size is skipped, the waiter gets FrameSizeError.
+func (c *connection) receive(r io.Reader) error {
+	var header [responseHeaderLength]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return errors.Wrapf(err, "failed to receive response from server")
+	}
+	l := int64(int32(binary.LittleEndian.Uint32(header[:])))
+	if l < responseHeaderLength-4 {
+		return &FrameSizeError{Length: l, Max: c.maxFrameSize}
+	}
+	uid := int64(binary.LittleEndian.Uint64(header[4:]))
+
+	c.mutex.Lock()
+	cl, ok := c.pending[uid]
+	if ok {
+		delete(c.pending, uid)
+	}
+	c.mutex.Unlock()
+
+	body := &io.LimitedReader{R: r, N: l - (responseHeaderLength - 4)}
+	if l > c.maxFrameSize {
+		if _, err := io.Copy(ioutil.Discard, body); err != nil {
+			return errors.Wrapf(err, "failed to receive response from server")
+		}
+		if ok {
+			cl.err = &FrameSizeError{Length: l, Max: c.maxFrameSize}
+			close(cl.done)
+		}
+		return nil
+	}
+
+	head, err := c.responseHeader(header[:], body)
+	if err != nil {
+		return err
+	}
+
+	// response for abandoned request is skipped
+	if ok && cl.stream != nil {
+		return c.streamResponse(cl, io.MultiReader(bytes.NewReader(head), body), body)
+	}
+	frame := make([]byte, int64(len(head))+body.N)
+	copy(frame, head)
+	if _, err = io.ReadFull(body, frame[len(head):]); err != nil {
+		return errors.Wrapf(err, "failed to receive response from server")
+	}
+	if ok {
+		cl.response = frame
+		close(cl.done)
+	}
+	return nil
+}
+
+// streamResponse passes reader of the response message to the waiter and waits until it is read.
+// Unread part of the message is skipped.
+func (c *connection) streamResponse(cl *call, message io.Reader, body *io.LimitedReader) error {
+	atomic.AddInt32(&c.streaming, 1)
+	defer func() {
+		atomic.AddInt64(&c.streamed, 1)
+		atomic.AddInt32(&c.streaming, -1)
+	}()
+
+	select {
+	case cl.stream <- message:
+		<-cl.consumed
+	case <-cl.abandoned:
+	}
+	if _, err := io.Copy(ioutil.Discard, body); err != nil {
+		return errors.Wrapf(err, "failed to receive response from server")
+	}
+	return nil
+}
+
+// responseHeader returns header of the response message with status code following the request ID.
+// Flags of the response of protocol version 1.4.0 and above are read from body and converted to the status code,
+// affinity topology version is saved if it is changed.
+func (c *connection) responseHeader(header []byte, body *io.LimitedReader) ([]byte, error) {
+	if c.version.Less(nodeIDVersion) {
+		return header, nil
+	}
+	var b [2 + 8 + 4]byte
+	if _, err := io.ReadFull(body, b[:2]); err != nil {
+		return nil, errors.Wrapf(err, "failed to read response flags")
+	}
+	flags := binary.LittleEndian.Uint16(b[:])
+
+	if flags&responseFlagAffinityTopologyChanged != 0 {
+		if _, err := io.ReadFull(body, b[2:]); err != nil {
+			return nil, errors.Wrapf(err, "failed to read affinity topology version")
+		}
+		v := affinityVersion{
+			major: int64(binary.LittleEndian.Uint64(b[2:])),
+			minor: int32(binary.LittleEndian.Uint32(b[2+8:])),
+		}
+		c.mutex.Lock()
+		if c.topology.less(v) {
+			c.topology = v
+		}
+		c.mutex.Unlock()
+	}
+
+	converted := make([]byte, responseHeaderLength, responseHeaderLength+4)
+	copy(converted[4:], header[4:])
+	if flags&responseFlagError == 0 {
+		converted = append(converted, 0, 0, 0, 0)
+	}
+	binary.LittleEndian.PutUint32(converted, uint32(int64(len(converted)-4)+body.N))
+
+	return converted, nil
+}
+
+// affinityTopology returns the latest affinity topology version received from the server
+func (c *connection) affinityTopology() affinityVersion {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.topology
+}
+
+// shutdown closes the connection and fails all waiting requests with err.
+// Returns error of closing the network connection if the connection was alive.
+func (c *connection) shutdown(err error) error {
+	c.mutex.Lock()
+	if c.err != nil {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.err = err
+	pending := c.pending
+	c.pending = nil
+	close(c.done)
+	c.mutex.Unlock()
+
+	cerr := c.conn.Close()
+	for _, cl := range pending {
+		cl.err = err
+		close(cl.done)
+	}
+	return cerr
+}
+
+// close closes the connection
+func (c *connection) close() error {
+	return c.shutdown(ErrClientClosed)
+}
+
+// exchange writes request and reads response on the connection exclusively.
+// It is used for handshake before pipelined transport is started.
+// Deadline of ctx is used as deadline of the connection I/O.
+func exchange(ctx context.Context, conn net.Conn, req Request, res Response) error {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return errors.Wrapf(err, "failed to set connection deadline")
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	if ctx.Done() != nil {
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			select {
+			case <-ctx.Done():
+				_ = conn.SetDeadline(aLongTimeAgo)
+			case <-done:
+			}
+		}()
+		defer func() {
+			close(done)
+			<-stopped
+		}()
+	}
+
+	if _, err := req.WriteTo(conn); err != nil {
+		return errors.Wrapf(contextError(ctx, err), "failed to send request to server")
+	}
+	if _, err := res.ReadFrom(conn); err != nil {
+		return errors.Wrapf(contextError(ctx, err), "failed to receive response from server")
+	}
+	return nil
+}
+
+// contextError returns error of ctx if err is caused by ctx cancellation or deadline
+func contextError(ctx context.Context, err error) error {
+	if cerr := ctx.Err(); cerr != nil {
+		return cerr
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// contextReader fails reading when ctx is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
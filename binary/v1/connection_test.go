@@ -0,0 +1,206 @@
+package ignite
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestConnect_Heartbeat(t *testing.T) {
+	tests := []struct {
+		name string
+		// node is true if the server supports OP_HEARTBEAT
+		node bool
+		want int16
+	}{
+		{name: "heartbeat operation", node: true, want: OpHeartbeat},
+		{name: "no-op operation", want: OpCacheGetNames},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int32
+			handler := func(conn int, code int16, data []byte) ([]byte, error) {
+				if code == tt.want {
+					atomic.AddInt32(&count, 1)
+				}
+				return []byte{0, 0, 0, 0}, nil
+			}
+			var s *testServer
+			if tt.node {
+				s = newTestNode(t, uuid.New(), handler)
+			} else {
+				s = newTestServer(t, handler)
+			}
+			defer s.close()
+
+			ci := s.connInfo()
+			if tt.node {
+				ci.Major, ci.Minor, ci.Patch = 0, 0, 0
+			}
+			ci.HeartbeatInterval = 10 * time.Millisecond
+			c, err := Connect(ci)
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer c.Close()
+
+			waitFor(t, func() bool { return atomic.LoadInt32(&count) >= 3 })
+			if !c.Connected() {
+				t.Errorf("Client.Connected() = false, want true")
+			}
+		})
+	}
+}
+
+func TestConnect_HeartbeatMissed(t *testing.T) {
+	block := make(chan struct{})
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		if conn == 0 {
+			// the first connection does not reply
+			<-block
+			return nil, errTestDrop
+		}
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+	defer close(block)
+
+	ci := s.connInfo()
+	ci.HeartbeatInterval = 10 * time.Millisecond
+	ci.Reconnect = ReconnectConfig{InitialInterval: 10 * time.Millisecond}
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	// connection is broken by the missed heartbeat and restored in background
+	waitFor(t, func() bool { return s.accepted() == 2 && c.Connected() })
+	if _, err = c.CacheGetNames(); err != nil {
+		t.Errorf("Client.CacheGetNames() error = %v", err)
+	}
+}
+
+func TestConnect_MaxFrameSize(t *testing.T) {
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		if code == OpCacheGet {
+			// string value longer than the maximum frame size
+			v := &bytes.Buffer{}
+			_ = WriteOString(v, strings.Repeat("a", 1024))
+			return v.Bytes(), nil
+		}
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+
+	ci := s.connInfo()
+	ci.MaxFrameSize = 512
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	var ferr *FrameSizeError
+	if _, err = c.CacheGet("cache", false, 1); !stderrors.As(err, &ferr) {
+		t.Fatalf("Client.CacheGet() error = %v, want FrameSizeError", err)
+	}
+	// the connection is still usable
+	if _, err = c.CacheGetNames(); err != nil {
+		t.Errorf("Client.CacheGetNames() error = %v", err)
+	}
+	if got := s.accepted(); got != 1 {
+		t.Errorf("server accepted %d connections, want 1", got)
+	}
+}
+
+func TestClient_QueryScanEach(t *testing.T) {
+	// page with rows 0 -> "value 0", 1 -> "value 1", ... and has more flag
+	page := &bytes.Buffer{}
+	_ = WriteLong(page, 42)
+	_ = WriteInt(page, 100)
+	for i := 0; i < 100; i++ {
+		_ = WriteOInt(page, int32(i))
+		_ = WriteOString(page, "value "+strconv.Itoa(i))
+	}
+	_ = WriteBool(page, true)
+	handler := func(conn int, code int16, data []byte) ([]byte, error) {
+		if code == OpQueryScan {
+			return page.Bytes(), nil
+		}
+		return []byte{0, 0, 0, 0}, nil
+	}
+
+	tests := []struct {
+		name string
+		// node is true if the server uses protocol version 1.4.0
+		node bool
+		// stop is count of rows after which fn returns error
+		stop int
+		// use is true if fn sends requests over the client
+		use     bool
+		wantErr bool
+	}{
+		{name: "all rows"},
+		{name: "all rows with flags", node: true},
+		{name: "stop reading", stop: 10, wantErr: true},
+		{name: "client is used by callback", use: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s *testServer
+			if tt.node {
+				s = newTestNode(t, uuid.New(), handler)
+			} else {
+				s = newTestServer(t, handler)
+			}
+			defer s.close()
+
+			ci := s.connInfo()
+			ci.ReadBufferSize = 16
+			c, err := Connect(ci)
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer c.Close()
+
+			count := 0
+			r, err := c.QueryScanEach("cache", false, QueryScanData{PageSize: 100}, func(key, value interface{}) error {
+				if want := "value " + strconv.Itoa(int(key.(int32))); value != want {
+					t.Errorf("value = %v, want %v", value, want)
+				}
+				if tt.use {
+					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+					defer cancel()
+					if _, err := c.CacheGetNamesContext(ctx); err != nil {
+						return err
+					}
+				}
+				count++
+				if count == tt.stop {
+					return stderrors.New("stop")
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.QueryScanEach() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if count != 100 || r.ID != 42 || !r.HasMore || r.Rows != nil {
+					t.Errorf("Client.QueryScanEach() = %+v with %d rows, want cursor 42 with 100 rows", r, count)
+				}
+			}
+			// unread part of the response is skipped
+			if _, err = c.CacheGetNames(); err != nil {
+				t.Errorf("Client.CacheGetNames() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package ignite
+
+import (
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// Decimal is "decimal" type (java.math.BigDecimal), the value is Unscaled * 10^(-Scale).
+// Nil Unscaled is zero.
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int32
+}
+
+// NewDecimal returns decimal unscaled * 10^(-scale)
+func NewDecimal(unscaled *big.Int, scale int32) Decimal {
+	return Decimal{Unscaled: new(big.Int).Set(unscaled), Scale: scale}
+}
+
+// ParseDecimal parses decimal in the plain ("-123.45") or the exponent ("1.2345e-3") notation.
+// Scale of the result is the count of the fraction digits minus the exponent, so "1.50" has scale 2.
+func ParseDecimal(s string) (Decimal, error) {
+	v := s
+	var exp int64
+	if i := strings.IndexAny(v, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(v[i+1:], 10, 32); err != nil {
+			return Decimal{}, errors.Wrapf(err, "invalid decimal exponent in %q", s)
+		}
+		v = v[:i]
+	}
+	digits := v
+	var scale int64
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		digits = v[:i] + v[i+1:]
+		scale = int64(len(v) - i - 1)
+	}
+	// sign only is not a number
+	if strings.TrimLeft(digits, "+-") == "" || strings.ContainsAny(digits[1:], "+-") {
+		return Decimal{}, errors.Errorf("invalid decimal %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, errors.Errorf("invalid decimal %q", s)
+	}
+	scale -= exp
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return Decimal{}, errors.Errorf("decimal scale of %q is out of range", s)
+	}
+	return Decimal{Unscaled: unscaled, Scale: int32(scale)}, nil
+}
+
+// DecimalFromFloat returns decimal with the shortest representation of f which rounds to f
+func DecimalFromFloat(f *big.Float) (Decimal, error) {
+	if f.IsInf() {
+		return Decimal{}, errors.Errorf("infinity can't be converted to decimal")
+	}
+	return ParseDecimal(f.Text('f', -1))
+}
+
+// unscaled returns unscaled value, nil is zero
+func (d Decimal) unscaled() *big.Int {
+	if d.Unscaled == nil {
+		return new(big.Int)
+	}
+	return d.Unscaled
+}
+
+// Rat returns exact value of the decimal
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.unscaled())
+	if d.Scale == 0 {
+		return r
+	}
+	scale := int64(d.Scale)
+	if scale < 0 {
+		scale = -scale
+	}
+	p := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(scale), nil))
+	if d.Scale > 0 {
+		return r.Quo(r, p)
+	}
+	return r.Mul(r, p)
+}
+
+// Float returns value of the decimal as big.Float with precision enough to hold the unscaled value
+func (d Decimal) Float() *big.Float {
+	return new(big.Float).SetRat(d.Rat())
+}
+
+// String returns the decimal in the plain notation without exponent, like BigDecimal.toPlainString
+func (d Decimal) String() string {
+	u := d.unscaled()
+	s := new(big.Int).Abs(u).String()
+	switch {
+	case d.Scale < 0:
+		s += strings.Repeat("0", -int(d.Scale))
+	case d.Scale > 0:
+		if n := int(d.Scale) - len(s) + 1; n > 0 {
+			s = strings.Repeat("0", n) + s
+		}
+		s = s[:len(s)-int(d.Scale)] + "." + s[len(s)-int(d.Scale):]
+	}
+	if u.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// Cmp compares decimals by value regardless of the scale, it returns -1, 0 or +1
+func (d Decimal) Cmp(v Decimal) int {
+	return d.Rat().Cmp(v.Rat())
+}
+
+// Scan implements sql.Scanner, so decimal columns of the query results can be scanned into Decimal
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case Decimal:
+		*d = v
+	case *Decimal:
+		*d = *v
+	case string:
+		p, err := ParseDecimal(v)
+		if err != nil {
+			return err
+		}
+		*d = p
+	case []byte:
+		return d.Scan(string(v))
+	case int64:
+		*d = Decimal{Unscaled: big.NewInt(v)}
+	case int32:
+		*d = Decimal{Unscaled: big.NewInt(int64(v))}
+	case float64:
+		p, err := ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+		*d = p
+	default:
+		return errors.Errorf("failed to scan value of type %T to decimal", src)
+	}
+	return nil
+}
+
+// WriteODecimal writes "decimal" object value.
+// The unscaled value is written as big-endian magnitude with the sign in the highest bit.
+func WriteODecimal(w io.Writer, v Decimal) error {
+	if err := WriteType(w, typeDecimal); err != nil {
+		return err
+	}
+	if err := WriteInt(w, v.Scale); err != nil {
+		return err
+	}
+	u := v.unscaled()
+	b := new(big.Int).Abs(u).Bytes()
+	if len(b) == 0 || b[0]&0x80 != 0 {
+		// the highest bit is reserved for the sign
+		b = append([]byte{0}, b...)
+	}
+	if u.Sign() < 0 {
+		b[0] |= 0x80
+	}
+	if err := WriteInt(w, int32(len(b))); err != nil {
+		return err
+	}
+	return WriteBytes(w, b)
+}
+
+// WriteOArrayODecimals writes "decimal" array object value, nil elements are written as NULL
+func WriteOArrayODecimals(w io.Writer, v []*Decimal) error {
+	if err := WriteType(w, typeDecimalArray); err != nil {
+		return err
+	}
+	if err := WriteInt(w, int32(len(v))); err != nil {
+		return err
+	}
+	for i, d := range v {
+		var err error
+		if d == nil {
+			err = WriteNull(w)
+		} else {
+			err = WriteODecimal(w, *d)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to write element with index %d", i)
+		}
+	}
+	return nil
+}
+
+// ReadDecimal reads "decimal" value
+func ReadDecimal(r io.Reader) (Decimal, error) {
+	scale, err := ReadInt(r)
+	if err != nil {
+		return Decimal{}, err
+	}
+	b, err := ReadArrayBytes(r)
+	if err != nil {
+		return Decimal{}, err
+	}
+	u := new(big.Int)
+	if len(b) > 0 {
+		neg := b[0]&0x80 != 0
+		b[0] &= 0x7F
+		u.SetBytes(b)
+		if neg {
+			u.Neg(u)
+		}
+	}
+	return Decimal{Unscaled: u, Scale: scale}, nil
+}
+
+// ReadArrayODecimals reads "decimal" array value, NULL elements are read as nil
+func ReadArrayODecimals(r io.Reader) ([]*Decimal, error) {
+	l, err := readLength(r, 1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid decimal array length")
+	}
+	b := make([]*Decimal, 0, capacity(r, l))
+	for i := 0; i < l; i++ {
+		t, err := ReadByte(r)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case typeNULL:
+			b = append(b, nil)
+		case typeDecimal:
+			d, err := ReadDecimal(r)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read element with index %d", i)
+			}
+			b = append(b, &d)
+		default:
+			return nil, errors.Errorf("invalid type of element with index %d (expected %d, but got %d)", i, typeDecimal, t)
+		}
+	}
+	return b, nil
+}
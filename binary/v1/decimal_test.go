@@ -0,0 +1,258 @@
+package ignite
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		want       Decimal
+		wantString string
+		wantErr    bool
+	}{
+		{
+			name:       "integer",
+			s:          "123",
+			want:       Decimal{Unscaled: big.NewInt(123)},
+			wantString: "123",
+		},
+		{
+			name:       "fraction",
+			s:          "-123.450",
+			want:       Decimal{Unscaled: big.NewInt(-123450), Scale: 3},
+			wantString: "-123.450",
+		},
+		{
+			name:       "less than one",
+			s:          "0.0012",
+			want:       Decimal{Unscaled: big.NewInt(12), Scale: 4},
+			wantString: "0.0012",
+		},
+		{
+			name:       "exponent",
+			s:          "1.5E+3",
+			want:       Decimal{Unscaled: big.NewInt(15), Scale: -2},
+			wantString: "1500",
+		},
+		{
+			name:       "negative exponent",
+			s:          "25e-3",
+			want:       Decimal{Unscaled: big.NewInt(25), Scale: 3},
+			wantString: "0.025",
+		},
+		{
+			name:    "sign only",
+			s:       "-",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			s:       "1.2.3",
+			wantErr: true,
+		},
+		{
+			name:    "invalid exponent",
+			s:       "1e",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDecimal(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDecimal() = %#v, want %#v", got, tt.want)
+			}
+			if s := got.String(); s != tt.wantString {
+				t.Errorf("Decimal.String() = %s, want %s", s, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestDecimal_Float(t *testing.T) {
+	d, _ := ParseDecimal("-12.375")
+	if f, _ := d.Float().Float64(); f != -12.375 {
+		t.Errorf("Decimal.Float() = %v, want %v", f, -12.375)
+	}
+	if r := d.Rat(); r.Cmp(big.NewRat(-12375, 1000)) != 0 {
+		t.Errorf("Decimal.Rat() = %v, want %v", r, "-12375/1000")
+	}
+
+	got, err := DecimalFromFloat(big.NewFloat(0.125))
+	if err != nil {
+		t.Fatalf("DecimalFromFloat() error = %v", err)
+	}
+	if got.String() != "0.125" {
+		t.Errorf("DecimalFromFloat() = %s, want %s", got, "0.125")
+	}
+
+	a, _ := ParseDecimal("1.50")
+	b, _ := ParseDecimal("1.5")
+	if a.Cmp(b) != 0 {
+		t.Errorf("Decimal.Cmp(%s, %s) = %d, want 0", a, b, a.Cmp(b))
+	}
+}
+
+func TestDecimal_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "decimal",
+			src:  Decimal{Unscaled: big.NewInt(105), Scale: 1},
+			want: "10.5",
+		},
+		{
+			name: "string",
+			src:  "-0.01",
+			want: "-0.01",
+		},
+		{
+			name: "int64",
+			src:  int64(42),
+			want: "42",
+		},
+		{
+			name: "float64",
+			src:  float64(2.5),
+			want: "2.5",
+		},
+		{
+			name:    "unsupported",
+			src:     true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Decimal
+			if err := d.Scan(tt.src); (err != nil) != tt.wantErr {
+				t.Fatalf("Decimal.Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && d.String() != tt.want {
+				t.Errorf("Decimal.Scan() = %s, want %s", d, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteODecimal(t *testing.T) {
+	// values are written the same way as java.math.BigDecimal
+	tests := []struct {
+		name string
+		v    string
+		want []byte
+	}{
+		{
+			name: "positive",
+			v:    "123.45",
+			want: []byte{30, 2, 0, 0, 0, 2, 0, 0, 0, 0x30, 0x39},
+		},
+		{
+			name: "negative",
+			v:    "-123.45",
+			want: []byte{30, 2, 0, 0, 0, 2, 0, 0, 0, 0xB0, 0x39},
+		},
+		{
+			name: "highest bit",
+			v:    "128",
+			want: []byte{30, 0, 0, 0, 0, 2, 0, 0, 0, 0x00, 0x80},
+		},
+		{
+			name: "negative highest bit",
+			v:    "-128",
+			want: []byte{30, 0, 0, 0, 0, 2, 0, 0, 0, 0x80, 0x80},
+		},
+		{
+			name: "zero",
+			v:    "0.00",
+			want: []byte{30, 2, 0, 0, 0, 1, 0, 0, 0, 0},
+		},
+		{
+			name: "negative scale",
+			v:    "1E+3",
+			want: []byte{30, 0xFD, 0xFF, 0xFF, 0xFF, 1, 0, 0, 0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDecimal(tt.v)
+			if err != nil {
+				t.Fatalf("ParseDecimal() error = %v", err)
+			}
+			w := &bytes.Buffer{}
+			if err := WriteObject(w, d); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(w.Bytes(), tt.want) {
+				t.Errorf("WriteObject() = %v, want %v", w.Bytes(), tt.want)
+			}
+			o, err := ReadObject(bytes.NewReader(tt.want))
+			if err != nil {
+				t.Fatalf("ReadObject() error = %v", err)
+			}
+			if got, ok := o.(Decimal); !ok || got.Scale != d.Scale || got.Unscaled.Cmp(d.Unscaled) != 0 {
+				t.Errorf("ReadObject() = %#v, want %#v", o, d)
+			}
+		})
+	}
+}
+
+func TestWriteOArrayODecimals(t *testing.T) {
+	d, _ := ParseDecimal("-1.5")
+	v := []*Decimal{&d, nil}
+	want := []byte{31, 2, 0, 0, 0, 30, 1, 0, 0, 0, 1, 0, 0, 0, 0x8F, 101}
+
+	w := &bytes.Buffer{}
+	if err := WriteObject(w, v); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+	if !reflect.DeepEqual(w.Bytes(), want) {
+		t.Errorf("WriteObject() = %v, want %v", w.Bytes(), want)
+	}
+	got, err := ReadObject(bytes.NewReader(want))
+	if err != nil {
+		t.Fatalf("ReadObject() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("ReadObject() = %v, want %v", got, v)
+	}
+}
+
+func TestMarshal_Decimal(t *testing.T) {
+	type Account struct {
+		Balance Decimal   `ignite:"balance"`
+		Limit   *Decimal  `ignite:"limit"`
+		History []Decimal `ignite:"history"`
+	}
+	balance, _ := ParseDecimal("1000.01")
+	h1, _ := ParseDecimal("0.5")
+	h2, _ := ParseDecimal("-2")
+	v := Account{Balance: balance, History: []Decimal{h1, h2}}
+
+	w := &bytes.Buffer{}
+	if err := WriteObject(w, v); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+	var got Account
+	if err := ReadObjectInto(bytes.NewReader(w.Bytes()), &got); err != nil {
+		t.Fatalf("ReadObjectInto() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("ReadObjectInto() = %v, want %v", got, v)
+	}
+}
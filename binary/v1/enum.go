@@ -0,0 +1,244 @@
+package ignite
+
+import (
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// Enum is "enum" type, value of the Java enum with ordinal Ordinal of the binary type TypeID
+type Enum struct {
+	TypeID  int32
+	Ordinal int32
+}
+
+// NewEnum returns value with ordinal of the enum type typeName
+func NewEnum(typeName string, ordinal int32) Enum {
+	return Enum{TypeID: HashCode(typeName), Ordinal: ordinal}
+}
+
+// Name returns name of the enum value.
+// The name is resolved by r using binary type metadata if r is not nil and the type is known to the cluster,
+// otherwise names of the Go type registered by RegisterEnum are used.
+func (e Enum) Name(r BinaryTypeResolver) (string, error) {
+	if r != nil {
+		if t, err := r.ResolveBinaryType(e.TypeID); err == nil && t.IsEnum {
+			for _, v := range t.EnumValues {
+				if v.Ordinal == e.Ordinal {
+					return v.Name, nil
+				}
+			}
+		}
+	}
+	if et := enums.byTypeID(e.TypeID); et != nil {
+		if e.Ordinal >= 0 && int(e.Ordinal) < len(et.names) {
+			return et.names[e.Ordinal], nil
+		}
+	}
+	return "", errors.Errorf("name of ordinal %d of enum type %d is not known", e.Ordinal, e.TypeID)
+}
+
+// enumType is Go type registered as Ignite enum type
+type enumType struct {
+	typ    reflect.Type
+	typeID int32
+	// names are names of the values by ordinals
+	names    []string
+	ordinals map[string]int32
+}
+
+// enumRegistry is registry of Go types registered as Ignite enum types
+type enumRegistry struct {
+	mutex sync.RWMutex
+	types map[reflect.Type]*enumType
+	ids   map[int32]*enumType
+}
+
+// enums is registry of the Go enum types
+var enums = &enumRegistry{types: map[reflect.Type]*enumType{}, ids: map[int32]*enumType{}}
+
+// RegisterEnum registers named Go type of the value v as Ignite enum type typeName with names of the values
+// in order of ordinals. Values of the type are written as enums by WriteObject and enums of the type are read
+// into values of the type by ReadObjectInto and UnmarshalObject.
+// The value of the integer type is the ordinal, the value of the string type is the name, so names must be set for it:
+//
+//	type Color int
+//
+//	const (
+//		Red Color = iota
+//		Green
+//	)
+//
+//	err := ignite.RegisterEnum(Red, "org.example.Color", "RED", "GREEN")
+func RegisterEnum(v interface{}, typeName string, names ...string) error {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Name() == "" || t.PkgPath() == "" {
+		return errors.Errorf("enum type must be named Go type, but got %T", v)
+	}
+	switch t.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+	case reflect.String:
+		if len(names) == 0 {
+			return errors.Errorf("names of the values of the enum type %s are not set", t)
+		}
+	default:
+		return errors.Errorf("enum type must be integer or string type, but got %s", t)
+	}
+	et := &enumType{typ: t, typeID: HashCode(typeName), names: names, ordinals: make(map[string]int32, len(names))}
+	for i, n := range names {
+		if _, ok := et.ordinals[n]; ok {
+			return errors.Errorf("duplicate name %s of the value of the enum type %s", n, t)
+		}
+		et.ordinals[n] = int32(i)
+	}
+
+	enums.mutex.Lock()
+	defer enums.mutex.Unlock()
+	if old, ok := enums.types[t]; ok {
+		delete(enums.ids, old.typeID)
+	}
+	enums.types[t] = et
+	enums.ids[et.typeID] = et
+	return nil
+}
+
+// byType returns registered enum type of the Go type t, nil is returned if the type is not registered
+func (r *enumRegistry) byType(t reflect.Type) *enumType {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.types[t]
+}
+
+// byTypeID returns registered enum type with ID typeID, nil is returned if the type is not registered
+func (r *enumRegistry) byTypeID(typeID int32) *enumType {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ids[typeID]
+}
+
+// marshal converts value v of the registered Go type to enum
+func (et *enumType) marshal(v reflect.Value) (Enum, error) {
+	switch v.Kind() {
+	case reflect.String:
+		o, ok := et.ordinals[v.String()]
+		if !ok {
+			return Enum{}, errors.Errorf("%q is not the value of the enum type %s", v.String(), et.typ)
+		}
+		return Enum{TypeID: et.typeID, Ordinal: o}, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		return Enum{TypeID: et.typeID, Ordinal: int32(v.Uint())}, nil
+	default:
+		return Enum{TypeID: et.typeID, Ordinal: int32(v.Int())}, nil
+	}
+}
+
+// unmarshal stores enum e in the value v of the registered Go type
+func (et *enumType) unmarshal(e Enum, v reflect.Value) error {
+	if e.TypeID != et.typeID {
+		return errors.Errorf("enum of type %d can't be set to %s", e.TypeID, et.typ)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if e.Ordinal < 0 || int(e.Ordinal) >= len(et.names) {
+			return errors.Errorf("name of ordinal %d of enum type %s is not known", e.Ordinal, et.typ)
+		}
+		v.SetString(et.names[e.Ordinal])
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		v.SetUint(uint64(e.Ordinal))
+	default:
+		v.SetInt(int64(e.Ordinal))
+	}
+	return nil
+}
+
+// WriteOEnum writes "enum" object value
+func WriteOEnum(w io.Writer, v Enum) error {
+	if err := WriteType(w, typeEnum); err != nil {
+		return err
+	}
+	if err := WriteInt(w, v.TypeID); err != nil {
+		return err
+	}
+	return WriteInt(w, v.Ordinal)
+}
+
+// WriteOArrayOEnums writes "enum" array object value, nil elements are written as NULL.
+// Element type ID is type ID of the first element, -1 is written if there are no elements.
+func WriteOArrayOEnums(w io.Writer, v []*Enum) error {
+	if err := WriteType(w, typeEnumArray); err != nil {
+		return err
+	}
+	typeID := int32(-1)
+	for _, e := range v {
+		if e != nil {
+			typeID = e.TypeID
+			break
+		}
+	}
+	if err := WriteInt(w, typeID); err != nil {
+		return err
+	}
+	if err := WriteInt(w, int32(len(v))); err != nil {
+		return err
+	}
+	for i, e := range v {
+		var err error
+		if e == nil {
+			err = WriteNull(w)
+		} else {
+			err = WriteOEnum(w, *e)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to write element with index %d", i)
+		}
+	}
+	return nil
+}
+
+// ReadEnum reads "enum" value
+func ReadEnum(r io.Reader) (Enum, error) {
+	typeID, err := ReadInt(r)
+	if err != nil {
+		return Enum{}, err
+	}
+	ordinal, err := ReadInt(r)
+	if err != nil {
+		return Enum{}, err
+	}
+	return Enum{TypeID: typeID, Ordinal: ordinal}, nil
+}
+
+// ReadArrayOEnums reads "enum" array value, NULL elements are read as nil
+func ReadArrayOEnums(r io.Reader) ([]*Enum, error) {
+	// element type ID is not used, every element has type ID
+	if _, err := ReadInt(r); err != nil {
+		return nil, err
+	}
+	l, err := readLength(r, 1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid enum array length")
+	}
+	b := make([]*Enum, 0, capacity(r, l))
+	for i := 0; i < l; i++ {
+		t, err := ReadByte(r)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case typeNULL:
+			b = append(b, nil)
+		case typeEnum, typeBinaryEnum:
+			e, err := ReadEnum(r)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read element with index %d", i)
+			}
+			b = append(b, &e)
+		default:
+			return nil, errors.Errorf("invalid type of element with index %d (expected %d, but got %d)", i, typeEnum, t)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,228 @@
+package ignite
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type testColor int
+
+const (
+	testRed testColor = iota
+	testGreen
+	testBlue
+)
+
+type testSize string
+
+func TestRegisterEnum(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        interface{}
+		typeName string
+		names    []string
+		wantErr  bool
+	}{
+		{
+			name:     "integer",
+			v:        testRed,
+			typeName: "TestColor",
+			names:    []string{"RED", "GREEN", "BLUE"},
+		},
+		{
+			name:     "string",
+			v:        testSize("S"),
+			typeName: "TestSize",
+			names:    []string{"S", "M", "L"},
+		},
+		{
+			name:     "string without names",
+			v:        testSize("S"),
+			typeName: "TestSize",
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate names",
+			v:        testRed,
+			typeName: "TestColor",
+			names:    []string{"RED", "RED"},
+			wantErr:  true,
+		},
+		{
+			name:     "not named type",
+			v:        1,
+			typeName: "Int",
+			wantErr:  true,
+		},
+		{
+			name:     "struct",
+			v:        Enum{},
+			typeName: "Enum",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterEnum(tt.v, tt.typeName, tt.names...); (err != nil) != tt.wantErr {
+				t.Errorf("RegisterEnum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnum(t *testing.T) {
+	if err := RegisterEnum(testRed, "TestColor", "RED", "GREEN", "BLUE"); err != nil {
+		t.Fatalf("RegisterEnum() error = %v", err)
+	}
+	if err := RegisterEnum(testSize(""), "TestSize", "S", "M", "L"); err != nil {
+		t.Fatalf("RegisterEnum() error = %v", err)
+	}
+	color := HashCode("TestColor")
+	size := HashCode("TestSize")
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want interface{}
+		dst  interface{}
+	}{
+		{
+			name: "enum",
+			v:    Enum{TypeID: 12, Ordinal: 3},
+			want: Enum{TypeID: 12, Ordinal: 3},
+			dst:  new(Enum),
+		},
+		{
+			name: "integer Go type",
+			v:    testBlue,
+			want: Enum{TypeID: color, Ordinal: 2},
+			dst:  new(testColor),
+		},
+		{
+			name: "string Go type",
+			v:    testSize("M"),
+			want: Enum{TypeID: size, Ordinal: 1},
+			dst:  new(testSize),
+		},
+		{
+			name: "array of Go type",
+			v:    []testColor{testGreen, testRed},
+			want: []*Enum{{TypeID: color, Ordinal: 1}, {TypeID: color, Ordinal: 0}},
+			dst:  new([]testColor),
+		},
+		{
+			name: "array with NULL",
+			v:    []*Enum{{TypeID: size, Ordinal: 2}, nil},
+			want: []*Enum{{TypeID: size, Ordinal: 2}, nil},
+			dst:  new([]*testSize),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := WriteObject(w, tt.v); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			got, err := ReadObject(bytes.NewReader(w.Bytes()))
+			if err != nil {
+				t.Fatalf("ReadObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadObject() = %v, want %v", got, tt.want)
+			}
+			if err = ReadObjectInto(bytes.NewReader(w.Bytes()), tt.dst); err != nil {
+				t.Fatalf("ReadObjectInto() error = %v", err)
+			}
+			want := tt.v
+			if tt.name == "array with NULL" {
+				l := testSize("L")
+				want = []*testSize{&l, nil}
+			}
+			if got := reflect.ValueOf(tt.dst).Elem().Interface(); !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadObjectInto() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteOEnum(t *testing.T) {
+	w := &bytes.Buffer{}
+	if err := WriteOEnum(w, Enum{TypeID: 1, Ordinal: 2}); err != nil {
+		t.Fatalf("WriteOEnum() error = %v", err)
+	}
+	want := []byte{28, 1, 0, 0, 0, 2, 0, 0, 0}
+	if !reflect.DeepEqual(w.Bytes(), want) {
+		t.Errorf("WriteOEnum() = %v, want %v", w.Bytes(), want)
+	}
+
+	// binary enum is read as enum
+	got, err := ReadObject(bytes.NewReader([]byte{38, 1, 0, 0, 0, 2, 0, 0, 0}))
+	if err != nil {
+		t.Fatalf("ReadObject() error = %v", err)
+	}
+	if got != (Enum{TypeID: 1, Ordinal: 2}) {
+		t.Errorf("ReadObject() = %v, want %v", got, Enum{TypeID: 1, Ordinal: 2})
+	}
+}
+
+func TestEnum_Name(t *testing.T) {
+	if err := RegisterEnum(testRed, "TestColor", "RED", "GREEN", "BLUE"); err != nil {
+		t.Fatalf("RegisterEnum() error = %v", err)
+	}
+	types := testBinaryTypes{
+		HashCode("Status"): {
+			TypeID:     HashCode("Status"),
+			TypeName:   "Status",
+			IsEnum:     true,
+			EnumValues: []BinaryEnumValue{{Name: "ACTIVE", Ordinal: 0}, {Name: "DELETED", Ordinal: 5}},
+		},
+	}
+	tests := []struct {
+		name    string
+		e       Enum
+		r       BinaryTypeResolver
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "binary type",
+			e:    NewEnum("Status", 5),
+			r:    types,
+			want: "DELETED",
+		},
+		{
+			name: "registered Go type",
+			e:    NewEnum("TestColor", 1),
+			r:    types,
+			want: "GREEN",
+		},
+		{
+			name: "registered Go type without resolver",
+			e:    NewEnum("TestColor", 2),
+			want: "BLUE",
+		},
+		{
+			name:    "unknown ordinal",
+			e:       NewEnum("TestColor", 3),
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			e:       NewEnum("Unknown", 0),
+			r:       types,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.e.Name(tt.r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Enum.Name() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Enum.Name() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package ignite
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+const (
+	// DefaultReconnectInitialInterval is default delay before the second reconnection attempt
+	DefaultReconnectInitialInterval = 100 * time.Millisecond
+	// DefaultReconnectMaxInterval is default maximum delay between reconnection attempts
+	DefaultReconnectMaxInterval = 30 * time.Second
+	// DefaultReconnectMultiplier is default factor the delay is multiplied by after every failed attempt
+	DefaultReconnectMultiplier = 2
+	// DefaultReconnectJitter is default randomization factor of the delay
+	DefaultReconnectJitter = 0.2
+)
+
+// ErrReconnecting is returned by operations while the client is reconnecting to the cluster
+var ErrReconnecting = errors.Errorf("client is reconnecting to the cluster")
+
+// ReconnectConfig contains parameters of reconnection after the connection is broken.
+// The first attempt is made immediately, delays between the next attempts grow exponentially.
+type ReconnectConfig struct {
+	// InitialInterval is delay before the second attempt.
+	// DefaultReconnectInitialInterval is used if value is not positive.
+	InitialInterval time.Duration
+
+	// MaxInterval is maximum delay between attempts.
+	// DefaultReconnectMaxInterval is used if value is not positive.
+	MaxInterval time.Duration
+
+	// Multiplier is factor the delay is multiplied by after every failed attempt.
+	// DefaultReconnectMultiplier is used if value is less than 1.
+	Multiplier float64
+
+	// Jitter is randomization factor in range (0, 1]: the delay is chosen randomly
+	// from [delay * (1 - Jitter), delay * (1 + Jitter)].
+	// DefaultReconnectJitter is used if value is not positive.
+	Jitter float64
+}
+
+// delay returns delay before reconnection attempt with index attempt (starting from 1)
+func (rc *ReconnectConfig) delay(attempt int) time.Duration {
+	initial, max, multiplier, jitter := rc.InitialInterval, rc.MaxInterval, rc.Multiplier, rc.Jitter
+	if initial <= 0 {
+		initial = DefaultReconnectInitialInterval
+	}
+	if max <= 0 {
+		max = DefaultReconnectMaxInterval
+	}
+	if multiplier < 1 {
+		multiplier = DefaultReconnectMultiplier
+	}
+	if jitter <= 0 {
+		jitter = DefaultReconnectJitter
+	} else if jitter > 1 {
+		jitter = 1
+	}
+
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if d > float64(max) {
+		d = float64(max)
+	}
+	return time.Duration(d * (1 + jitter*(2*rand.Float64()-1)))
+}
+
+// failover is transport over connection to one of the endpoints.
+// Broken connection is replaced in background by connection to the next available endpoint.
+type failover struct {
+	ci ConnInfo
+	// ctx is canceled when transport is closed to stop reconnection
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex  sync.Mutex
+	conn   *connection
+	active int
+	closed bool
+	// reconnecting is true if the connection is broken and the new one is not opened yet
+	reconnecting bool
+	// firstAttempt is closed when the first reconnection attempt is finished
+	firstAttempt chan struct{}
+}
+
+// newFailover opens connection to the first available endpoint
+func newFailover(ctx context.Context, ci ConnInfo) (*failover, error) {
+	conn, active, err := dial(ctx, ci, 0)
+	if err != nil {
+		return nil, err
+	}
+	return startFailover(ci, conn, active), nil
+}
+
+// startFailover creates transport over the opened connection to the endpoint with index active
+func startFailover(ci ConnInfo, conn *connection, active int) *failover {
+	f := &failover{ci: ci, conn: conn, active: active}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	go f.watch(conn)
+
+	return f
+}
+
+// watch starts reconnection when the connection is broken
+func (f *failover) watch(conn *connection) {
+	select {
+	case <-conn.done:
+		f.broken(conn)
+	case <-f.ctx.Done():
+	}
+}
+
+// broken starts reconnection if conn is the current connection
+func (f *failover) broken(conn *connection) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.closed || f.reconnecting || f.conn != conn {
+		return
+	}
+	f.reconnecting = true
+	f.firstAttempt = make(chan struct{})
+	go f.reconnect(f.firstAttempt, f.active+1)
+}
+
+// reconnect connects to the next available endpoint and makes handshake with the original credentials.
+// Attempts are repeated with exponential backoff until success or the transport is closed.
+func (f *failover) reconnect(firstAttempt chan struct{}, first int) {
+	// the first attempt is finished on every return, so operations waiting for it are released
+	var once sync.Once
+	finished := func() { once.Do(func() { close(firstAttempt) }) }
+	defer finished()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			t := time.NewTimer(f.ci.Reconnect.delay(attempt))
+			select {
+			case <-t.C:
+			case <-f.ctx.Done():
+				t.Stop()
+				return
+			}
+		}
+
+		conn, active, err := dial(f.ctx, f.ci, first)
+		f.mutex.Lock()
+		if f.closed {
+			f.mutex.Unlock()
+			if err == nil {
+				_ = conn.close()
+			}
+			return
+		}
+		if err == nil {
+			f.conn, f.active, f.reconnecting = conn, active, false
+		}
+		f.mutex.Unlock()
+
+		finished()
+		if err == nil {
+			go f.watch(conn)
+			return
+		}
+	}
+}
+
+// connection returns the current connection.
+// If the client is reconnecting it waits for the first reconnection attempt,
+// returns ErrReconnecting if the attempt is failed.
+func (f *failover) connection(ctx context.Context) (*connection, error) {
+	f.mutex.Lock()
+	if f.closed {
+		f.mutex.Unlock()
+		return nil, ErrClientClosed
+	}
+	conn := f.conn
+	reconnecting, firstAttempt := f.reconnecting, f.firstAttempt
+	f.mutex.Unlock()
+
+	if !reconnecting {
+		if conn.alive() {
+			return conn, nil
+		}
+		// the connection is broken but watcher is not notified yet
+		f.broken(conn)
+		return f.connection(ctx)
+	}
+
+	select {
+	case <-firstAttempt:
+	case <-f.ctx.Done():
+		return nil, ErrClientClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	switch {
+	case f.closed:
+		return nil, ErrClientClosed
+	case f.reconnecting:
+		return nil, ErrReconnecting
+	default:
+		return f.conn, nil
+	}
+}
+
+// do sends request message over the alive connection and waits for response message
+func (f *failover) do(ctx context.Context, message []byte) ([]byte, error) {
+	conn, err := f.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.do(ctx, message)
+}
+
+// stream sends request message over the alive connection and calls fn with reader of the response message
+func (f *failover) stream(ctx context.Context, message []byte, fn func(r io.Reader) error) error {
+	conn, err := f.connection(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.stream(ctx, message, fn)
+}
+
+// bind returns transport which sends requests over the current connection until it is closed.
+// Requests fail if the connection is broken, they are not sent over the new connection.
+func (f *failover) bind(ctx context.Context) (transport, error) {
+	conn, err := f.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pinnedConnection{connection: conn, ep: f.endpoint()}, nil
+}
+
+// alive returns true if the connection is not closed or broken
+func (f *failover) alive() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return !f.closed && !f.reconnecting && f.conn.alive()
+}
+
+// state returns StateConnecting until the first reconnection attempt is finished,
+// StateBroken if the attempt is failed
+func (f *failover) state() ConnectionState {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	switch {
+	case f.closed:
+		return StateClosed
+	case f.reconnecting:
+		select {
+		case <-f.firstAttempt:
+			return StateBroken
+		default:
+			return StateConnecting
+		}
+	case !f.conn.alive():
+		return StateBroken
+	default:
+		return StateReady
+	}
+}
+
+// endpoint returns endpoint of the last connected cluster node
+func (f *failover) endpoint() Endpoint {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.ci.endpoints()[f.active]
+}
+
+// session returns parameters negotiated with the last connected cluster node
+func (f *failover) session() session {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.conn.session
+}
+
+// close closes the connection and stops reconnection
+func (f *failover) close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	f.cancel()
+	return f.conn.close()
+}
+
+// pinnedConnection is transport which sends requests over the single connection of the failover transport.
+// Closing the transport does not close the connection.
+type pinnedConnection struct {
+	*connection
+	ep Endpoint
+}
+
+// state returns StateReady if the connection is alive, StateBroken otherwise
+func (p *pinnedConnection) state() ConnectionState {
+	if p.alive() {
+		return StateReady
+	}
+	return StateBroken
+}
+
+// endpoint returns endpoint of the connection
+func (p *pinnedConnection) endpoint() Endpoint {
+	return p.ep
+}
+
+// session returns parameters negotiated over the connection
+func (p *pinnedConnection) session() session {
+	return p.connection.session
+}
+
+// close does nothing, the connection is owned by the failover transport
+func (p *pinnedConnection) close() error {
+	return nil
+}
+
+// isIdempotent returns true if operation request can be safely sent again
+// when it is unknown whether the server executed it.
+// Only read operations are retried: result of the repeated update may depend on the first execution.
+func isIdempotent(message []byte) bool {
+	if len(message) < requestHeaderLength {
+		return false
+	}
+	// transaction is rolled back when its connection is broken
+	if isTransactional(message) {
+		return false
+	}
+	switch binary.LittleEndian.Uint16(message[4:]) {
+	case OpCacheGetNames, OpCacheGetConfiguration, OpCacheGet, OpCacheGetAll, OpCacheContainsKey, OpCacheContainsKeys,
+		OpCacheGetSize, OpCachePartitions:
+		return true
+	default:
+		return false
+	}
+}
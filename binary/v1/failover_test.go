@@ -0,0 +1,272 @@
+package ignite
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnect_Failover(t *testing.T) {
+	// first node drops connection on any request
+	s1 := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		return nil, errTestDrop
+	})
+	defer s1.close()
+	s2 := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s2.close()
+
+	tests := []struct {
+		name string
+		// operation which is sent to the first node
+		op      func(c Client) error
+		wantErr bool
+	}{
+		{
+			name: "idempotent operation is retried",
+			op: func(c Client) error {
+				_, err := c.CacheGetNames()
+				return err
+			},
+		},
+		{
+			name: "not idempotent operation is not retried",
+			op: func(c Client) error {
+				return c.CacheCreateWithName("cache")
+			},
+			wantErr: true,
+		},
+		{
+			name: "update is not retried",
+			op: func(c Client) error {
+				return c.CachePut("cache", false, "key", "value")
+			},
+			wantErr: true,
+		},
+		{
+			name: "conditional update is not retried",
+			op: func(c Client) error {
+				_, err := c.CacheReplace("cache", false, "key", "value")
+				return err
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := s1.connInfo()
+			ci.Endpoints = []Endpoint{s1.endpoint(), s2.endpoint()}
+			c, err := Connect(ci)
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer c.Close()
+			if got := c.ActiveEndpoint(); got != s1.endpoint() {
+				t.Errorf("Client.ActiveEndpoint() = %v, want %v", got, s1.endpoint())
+			}
+
+			if err = tt.op(c); (err != nil) != tt.wantErr {
+				t.Errorf("operation error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// next operation is sent to the second node
+			if _, err = c.CacheGetNames(); err != nil {
+				t.Errorf("Client.CacheGetNames() error = %v", err)
+			}
+			if got := c.ActiveEndpoint(); got != s2.endpoint() {
+				t.Errorf("Client.ActiveEndpoint() = %v, want %v", got, s2.endpoint())
+			}
+		})
+	}
+}
+
+func TestConnect_FailoverUnavailable(t *testing.T) {
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+
+	// address nobody listens to
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := l.Addr().(*net.TCPAddr)
+	l.Close()
+	unavailable := Endpoint{Host: addr.IP.String(), Port: addr.Port}
+
+	ci := s.connInfo()
+	ci.Endpoints = []Endpoint{unavailable, s.endpoint()}
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+	if got := c.ActiveEndpoint(); got != s.endpoint() {
+		t.Errorf("Client.ActiveEndpoint() = %v, want %v", got, s.endpoint())
+	}
+
+	ci.Endpoints = []Endpoint{unavailable, unavailable}
+	if _, err = Connect(ci); err == nil {
+		t.Errorf("Connect() to unavailable endpoints succeeded")
+	}
+}
+
+func TestConnect_Reconnect(t *testing.T) {
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+
+	ci := s.connInfo()
+	ci.Reconnect = ReconnectConfig{InitialInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	// connection is broken and restored in background
+	s.drop()
+	waitFor(t, func() bool { return s.accepted() == 2 && c.Connected() })
+	if _, err = c.CacheGetNames(); err != nil {
+		t.Errorf("Client.CacheGetNames() error = %v", err)
+	}
+
+	if got := c.State(); got != StateReady {
+		t.Errorf("Client.State() = %v, want %v", got, StateReady)
+	}
+
+	// server is not available
+	s.close()
+	waitFor(t, func() bool { return c.State() == StateBroken })
+	if _, err = c.CacheGetNames(); !stderrors.Is(err, ErrReconnecting) {
+		t.Errorf("Client.CacheGetNames() error = %v, want %v", err, ErrReconnecting)
+	}
+}
+
+func TestFailover_CloseReconnecting(t *testing.T) {
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+	silent, accepted := newSilentListener(t)
+	defer silent.Close()
+
+	ci := s.connInfo()
+	ci.Endpoints = []Endpoint{s.endpoint(), endpointOf(silent)}
+	f, err := newFailover(context.Background(), ci)
+	if err != nil {
+		t.Fatalf("newFailover() error = %v", err)
+	}
+
+	// the first reconnection attempt hangs in handshake with the silent node
+	s.drop()
+	select {
+	case conn := <-accepted:
+		defer conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatalf("reconnection is not started in time")
+	}
+	if got := f.state(); got != StateConnecting {
+		t.Errorf("failover.state() = %v, want %v", got, StateConnecting)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := f.connection(context.Background())
+		errs <- err
+	}()
+	// the operation waits for the first reconnection attempt
+	time.Sleep(50 * time.Millisecond)
+	if err = f.close(); err != nil {
+		t.Errorf("failover.close() error = %v", err)
+	}
+	select {
+	case err = <-errs:
+		if !stderrors.Is(err, ErrClientClosed) {
+			t.Errorf("failover.connection() error = %v, want %v", err, ErrClientClosed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("failover.connection() is not finished after close")
+	}
+}
+
+func TestReconnectConfig_delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		rc      ReconnectConfig
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{
+			name:    "default",
+			attempt: 1,
+			min:     80 * time.Millisecond,
+			max:     120 * time.Millisecond,
+		},
+		{
+			name:    "exponential",
+			rc:      ReconnectConfig{InitialInterval: time.Second, Multiplier: 3, Jitter: 0.1},
+			attempt: 3,
+			min:     8100 * time.Millisecond,
+			max:     9900 * time.Millisecond,
+		},
+		{
+			name:    "max interval",
+			rc:      ReconnectConfig{InitialInterval: time.Second, MaxInterval: 5 * time.Second, Jitter: 0.5},
+			attempt: 10,
+			min:     2500 * time.Millisecond,
+			max:     7500 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := tt.rc.delay(tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("ReconnectConfig.delay() = %v, want in [%v, %v]", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+// waitFor waits until condition is true
+// newSilentListener starts listener which accepts connections but never answers,
+// accepted connections are sent to the returned channel
+func newSilentListener(t *testing.T) (net.Listener, <-chan net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	accepted := make(chan net.Conn, 16)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	return l, accepted
+}
+
+// endpointOf returns address of the listener
+func endpointOf(l net.Listener) Endpoint {
+	addr := l.Addr().(*net.TCPAddr)
+	return Endpoint{Host: addr.IP.String(), Port: addr.Port}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition is not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
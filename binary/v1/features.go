@@ -0,0 +1,50 @@
+package ignite
+
+// Feature is optional protocol feature negotiated in the handshake (protocol version 1.7.0 and above)
+type Feature int
+
+const (
+	// FeatureUserAttributes is USER_ATTRIBUTES = 0
+	FeatureUserAttributes Feature = 0
+	// FeatureHeartbeat is HEARTBEAT = 11
+	FeatureHeartbeat Feature = 11
+)
+
+// Features is bitmask of protocol features, bit with index of the feature is set if the feature is supported
+type Features []byte
+
+// newFeatures returns bitmask of the features
+func newFeatures(features ...Feature) Features {
+	var fs Features
+	for _, f := range features {
+		for len(fs) <= int(f)/8 {
+			fs = append(fs, 0)
+		}
+		fs[f/8] |= 1 << (uint(f) % 8)
+	}
+	return fs
+}
+
+// Has returns true if feature f is supported
+func (fs Features) Has(f Feature) bool {
+	if f < 0 || int(f)/8 >= len(fs) {
+		return false
+	}
+	return fs[f/8]&(1<<(uint(f)%8)) != 0
+}
+
+// intersect returns features supported by both fs and o
+func (fs Features) intersect(o Features) Features {
+	n := len(fs)
+	if len(o) < n {
+		n = len(o)
+	}
+	res := make(Features, n)
+	for i := range res {
+		res[i] = fs[i] & o[i]
+	}
+	return res
+}
+
+// clientFeatures contains features supported by the client
+var clientFeatures = newFeatures(FeatureUserAttributes, FeatureHeartbeat)
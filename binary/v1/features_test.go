@@ -0,0 +1,75 @@
+package ignite
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFeatures_Has(t *testing.T) {
+	tests := []struct {
+		name string
+		fs   Features
+		f    Feature
+		want bool
+	}{
+		{name: "first byte", fs: newFeatures(0, 3), f: 3, want: true},
+		{name: "second byte", fs: newFeatures(9), f: 9, want: true},
+		{name: "not set", fs: newFeatures(9), f: 8},
+		{name: "out of bitmask", fs: newFeatures(1), f: 11},
+		{name: "empty", f: FeatureUserAttributes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fs.Has(tt.f); got != tt.want {
+				t.Errorf("Features.Has() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeatures_intersect(t *testing.T) {
+	got := newFeatures(0, 2, 9).intersect(newFeatures(2, 3))
+	if want := newFeatures(2); !bytes.Equal(got, want) {
+		t.Errorf("Features.intersect() = %v, want %v", got, want)
+	}
+}
+
+func TestConnect_Features(t *testing.T) {
+	nodeID := uuid.New()
+	s := newTestNode(t, nodeID, func(conn int, code int16, data []byte) ([]byte, error) {
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+
+	ci := s.connInfo()
+	ci.Major, ci.Minor, ci.Patch = 0, 0, 0
+	ci.UserAttributes = map[string]string{"app": "test"}
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	if got := c.ProtocolVersion(); got != featuresVersion {
+		t.Errorf("Client.ProtocolVersion() = %v, want %v", got, featuresVersion)
+	}
+	if got := c.NodeID(); got != nodeID {
+		t.Errorf("Client.NodeID() = %v, want %v", got, nodeID)
+	}
+	if got := c.Features(); !bytes.Equal(got, clientFeatures) {
+		t.Errorf("Client.Features() = %v, want %v", got, clientFeatures)
+	}
+	if !c.HasFeature(FeatureUserAttributes) {
+		t.Errorf("Client.HasFeature(FeatureUserAttributes) = false, want true")
+	}
+	attributes := &bytes.Buffer{}
+	_ = writeUserAttributes(attributes, ci.UserAttributes)
+	if !bytes.Contains(s.handshake(0), attributes.Bytes()) {
+		t.Errorf("handshake request does not contain user attributes")
+	}
+	if _, err = c.CacheGetNames(); err != nil {
+		t.Errorf("Client.CacheGetNames() error = %v", err)
+	}
+}
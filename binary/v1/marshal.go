@@ -0,0 +1,770 @@
+package ignite
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// Go structs are marshalled to complex objects by WriteObject and unmarshalled by ReadObjectInto.
+// Exported fields are mapped to the object fields, the mapping is set by the "ignite" field tag:
+//
+//	Name    string    `ignite:"name"`                // field "name"
+//	Created time.Time `ignite:"created,type=date"`   // field "created" of Date type
+//	Age     *int32    `ignite:",omitempty"`          // nil pointer is not written
+//	Secret  string    `ignite:"-"`                   // field is ignored
+//
+// Field name is the name of the struct field if the tag name is empty.
+// Supported type options are "timestamp" (default for time.Time), "date", "time" and "char".
+// Nil pointers, slices and interfaces are written as NULL, slices of types without Ignite array type
+// (structs for example) are written as object arrays.
+
+const (
+	// tagName is key of the struct field tag with the mapping of the field
+	tagName = "ignite"
+
+	// type options of the struct field tag
+	tagTypeTimestamp = "timestamp"
+	tagTypeDate      = "date"
+	tagTypeTime      = "time"
+	tagTypeChar      = "char"
+)
+
+// BinaryTypeNamer is implemented by Go structs with binary type name different from the struct type name
+type BinaryTypeNamer interface {
+	BinaryTypeName() string
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	decimalType    = reflect.TypeOf(Decimal{})
+	collectionType = reflect.TypeOf(Collection{})
+	mapType        = reflect.TypeOf(Map{})
+	enumValueType  = reflect.TypeOf(Enum{})
+	binaryObjType  = reflect.TypeOf(BinaryObject{})
+	charType       = reflect.TypeOf(Char(0))
+	namerType      = reflect.TypeOf((*BinaryTypeNamer)(nil)).Elem()
+
+	marshalerType   = reflect.TypeOf((*BinaryMarshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*BinaryUnmarshaler)(nil)).Elem()
+)
+
+// structField is field of the Go struct mapped to the complex object field
+type structField struct {
+	// index is index sequence of the field for reflect.Value.FieldByIndex
+	index []int
+	name  string
+	id    int32
+	// typ is type option of the field tag
+	typ       string
+	omitEmpty bool
+}
+
+// structType is mapping of the Go struct to the complex object
+type structType struct {
+	typeID int32
+	fields []structField
+	byID   map[int32]*structField
+}
+
+// structTypes caches mappings of the Go structs by reflect.Type
+var structTypes sync.Map
+
+// getStructType returns mapping of the Go struct type t
+func getStructType(t reflect.Type) (*structType, error) {
+	if v, ok := structTypes.Load(t); ok {
+		return v.(*structType), nil
+	}
+
+	name := t.Name()
+	if t.Implements(namerType) {
+		name = reflect.Zero(t).Interface().(BinaryTypeNamer).BinaryTypeName()
+	}
+	if name == "" {
+		return nil, errors.Errorf("binary type name of anonymous struct is not set")
+	}
+	st := &structType{typeID: HashCode(name), byID: map[int32]*structField{}}
+	if err := st.addFields(t, nil); err != nil {
+		return nil, errors.Wrapf(err, "failed to map struct %s", t)
+	}
+	for i := range st.fields {
+		f := &st.fields[i]
+		if d, ok := st.byID[f.id]; ok {
+			return nil, errors.Errorf("fields %s and %s of struct %s have the same ID", d.name, f.name, t)
+		}
+		st.byID[f.id] = f
+	}
+
+	v, _ := structTypes.LoadOrStore(t, st)
+	return v.(*structType), nil
+}
+
+// addFields adds exported fields of the struct type t, fields of embedded structs without tag are added too
+func (st *structType) addFields(t reflect.Type, index []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup(tagName)
+		if tag == "-" {
+			continue
+		}
+		fi := append(append([]int(nil), index...), i)
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct && f.Type != timeType {
+			if err := st.addFields(f.Type, fi); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported field
+			continue
+		}
+		sf := structField{index: fi, name: f.Name}
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			sf.name = opts[0]
+		}
+		for _, o := range opts[1:] {
+			switch {
+			case o == "omitempty":
+				sf.omitEmpty = true
+			case strings.HasPrefix(o, "type="):
+				sf.typ = strings.TrimPrefix(o, "type=")
+				switch sf.typ {
+				case tagTypeTimestamp, tagTypeDate, tagTypeTime, tagTypeChar:
+				default:
+					return errors.Errorf("unsupported type option %q of field %s", sf.typ, f.Name)
+				}
+			default:
+				return errors.Errorf("unsupported option %q of field %s", o, f.Name)
+			}
+		}
+		sf.id = HashCode(sf.name)
+		st.fields = append(st.fields, sf)
+	}
+	return nil
+}
+
+// marshalStruct converts the Go struct to the complex object
+func marshalStruct(v reflect.Value) (ComplexObject, error) {
+	st, err := getStructType(v.Type())
+	if err != nil {
+		return ComplexObject{}, err
+	}
+	c := ComplexObject{Type: st.typeID, Fields: make(map[int32]interface{}, len(st.fields))}
+	for _, f := range st.fields {
+		fv := v.FieldByIndex(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		o, err := marshalValue(fv, f.typ)
+		if err != nil {
+			return ComplexObject{}, errors.Wrapf(err, "failed to marshal field %s", f.name)
+		}
+		c.Fields[f.id] = o
+	}
+	if schemas.get(st.typeID, c.Fields) == nil {
+		// fields are written in order of the struct fields
+		ids := make([]int32, 0, len(c.Fields))
+		for _, f := range st.fields {
+			if _, ok := c.Fields[f.id]; ok {
+				ids = append(ids, f.id)
+			}
+		}
+		schemas.put(st.typeID, ids, false, false)
+	}
+	return c, nil
+}
+
+// marshalValue converts the Go value to the value supported by WriteObject.
+// typ is type option of the struct field tag.
+func marshalValue(v reflect.Value, typ string) (interface{}, error) {
+	if et := enums.byType(v.Type()); et != nil {
+		return et.marshal(v)
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return marshalValue(v.Elem(), typ)
+	case reflect.Struct:
+		if v.Type().Implements(marshalerType) {
+			return v.Interface(), nil
+		}
+		switch v.Type() {
+		case timeType:
+			t := v.Interface().(time.Time)
+			switch typ {
+			case tagTypeDate:
+				return ToDate(t), nil
+			case tagTypeTime:
+				return ToTime(t), nil
+			}
+			return t, nil
+		case reflect.TypeOf(ComplexObject{}), decimalType, collectionType, mapType:
+			return v.Interface(), nil
+		}
+		return marshalStruct(v)
+	case reflect.Array:
+		if v.Type() == uuidType {
+			return v.Interface(), nil
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return marshalSlice(v, typ)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return marshalMap(v, typ)
+	case reflect.Int8:
+		return byte(v.Int()), nil
+	case reflect.Uint8:
+		return byte(v.Uint()), nil
+	case reflect.Int16:
+		return int16(v.Int()), nil
+	case reflect.Uint16:
+		if typ == tagTypeChar {
+			return Char(v.Uint()), nil
+		}
+		return int16(v.Uint()), nil
+	case reflect.Int32:
+		if typ == tagTypeChar {
+			return Char(v.Int()), nil
+		}
+		return int32(v.Int()), nil
+	case reflect.Uint32:
+		return int32(v.Uint()), nil
+	case reflect.Int, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Float32:
+		return float32(v.Float()), nil
+	case reflect.Float64:
+		return v.Float(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	}
+	return nil, errors.Errorf("unsupported type %s", v.Type())
+}
+
+// marshalSlice converts the Go slice to the array supported by WriteObject.
+// Slice of the type without Ignite array type is converted to the object array.
+func marshalSlice(v reflect.Value, typ string) (interface{}, error) {
+	if et := enums.byType(v.Type().Elem()); et != nil {
+		a := make([]*Enum, v.Len())
+		for i := range a {
+			e, err := et.marshal(v.Index(i))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to marshal element with index %d", i)
+			}
+			a[i] = &e
+		}
+		return a, nil
+	}
+	var a interface{}
+	switch t := v.Type().Elem(); {
+	case t == timeType && typ == tagTypeDate:
+		a = make([]Date, v.Len())
+	case t == timeType && typ == tagTypeTime:
+		a = make([]Time, v.Len())
+	case t == timeType:
+		a = []time.Time{}
+	case t == uuidType:
+		a = []uuid.UUID{}
+	case t == decimalType:
+		a = []Decimal{}
+	case t == reflect.PtrTo(decimalType):
+		a = []*Decimal{}
+	case t.Kind() == reflect.Uint8 || t.Kind() == reflect.Int8:
+		a = []byte{}
+	case (t.Kind() == reflect.Int32 || t.Kind() == reflect.Uint16) && typ == tagTypeChar:
+		a = []Char{}
+	case t.Kind() == reflect.Int16 || t.Kind() == reflect.Uint16:
+		a = []int16{}
+	case t.Kind() == reflect.Int32 || t.Kind() == reflect.Uint32:
+		a = []int32{}
+	case t.Kind() == reflect.Int || t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint || t.Kind() == reflect.Uint64:
+		a = []int64{}
+	case t.Kind() == reflect.Float32:
+		a = []float32{}
+	case t.Kind() == reflect.Float64:
+		a = []float64{}
+	case t.Kind() == reflect.Bool:
+		a = []bool{}
+	case t.Kind() == reflect.String:
+		a = []string{}
+	}
+
+	if a == nil {
+		// object array
+		o := make([]interface{}, v.Len())
+		for i := range o {
+			var err error
+			if o[i], err = marshalValue(v.Index(i), typ); err != nil {
+				return nil, errors.Wrapf(err, "failed to marshal element with index %d", i)
+			}
+		}
+		return o, nil
+	}
+
+	av := reflect.ValueOf(a)
+	if v.Type() == av.Type() {
+		return v.Interface(), nil
+	}
+	et := av.Type().Elem()
+	if v.Type().Elem().ConvertibleTo(et) && av.Len() == 0 {
+		av = reflect.MakeSlice(av.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			av.Index(i).Set(v.Index(i).Convert(et))
+		}
+		return av.Interface(), nil
+	}
+	// dates and times
+	for i := 0; i < v.Len(); i++ {
+		o, err := marshalValue(v.Index(i), typ)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal element with index %d", i)
+		}
+		av.Index(i).Set(reflect.ValueOf(o))
+	}
+	return av.Interface(), nil
+}
+
+// isEmptyValue returns true if v is zero value
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// WriteOArrayObjects writes "object array" value, element type ID is -1 (java.lang.Object)
+func WriteOArrayObjects(w io.Writer, v []interface{}) error {
+	if err := WriteType(w, typeObjectArray); err != nil {
+		return err
+	}
+	if err := WriteInt(w, -1); err != nil {
+		return err
+	}
+	if err := WriteInt(w, int32(len(v))); err != nil {
+		return err
+	}
+	for i, o := range v {
+		if err := WriteObject(w, o); err != nil {
+			return errors.Wrapf(err, "failed to write element with index %d", i)
+		}
+	}
+	return nil
+}
+
+// ReadArrayObjects reads "object array" value
+func ReadArrayObjects(r io.Reader) ([]interface{}, error) {
+	// element type ID is not used
+	if _, err := ReadInt(r); err != nil {
+		return nil, err
+	}
+	l, err := readLength(r, 1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid object array length")
+	}
+	b := make([]interface{}, 0, capacity(r, l))
+	for i := 0; i < l; i++ {
+		o, err := ReadObject(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read element with index %d", i)
+		}
+		b = append(b, o)
+	}
+	return b, nil
+}
+
+// ReadObjectInto reads object and stores it in the value pointed to by dst.
+// Complex object is read into the struct field by field without intermediate ComplexObject,
+// numbers, strings and bools are converted to the type of dst if it is possible.
+// Error is returned if the integer overflows the type of dst or the floating-point number is read into the integer.
+func ReadObjectInto(r io.Reader, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.Errorf("destination must be non-nil pointer, but got %T", dst)
+	}
+	return readValue(r, v.Elem())
+}
+
+// UnmarshalObject stores object o returned by ReadObject (by CacheGet for example) in the value pointed to by dst.
+// Complex objects are stored in the structs, numbers, strings and bools are converted to the type of dst if it is possible
+// the same way as by ReadObjectInto.
+func UnmarshalObject(o interface{}, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.Errorf("destination must be non-nil pointer, but got %T", dst)
+	}
+	return setValue(v.Elem(), reflect.ValueOf(o))
+}
+
+// readValue reads object into v
+func readValue(r io.Reader, v reflect.Value) error {
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		return v.Addr().Interface().(BinaryUnmarshaler).ReadBinary(r)
+	}
+	t, err := ReadByte(r)
+	if err != nil {
+		return err
+	}
+	return readValueOfType(r, t, v)
+}
+
+// readValueOfType reads object value with type code t into v
+func readValueOfType(r io.Reader, t byte, v reflect.Value) error {
+	if t == typeNULL {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return readValueOfType(r, t, v.Elem())
+	case reflect.Struct:
+		if t == typeComplexObject && v.Type() != reflect.TypeOf(ComplexObject{}) {
+			return readStruct(r, v)
+		}
+	case reflect.Slice:
+		if t == typeObjectArray {
+			return readSlice(r, v)
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int, reflect.Int64:
+		if n, err := readInteger(r, t); err == nil {
+			if v.OverflowInt(n) {
+				return errors.Errorf("value %d overflows %s", n, v.Type())
+			}
+			v.SetInt(n)
+			return nil
+		} else if err != errNotInteger {
+			return err
+		}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		if n, err := readInteger(r, t); err == nil {
+			u := unsigned(n, integerBits[t])
+			if v.OverflowUint(u) {
+				return errors.Errorf("value %d overflows %s", u, v.Type())
+			}
+			v.SetUint(u)
+			return nil
+		} else if err != errNotInteger {
+			return err
+		}
+	case reflect.Float32, reflect.Float64:
+		switch t {
+		case typeFloat:
+			f, err := ReadFloat(r)
+			v.SetFloat(float64(f))
+			return err
+		case typeDouble:
+			f, err := ReadDouble(r)
+			if err != nil {
+				return err
+			}
+			if v.OverflowFloat(f) {
+				return errors.Errorf("value %g overflows %s", f, v.Type())
+			}
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.Bool:
+		if t == typeBool {
+			b, err := ReadBool(r)
+			v.SetBool(b)
+			return err
+		}
+	case reflect.String:
+		if t == typeString {
+			s, err := ReadString(r)
+			v.SetString(s)
+			return err
+		}
+	}
+
+	o, err := readObject(r, t)
+	if err != nil {
+		return err
+	}
+	return setValue(v, reflect.ValueOf(o))
+}
+
+// errNotInteger is returned by readInteger if the value is not integer
+var errNotInteger = errors.Errorf("value is not integer")
+
+// integerBits are sizes in bits of the integer values by type codes
+var integerBits = map[byte]int{typeByte: 8, typeShort: 16, typeInt: 32, typeLong: 64, typeChar: 16}
+
+// unsigned converts signed integer n of the size bits to unsigned integer.
+// Unsigned Go values are written as signed values of the same size, so negative value is read as the large one.
+func unsigned(n int64, bits int) uint64 {
+	if bits >= 64 {
+		return uint64(n)
+	}
+	return uint64(n) & (1<<uint(bits) - 1)
+}
+
+// readInteger reads integer value with type code t
+func readInteger(r io.Reader, t byte) (int64, error) {
+	switch t {
+	case typeByte:
+		v, err := ReadByte(r)
+		return int64(int8(v)), err
+	case typeShort:
+		v, err := ReadShort(r)
+		return int64(v), err
+	case typeInt:
+		v, err := ReadInt(r)
+		return int64(v), err
+	case typeLong:
+		return ReadLong(r)
+	case typeChar:
+		v, err := ReadChar(r)
+		return int64(v), err
+	}
+	return 0, errNotInteger
+}
+
+// readStruct reads complex object into the struct v
+func readStruct(r io.Reader, v reflect.Value) error {
+	st, err := getStructType(v.Type())
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.Zero(v.Type()))
+	_, _, err = readComplexObject(r, func(fieldID int32, field io.Reader) error {
+		f, ok := st.byID[fieldID]
+		if !ok {
+			// field is not mapped to the struct
+			return nil
+		}
+		if err := readValue(field, fieldByIndex(v, f.index)); err != nil {
+			return errors.Wrapf(err, "failed to read field %s", f.name)
+		}
+		return nil
+	})
+	return err
+}
+
+// fieldByIndex returns the nested field of the struct v, nil pointers to embedded structs are allocated
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// readSlice reads object array into the slice v
+func readSlice(r io.Reader, v reflect.Value) error {
+	// element type ID is not used
+	if _, err := ReadInt(r); err != nil {
+		return err
+	}
+	l, err := readLength(r, 1)
+	if err != nil {
+		return errors.Wrapf(err, "invalid object array length")
+	}
+	s := reflect.MakeSlice(v.Type(), 0, capacity(r, l))
+	for i := 0; i < l; i++ {
+		e := reflect.New(v.Type().Elem()).Elem()
+		if err = readValue(r, e); err != nil {
+			return errors.Wrapf(err, "failed to read element with index %d", i)
+		}
+		s = reflect.Append(s, e)
+	}
+	v.Set(s)
+	return nil
+}
+
+// setValue stores value o read by ReadObject in v converting it to the type of v if it is necessary
+func setValue(v reflect.Value, o reflect.Value) error {
+	switch {
+	case !o.IsValid():
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case o.Type().AssignableTo(v.Type()):
+		v.Set(o)
+		return nil
+	case o.Type() == binaryObjType:
+		b := o.Interface().(BinaryObject)
+		return readValue(&decoder{b: b.Bytes(), resolver: b.resolver}, v)
+	case o.Kind() == reflect.Slice && v.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), o.Len(), o.Len())
+		for i := 0; i < o.Len(); i++ {
+			e := o.Index(i)
+			if e.Kind() == reflect.Interface {
+				if e.IsNil() {
+					continue
+				}
+				e = e.Elem()
+			}
+			if err := setValue(s.Index(i), e); err != nil {
+				return errors.Wrapf(err, "failed to set element with index %d", i)
+			}
+		}
+		v.Set(s)
+		return nil
+	case o.Kind() == reflect.Ptr && o.IsNil():
+		// NULL element of decimal array for example
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case v.Kind() == reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), o); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	case o.Kind() == reflect.Ptr:
+		// element of decimal array for example
+		return setValue(v, o.Elem())
+	case o.Type() == collectionType && v.Kind() == reflect.Slice:
+		return setValue(v, reflect.ValueOf(o.Interface().(Collection).Items))
+	case o.Type() == mapType && v.Kind() == reflect.Map:
+		return setMap(v, o.Interface().(Map))
+	case o.Kind() == reflect.Struct && v.Kind() == reflect.Struct:
+		if c, ok := o.Interface().(ComplexObject); ok {
+			return unmarshalStruct(c, v)
+		}
+	case o.Type() == enumValueType && enums.byType(v.Type()) != nil:
+		return enums.byType(v.Type()).unmarshal(o.Interface().(Enum), v)
+	case isScalar(o.Kind()) && isScalar(v.Kind()):
+		return setScalar(v, o)
+	}
+	return errors.Errorf("failed to set value of type %s to %s", o.Type(), v.Type())
+}
+
+// setScalar stores number, string or bool o in v.
+// Integers are checked for overflow, floating-point numbers are not truncated to integers
+// and numbers are not converted to strings except of Char.
+func setScalar(v reflect.Value, o reflect.Value) error {
+	switch k := o.Kind(); v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int, reflect.Int64:
+		var n int64
+		switch {
+		case isInt(k):
+			n = o.Int()
+		case k == reflect.Uint8:
+			// Ignite byte is signed
+			n = int64(int8(o.Uint()))
+		case isUint(k) && o.Uint() <= 1<<63-1:
+			n = int64(o.Uint())
+		case isUint(k):
+			return errors.Errorf("value %d overflows %s", o.Uint(), v.Type())
+		default:
+			return errors.Errorf("failed to set value of type %s to %s", o.Type(), v.Type())
+		}
+		if v.OverflowInt(n) {
+			return errors.Errorf("value %d overflows %s", n, v.Type())
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		var u uint64
+		switch {
+		case isInt(k):
+			u = unsigned(o.Int(), o.Type().Bits())
+		case isUint(k):
+			u = o.Uint()
+		default:
+			return errors.Errorf("failed to set value of type %s to %s", o.Type(), v.Type())
+		}
+		if v.OverflowUint(u) {
+			return errors.Errorf("value %d overflows %s", u, v.Type())
+		}
+		v.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch {
+		case isInt(k):
+			f = float64(o.Int())
+		case isUint(k):
+			f = float64(o.Uint())
+		case k == reflect.Float32 || k == reflect.Float64:
+			f = o.Float()
+		default:
+			return errors.Errorf("failed to set value of type %s to %s", o.Type(), v.Type())
+		}
+		if v.OverflowFloat(f) {
+			return errors.Errorf("value %g overflows %s", f, v.Type())
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.String:
+		switch {
+		case k == reflect.String:
+			v.SetString(o.String())
+			return nil
+		case o.Type() == charType:
+			v.SetString(string(rune(o.Int())))
+			return nil
+		}
+	case reflect.Bool:
+		if k == reflect.Bool {
+			v.SetBool(o.Bool())
+			return nil
+		}
+	}
+	return errors.Errorf("failed to set value of type %s to %s", o.Type(), v.Type())
+}
+
+// unmarshalStruct stores fields of the complex object in the struct v
+func unmarshalStruct(c ComplexObject, v reflect.Value) error {
+	st, err := getStructType(v.Type())
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.Zero(v.Type()))
+	for id, o := range c.Fields {
+		f, ok := st.byID[id]
+		if !ok || o == nil {
+			continue
+		}
+		if err := setValue(fieldByIndex(v, f.index), reflect.ValueOf(o)); err != nil {
+			return errors.Wrapf(err, "failed to set field %s", f.name)
+		}
+	}
+	return nil
+}
+
+// isInt returns true if values of kind k are signed integers
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+// isUint returns true if values of kind k are unsigned integers
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+// isScalar returns true if values of kind k are numbers, strings or bools
+func isScalar(k reflect.Kind) bool {
+	return k >= reflect.Bool && k <= reflect.Float64 || k == reflect.String
+}
@@ -0,0 +1,323 @@
+package ignite
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testAddress struct {
+	City   string `ignite:"city"`
+	Street string `ignite:"street,omitempty"`
+}
+
+type testBase struct {
+	ID int64 `ignite:"id"`
+}
+
+type testPerson struct {
+	testBase
+	Name     string        `ignite:"name"`
+	Age      *int32        `ignite:"age"`
+	Born     time.Time     `ignite:"born,type=date"`
+	Updated  time.Time     `ignite:"updated"`
+	Initial  rune          `ignite:"initial,type=char"`
+	Key      uuid.UUID     `ignite:"key"`
+	Tags     []string      `ignite:"tags"`
+	Scores   []int         `ignite:"scores"`
+	Address  testAddress   `ignite:"address"`
+	Previous []testAddress `ignite:"previous"`
+	Parent   *testPerson   `ignite:"parent"`
+	Secret   string        `ignite:"-"`
+	hidden   string
+}
+
+type testNamed struct {
+	Value int32
+}
+
+func (testNamed) BinaryTypeName() string {
+	return "org.example.Named"
+}
+
+func TestWriteObject_Struct(t *testing.T) {
+	age := int32(30)
+	born := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	person := testPerson{
+		testBase: testBase{ID: 1},
+		Name:     "Ivan",
+		Age:      &age,
+		Born:     born,
+		Updated:  updated,
+		Initial:  'I',
+		Key:      uuid.New(),
+		Tags:     []string{"a", "b"},
+		Scores:   []int{1, 2},
+		Address:  testAddress{City: "Moscow"},
+		Previous: []testAddress{{City: "Kazan", Street: "Baumana"}},
+		Parent:   &testPerson{Name: "Petr", Born: born, Updated: updated},
+		Secret:   "secret",
+		hidden:   "hidden",
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want ComplexObject
+	}{
+		{
+			name: "nested",
+			v:    person.Address,
+			want: ComplexObject{Type: HashCode("testAddress"), Fields: map[int32]interface{}{HashCode("city"): "Moscow"}},
+		},
+		{
+			name: "type name",
+			v:    &testNamed{Value: 1},
+			want: ComplexObject{Type: HashCode("org.example.Named"), Fields: map[int32]interface{}{HashCode("Value"): int32(1)}},
+		},
+		{
+			name: "all fields",
+			v:    person,
+			want: ComplexObject{Type: HashCode("testPerson"), Fields: map[int32]interface{}{
+				HashCode("id"):      int64(1),
+				HashCode("name"):    "Ivan",
+				HashCode("age"):     int32(30),
+				HashCode("born"):    born,
+				HashCode("updated"): updated,
+				HashCode("initial"): Char('I'),
+				HashCode("key"):     person.Key,
+				HashCode("tags"):    []string{"a", "b"},
+				HashCode("scores"):  []int64{1, 2},
+				HashCode("address"): ComplexObject{Type: HashCode("testAddress"), Fields: map[int32]interface{}{
+					HashCode("city"): "Moscow",
+				}},
+				HashCode("previous"): []interface{}{ComplexObject{Type: HashCode("testAddress"), Fields: map[int32]interface{}{
+					HashCode("city"):   "Kazan",
+					HashCode("street"): "Baumana",
+				}}},
+				HashCode("parent"): ComplexObject{Type: HashCode("testPerson"), Fields: map[int32]interface{}{
+					HashCode("id"):       int64(0),
+					HashCode("name"):     "Petr",
+					HashCode("age"):      nil,
+					HashCode("born"):     born,
+					HashCode("updated"):  updated,
+					HashCode("initial"):  Char(0),
+					HashCode("key"):      uuid.UUID{},
+					HashCode("tags"):     nil,
+					HashCode("scores"):   nil,
+					HashCode("address"):  ComplexObject{Type: HashCode("testAddress"), Fields: map[int32]interface{}{HashCode("city"): ""}},
+					HashCode("previous"): nil,
+					HashCode("parent"):   nil,
+				}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := WriteObject(w, tt.v); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			got, err := ReadObject(w)
+			if err != nil {
+				t.Fatalf("ReadObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadObject() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadObjectInto(t *testing.T) {
+	age := int32(30)
+	person := testPerson{
+		testBase: testBase{ID: 1},
+		Name:     "Ivan",
+		Age:      &age,
+		Born:     time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC),
+		Updated:  time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Initial:  'I',
+		Key:      uuid.New(),
+		Tags:     []string{"a", "b"},
+		Scores:   []int{1, 2},
+		Address:  testAddress{City: "Moscow"},
+		Previous: []testAddress{{City: "Kazan", Street: "Baumana"}},
+		Parent:   &testPerson{Name: "Petr", Updated: time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		dst     interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "struct",
+			v:    person,
+			dst:  &testPerson{},
+			want: &person,
+		},
+		{
+			name: "pointer to struct",
+			v:    &person.Address,
+			dst:  new(*testAddress),
+			want: func() interface{} { a := &person.Address; return &a }(),
+		},
+		{
+			name: "complex object into struct",
+			v: ComplexObject{Type: HashCode("testAddress"), Fields: map[int32]interface{}{
+				HashCode("city"):    "Moscow",
+				HashCode("unknown"): int32(1),
+			}},
+			dst:  &testAddress{Street: "old"},
+			want: &testAddress{City: "Moscow"},
+		},
+		{
+			name: "number conversion",
+			v:    int32(10),
+			dst:  new(int64),
+			want: func() interface{} { v := int64(10); return &v }(),
+		},
+		{
+			name:    "integer overflow",
+			v:       int32(300),
+			dst:     new(int8),
+			want:    new(int8),
+			wantErr: true,
+		},
+		{
+			name:    "negative integer overflows unsigned",
+			v:       int32(-1),
+			dst:     new(uint8),
+			want:    new(uint8),
+			wantErr: true,
+		},
+		{
+			name: "unsigned integer",
+			v:    uint32(4000000000),
+			dst:  new(uint32),
+			want: func() interface{} { v := uint32(4000000000); return &v }(),
+		},
+		{
+			name: "unsigned byte",
+			v:    uint8(200),
+			dst:  new(uint8),
+			want: func() interface{} { v := uint8(200); return &v }(),
+		},
+		{
+			name: "integer into float",
+			v:    int32(10),
+			dst:  new(float64),
+			want: func() interface{} { v := float64(10); return &v }(),
+		},
+		{
+			name:    "float into integer",
+			v:       1.5,
+			dst:     new(int64),
+			want:    new(int64),
+			wantErr: true,
+		},
+		{
+			name:    "float overflow",
+			v:       1e300,
+			dst:     new(float32),
+			want:    new(float32),
+			wantErr: true,
+		},
+		{
+			name:    "integer into string",
+			v:       int32(65),
+			dst:     new(string),
+			want:    new(string),
+			wantErr: true,
+		},
+		{
+			name: "char into string",
+			v:    Char('A'),
+			dst:  new(string),
+			want: func() interface{} { v := "A"; return &v }(),
+		},
+		{
+			name: "null",
+			v:    nil,
+			dst:  func() interface{} { v := &age; return &v }(),
+			want: new(*int32),
+		},
+		{
+			name: "object array into interface",
+			v:    []testAddress{{City: "Kazan"}},
+			dst:  new(interface{}),
+			want: func() interface{} {
+				var v interface{} = []interface{}{ComplexObject{Type: HashCode("testAddress"), Fields: map[int32]interface{}{
+					HashCode("city"): "Kazan",
+				}}}
+				return &v
+			}(),
+		},
+		{
+			name:    "type mismatch",
+			v:       "string",
+			dst:     new(int32),
+			want:    new(int32),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := WriteObject(w, tt.v); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			if err := ReadObjectInto(w, tt.dst); (err != nil) != tt.wantErr {
+				t.Fatalf("ReadObjectInto() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("ReadObjectInto() = %#v, want %#v", tt.dst, tt.want)
+			}
+
+			// the same value is unmarshalled from the object read by ReadObject
+			if err := WriteObject(w, tt.v); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			o, err := ReadObject(w)
+			if err != nil {
+				t.Fatalf("ReadObject() error = %v", err)
+			}
+			dst := reflect.New(reflect.TypeOf(tt.dst).Elem())
+			if err = UnmarshalObject(o, dst.Interface()); (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(dst.Interface(), tt.want) {
+				t.Errorf("UnmarshalObject() = %#v, want %#v", dst.Interface(), tt.want)
+			}
+		})
+	}
+
+	if err := ReadObjectInto(&bytes.Buffer{}, testPerson{}); err == nil {
+		t.Errorf("ReadObjectInto() error = nil for not pointer destination")
+	}
+}
+
+func TestReadObjectInto_InvalidLength(t *testing.T) {
+	// object array of 2^30 elements in the message of 13 bytes
+	b := []byte{typeObjectArray, 0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0x40, typeString, 1, 0, 0}
+	readers := map[string]io.Reader{
+		"decoder": &decoder{b: b},
+		"reader":  bytes.NewReader(b),
+		// length of the message is unknown
+		"unsized": struct{ io.Reader }{bytes.NewReader(b)},
+	}
+	for name, r := range readers {
+		var dst []testAddress
+		if err := ReadObjectInto(r, &dst); err == nil {
+			t.Errorf("ReadObjectInto() from %s = %v, want error", name, dst)
+		}
+	}
+}
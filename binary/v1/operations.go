@@ -85,6 +85,34 @@ const (
 	// OpResourceClose closes a resource, such as query cursor.
 	OpResourceClose = 0
 
+	// Binary Types
+
+	// OpGetBinaryTypeName gets the platform-specific full binary type name by ID.
+	OpGetBinaryTypeName = 3000
+	// OpRegisterBinaryTypeName registers the platform-specific full binary type name for the specified type ID.
+	OpRegisterBinaryTypeName = 3001
+	// OpGetBinaryType gets the binary type information by ID.
+	OpGetBinaryType = 3002
+	// OpPutBinaryType registers the binary type information in the cluster.
+	OpPutBinaryType = 3003
+
+	// Partition Awareness
+
+	// OpCachePartitions gets partition to node mapping of the caches (protocol version 1.4.0 and above).
+	OpCachePartitions = 1101
+
+	// Transactions
+
+	// OpTxStart starts the transaction (protocol version 1.5.0 and above).
+	OpTxStart = 4000
+	// OpTxEnd commits or rolls back the transaction (protocol version 1.5.0 and above).
+	OpTxEnd = 4001
+
+	// Connection
+
+	// OpHeartbeat checks the connection is alive (FeatureHeartbeat is required).
+	OpHeartbeat = 1
+
 	// flags
 	KeepBinaryFlagMask       = 0x01
 	TransactionalFlagMask    = 0x02
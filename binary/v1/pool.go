@@ -0,0 +1,559 @@
+package ignite
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// DefaultPoolMaxConnections is default maximum count of open connections in the pool
+const DefaultPoolMaxConnections = 10
+
+// PoolConfig contains connection pool parameters
+type PoolConfig struct {
+	// Connection parameters
+	ConnInfo
+
+	// MinConnections is count of connections which are kept open even if they are idle.
+	MinConnections int
+
+	// MaxConnections is maximum count of open connections.
+	// DefaultPoolMaxConnections is used if value is not positive.
+	MaxConnections int
+
+	// IdleTimeout is time after which idle connection is closed.
+	// Zero value disables closing of idle connections.
+	IdleTimeout time.Duration
+
+	// MaxLifetime is maximum time connection may be reused.
+	// Zero value means connection is reused forever.
+	MaxLifetime time.Duration
+
+	// HealthCheckPeriod is period of connection inactivity after which
+	// the connection is checked by request to the server before it is borrowed.
+	// Zero value disables health checks.
+	HealthCheckPeriod time.Duration
+
+	// BorrowTimeout is maximum time to wait for free connection.
+	// Zero value means to wait until operation context is done.
+	BorrowTimeout time.Duration
+}
+
+// pooledConnection is connection owned by the pool
+type pooledConnection struct {
+	*connection
+
+	created  time.Time
+	released time.Time
+	// count of open cursors owned by the connection
+	cursors int
+}
+
+// pool is transport which borrows connection from the pool for every operation.
+// Cursor operations are sent over the connection which owns the cursor.
+type pool struct {
+	config PoolConfig
+	// every borrowed connection and every connection being dialed holds a slot
+	slots chan struct{}
+	done  chan struct{}
+
+	mutex   sync.Mutex
+	idle    []*pooledConnection
+	open    int
+	cursors map[int64]*pooledConnection
+	// borrowed connections are closed when the pool is closed
+	borrowed map[*pooledConnection]struct{}
+	closed   bool
+	// index of endpoint of the last opened connection
+	active int
+	// handshake parameters of the last opened connection
+	last session
+}
+
+// ConnectPool creates client with connection pool.
+// Every operation borrows connection from the pool,
+// cursor operations use the connection which owns the cursor.
+func ConnectPool(pc PoolConfig) (Client, error) {
+	return ConnectPoolContext(context.Background(), pc)
+}
+
+// ConnectPoolContext creates client with connection pool.
+// ctx is used to cancel or limit duration of opening the MinConnections connections.
+func ConnectPoolContext(ctx context.Context, pc PoolConfig) (Client, error) {
+	p, err := newPool(ctx, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{conn: p, debugID: pc.debugID(), retries: len(pc.endpoints()), compactFooter: pc.CompactFooter}
+	runtime.SetFinalizer(c, clientFinalizer)
+
+	return c, nil
+}
+
+// newPool creates pool and opens MinConnections connections
+func newPool(ctx context.Context, pc PoolConfig) (*pool, error) {
+	if pc.MaxConnections <= 0 {
+		pc.MaxConnections = DefaultPoolMaxConnections
+	}
+	if pc.MinConnections > pc.MaxConnections {
+		return nil, errors.Errorf("min connections %d is greater than max connections %d",
+			pc.MinConnections, pc.MaxConnections)
+	}
+
+	p := &pool{
+		config:   pc,
+		slots:    make(chan struct{}, pc.MaxConnections),
+		done:     make(chan struct{}),
+		cursors:  map[int64]*pooledConnection{},
+		borrowed: map[*pooledConnection]struct{}{},
+	}
+	for i := 0; i < pc.MinConnections; i++ {
+		conn, err := p.dial(ctx)
+		if err != nil {
+			p.close()
+			return nil, errors.Wrapf(err, "failed to open connection with index %d", i)
+		}
+		now := time.Now()
+		p.idle = append(p.idle, &pooledConnection{connection: conn, created: now, released: now})
+		p.open++
+	}
+
+	go p.maintain()
+
+	return p, nil
+}
+
+// do sends request over borrowed connection or over the connection which owns the cursor
+func (p *pool) do(ctx context.Context, message []byte) ([]byte, error) {
+	if len(message) < requestHeaderLength {
+		return nil, errors.Errorf("invalid operation request length %d", len(message))
+	}
+	code := int16(binary.LittleEndian.Uint16(message[4:]))
+
+	if isCursorOperation(code) && len(message) >= requestHeaderLength+8 {
+		id := int64(binary.LittleEndian.Uint64(message[requestHeaderLength:]))
+		if pc := p.cursor(id); pc != nil {
+			frame, err := pc.do(ctx, message)
+			if (err == nil && (code == OpResourceClose || !cursorHasMore(frame))) || !pc.alive() {
+				p.unpin(id)
+			}
+			return frame, err
+		}
+	}
+
+	pc, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := pc.do(ctx, message)
+	if err == nil && opensCursor(code) && cursorHasMore(frame) {
+		if data, _ := operationResponseData(frame); len(data) >= 8 {
+			p.pin(int64(binary.LittleEndian.Uint64(data)), pc)
+		}
+	}
+	p.release(pc)
+
+	return frame, err
+}
+
+// stream sends request like do and calls fn with reader of the response message.
+// The response is buffered because cursors are tracked by the response content.
+func (p *pool) stream(ctx context.Context, message []byte, fn func(r io.Reader) error) error {
+	frame, err := p.do(ctx, message)
+	if err != nil {
+		return err
+	}
+	return fn(bytes.NewReader(frame))
+}
+
+// bind borrows connection which is used by the returned transport until it is closed.
+// Operations of the transaction are sent over the connection the transaction is started on.
+func (p *pool) bind(ctx context.Context) (transport, error) {
+	pc, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &boundConnection{pooledConnection: pc, pool: p}, nil
+}
+
+// dial opens connection starting from endpoint of the last opened connection.
+// Dialing is interrupted when the pool is closed.
+func (p *pool) dial(ctx context.Context) (*connection, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	p.mutex.Lock()
+	first := p.active
+	p.mutex.Unlock()
+
+	conn, active, err := dial(ctx, p.config.ConnInfo, first)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	p.active, p.last = active, conn.session
+	p.mutex.Unlock()
+
+	return conn, nil
+}
+
+// endpoint returns endpoint of the last opened connection
+func (p *pool) endpoint() Endpoint {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.config.endpoints()[p.active]
+}
+
+// session returns parameters negotiated with the node of the last opened connection
+func (p *pool) session() session {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.last
+}
+
+// alive returns true if pool is not closed
+func (p *pool) alive() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return !p.closed
+}
+
+// state returns StateReady if pool is not closed, connections are opened on demand
+func (p *pool) state() ConnectionState {
+	if !p.alive() {
+		return StateClosed
+	}
+	return StateReady
+}
+
+// close closes idle connections and borrowed connections, requests in progress fail with ErrClientClosed.
+// Borrowed connections are discarded when they are returned to the pool.
+func (p *pool) close() error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	idle := p.idle
+	p.idle = nil
+	p.cursors = map[int64]*pooledConnection{}
+	p.open -= len(idle)
+	for pc := range p.borrowed {
+		idle = append(idle, pc)
+	}
+	p.mutex.Unlock()
+
+	var err error
+	for _, pc := range idle {
+		if cerr := pc.close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// acquire borrows connection from the pool.
+// It waits for free slot until BorrowTimeout is elapsed or ctx is done.
+func (p *pool) acquire(ctx context.Context) (*pooledConnection, error) {
+	if p.config.BorrowTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.BorrowTimeout)
+		defer cancel()
+	}
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.done:
+		return nil, ErrClientClosed
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "failed to borrow connection from pool")
+	}
+
+	for {
+		p.mutex.Lock()
+		if p.closed {
+			p.mutex.Unlock()
+			<-p.slots
+			return nil, ErrClientClosed
+		}
+		if len(p.idle) == 0 {
+			p.open++
+			p.mutex.Unlock()
+			break
+		}
+		pc := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.borrowed[pc] = struct{}{}
+		usable := p.usable(pc, time.Now())
+		p.mutex.Unlock()
+
+		if !usable {
+			p.discard(pc)
+			continue
+		}
+		if p.config.HealthCheckPeriod > 0 && time.Since(pc.released) >= p.config.HealthCheckPeriod {
+			if err := pc.ping(ctx); err != nil {
+				if ctx.Err() != nil {
+					p.release(pc)
+					return nil, errors.Wrapf(ctx.Err(), "failed to borrow connection from pool")
+				}
+				p.discard(pc)
+				continue
+			}
+		}
+		return pc, nil
+	}
+
+	conn, err := p.dial(ctx)
+	if err != nil {
+		p.mutex.Lock()
+		p.open--
+		closed := p.closed
+		p.mutex.Unlock()
+		<-p.slots
+		if closed {
+			return nil, ErrClientClosed
+		}
+		return nil, err
+	}
+	pc := &pooledConnection{connection: conn, created: time.Now()}
+	p.mutex.Lock()
+	if p.closed {
+		p.open--
+		p.mutex.Unlock()
+		<-p.slots
+		_ = conn.close()
+		return nil, ErrClientClosed
+	}
+	p.borrowed[pc] = struct{}{}
+	p.mutex.Unlock()
+	return pc, nil
+}
+
+// release returns borrowed connection to the pool
+func (p *pool) release(pc *pooledConnection) {
+	defer func() { <-p.slots }()
+
+	now := time.Now()
+	p.mutex.Lock()
+	delete(p.borrowed, pc)
+	if !p.closed && p.usable(pc, now) {
+		pc.released = now
+		p.idle = append(p.idle, pc)
+		p.mutex.Unlock()
+		return
+	}
+	p.mutex.Unlock()
+	p.discard(pc)
+}
+
+// usable returns true if connection is alive and its lifetime is not expired.
+// Connection which owns open cursors is used until the cursors are closed.
+// It must be called with p.mutex held because count of the cursors is changed under it.
+func (p *pool) usable(pc *pooledConnection, now time.Time) bool {
+	if !pc.alive() {
+		return false
+	}
+	return p.config.MaxLifetime <= 0 || pc.cursors > 0 || now.Sub(pc.created) < p.config.MaxLifetime
+}
+
+// discard closes connection which is not returned to the pool
+func (p *pool) discard(pc *pooledConnection) {
+	p.mutex.Lock()
+	p.open--
+	delete(p.borrowed, pc)
+	for id, owner := range p.cursors {
+		if owner == pc {
+			delete(p.cursors, id)
+		}
+	}
+	p.mutex.Unlock()
+	_ = pc.close()
+}
+
+// cursor returns connection which owns the cursor
+func (p *pool) cursor(id int64) *pooledConnection {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.cursors[id]
+}
+
+// pin binds the cursor to the connection
+func (p *pool) pin(id int64, pc *pooledConnection) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.closed {
+		p.cursors[id] = pc
+		pc.cursors++
+	}
+}
+
+// unpin removes binding of the closed cursor
+func (p *pool) unpin(id int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if pc, ok := p.cursors[id]; ok {
+		delete(p.cursors, id)
+		pc.cursors--
+	}
+}
+
+// minMaintainInterval is the minimum interval of the pool maintenance
+const minMaintainInterval = time.Millisecond
+
+// maintain closes expired idle connections and opens MinConnections connections
+func (p *pool) maintain() {
+	interval := time.Second
+	for _, d := range []time.Duration{p.config.IdleTimeout, p.config.MaxLifetime} {
+		if d > 0 && d/2 < interval {
+			interval = d / 2
+		}
+	}
+	if interval < minMaintainInterval {
+		interval = minMaintainInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return
+		}
+		p.evict()
+		p.fill()
+	}
+}
+
+// evict closes idle connections which are broken or expired
+func (p *pool) evict() {
+	now := time.Now()
+	var expired []*pooledConnection
+
+	p.mutex.Lock()
+	idle := p.idle[:0]
+	for _, pc := range p.idle {
+		switch {
+		case !p.usable(pc, now):
+			expired = append(expired, pc)
+		case pc.cursors == 0 && p.config.IdleTimeout > 0 && now.Sub(pc.released) >= p.config.IdleTimeout &&
+			p.open-len(expired) > p.config.MinConnections:
+			expired = append(expired, pc)
+		default:
+			idle = append(idle, pc)
+		}
+	}
+	p.idle = idle
+	p.mutex.Unlock()
+
+	for _, pc := range expired {
+		p.discard(pc)
+	}
+}
+
+// fill opens connections until MinConnections connections are open
+func (p *pool) fill() {
+	for {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			return
+		}
+
+		p.mutex.Lock()
+		if p.closed || p.open >= p.config.MinConnections {
+			p.mutex.Unlock()
+			<-p.slots
+			return
+		}
+		p.open++
+		p.mutex.Unlock()
+
+		conn, err := p.dial(context.Background())
+		if err != nil {
+			p.mutex.Lock()
+			p.open--
+			p.mutex.Unlock()
+			<-p.slots
+			return
+		}
+		p.release(&pooledConnection{connection: conn, created: time.Now()})
+	}
+}
+
+// isCursorOperation returns true if operation request starts with cursor ID
+func isCursorOperation(code int16) bool {
+	switch code {
+	case OpQuerySQLCursorGetPage, OpQuerySQLFieldsCursorGetPage, OpQueryScanCursorGetPage, OpResourceClose:
+		return true
+	default:
+		return false
+	}
+}
+
+// opensCursor returns true if operation response starts with ID of the opened cursor
+func opensCursor(code int16) bool {
+	switch code {
+	case OpQuerySQL, OpQuerySQLFields, OpQueryScan:
+		return true
+	default:
+		return false
+	}
+}
+
+// cursorHasMore returns true if successful query response message ends with "has more" flag set,
+// it means the cursor is still open on the server.
+func cursorHasMore(frame []byte) bool {
+	data, ok := operationResponseData(frame)
+	return ok && len(data) > 0 && data[len(data)-1] == 1
+}
+
+// boundConnection is transport which sends requests over the single connection borrowed from the pool.
+// Closing the transport returns the connection to the pool.
+type boundConnection struct {
+	*pooledConnection
+	pool *pool
+	once sync.Once
+}
+
+// state returns StateReady if the connection is alive, StateBroken otherwise
+func (b *boundConnection) state() ConnectionState {
+	if b.alive() {
+		return StateReady
+	}
+	return StateBroken
+}
+
+// endpoint returns endpoint of the last opened connection of the pool
+func (b *boundConnection) endpoint() Endpoint {
+	return b.pool.endpoint()
+}
+
+// session returns parameters negotiated over the connection
+func (b *boundConnection) session() session {
+	return b.connection.session
+}
+
+// close returns the connection to the pool
+func (b *boundConnection) close() error {
+	b.once.Do(func() {
+		b.pool.release(b.pooledConnection)
+	})
+	return nil
+}
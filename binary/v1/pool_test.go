@@ -0,0 +1,280 @@
+package ignite
+
+import (
+	"context"
+	"encoding/binary"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnectPool_BorrowTimeout(t *testing.T) {
+	release := make(chan struct{})
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		<-release
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+
+	c, err := ConnectPool(PoolConfig{ConnInfo: s.connInfo(), MaxConnections: 1, BorrowTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("ConnectPool() error = %v", err)
+	}
+	defer c.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := c.CacheGetNames()
+		done <- err
+	}()
+	// wait until the only connection is borrowed
+	for s.accepted() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	start := time.Now()
+	_, err = c.CacheGetNames()
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CacheGetNames() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("CacheGetNames() returned after %v", d)
+	}
+
+	close(release)
+	if err = <-done; err != nil {
+		t.Errorf("CacheGetNames() error = %v", err)
+	}
+	if _, err = c.CacheGetNames(); err != nil {
+		t.Errorf("CacheGetNames() error = %v", err)
+	}
+	if s.accepted() != 1 {
+		t.Errorf("pool opened %d connections, want 1", s.accepted())
+	}
+}
+
+func TestConnectPool_Cursor(t *testing.T) {
+	const count = 3
+	var wg sync.WaitGroup
+	wg.Add(count)
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		id := int64(100 + conn)
+		switch code {
+		case OpQueryScan:
+			// hold connections until all queries are started to use different connections
+			wg.Done()
+			wg.Wait()
+			return append(appendInt64(nil, id), 0, 0, 0, 0, 1), nil
+		case OpQueryScanCursorGetPage, OpResourceClose:
+			if got := int64(binary.LittleEndian.Uint64(data)); got != id {
+				return nil, fmt.Errorf("cursor %d is not found", got)
+			}
+			if code == OpResourceClose {
+				return nil, nil
+			}
+			return []byte{0, 0, 0, 0, 1}, nil
+		default:
+			return nil, fmt.Errorf("unexpected operation %d", code)
+		}
+	})
+	defer s.close()
+
+	c, err := ConnectPool(PoolConfig{ConnInfo: s.connInfo(), MaxConnections: count})
+	if err != nil {
+		t.Fatalf("ConnectPool() error = %v", err)
+	}
+	defer c.Close()
+
+	ids := make(chan int64, count)
+	for i := 0; i < count; i++ {
+		go func() {
+			r, err := c.QueryScan("cache", false, QueryScanData{PageSize: 1})
+			if err != nil {
+				t.Errorf("QueryScan() error = %v", err)
+			}
+			ids <- r.ID
+		}()
+	}
+	for i := 0; i < count; i++ {
+		id := <-ids
+		// get page from every cursor several times to borrow connections in different order
+		for j := 0; j < 2; j++ {
+			if _, err := c.QueryScanCursorGetPage(id); err != nil {
+				t.Errorf("QueryScanCursorGetPage(%d) error = %v", id, err)
+			}
+		}
+		if err := c.ResourceClose(id); err != nil {
+			t.Errorf("ResourceClose(%d) error = %v", id, err)
+		}
+	}
+
+	p := c.(*client).conn.(*pool)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.cursors) != 0 {
+		t.Errorf("pool has %d pinned cursors after they are closed", len(p.cursors))
+	}
+}
+
+func TestConnectPool_CursorConcurrent(t *testing.T) {
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		switch code {
+		case OpQueryScan:
+			return append(appendInt64(nil, 42), 0, 0, 0, 0, 1), nil
+		case OpResourceClose:
+			return nil, nil
+		default:
+			return []byte{0, 0, 0, 0}, nil
+		}
+	})
+	defer s.close()
+
+	// cursors are opened and closed on the connection while it is borrowed by other operations
+	c, err := ConnectPool(PoolConfig{ConnInfo: s.connInfo(), MaxConnections: 1, MaxLifetime: time.Hour})
+	if err != nil {
+		t.Fatalf("ConnectPool() error = %v", err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			r, err := c.QueryScan("cache", false, QueryScanData{PageSize: 1})
+			if err != nil {
+				t.Errorf("QueryScan() error = %v", err)
+				return
+			}
+			if err = c.ResourceClose(r.ID); err != nil {
+				t.Errorf("ResourceClose(%d) error = %v", r.ID, err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if _, err := c.CacheGetNames(); err != nil {
+				t.Errorf("CacheGetNames() error = %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestConnectPool_ShortTimeouts(t *testing.T) {
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+
+	c, err := ConnectPool(PoolConfig{ConnInfo: s.connInfo(), MaxConnections: 1,
+		IdleTimeout: time.Nanosecond, MaxLifetime: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("ConnectPool() error = %v", err)
+	}
+	defer c.Close()
+
+	if _, err = c.CacheGetNames(); err != nil {
+		t.Errorf("CacheGetNames() error = %v", err)
+	}
+}
+
+func TestConnectPool_IdleTimeout(t *testing.T) {
+	release := make(chan struct{})
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		<-release
+		return []byte{0, 0, 0, 0}, nil
+	})
+	defer s.close()
+
+	c, err := ConnectPool(PoolConfig{ConnInfo: s.connInfo(), MinConnections: 2, MaxConnections: 4,
+		IdleTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("ConnectPool() error = %v", err)
+	}
+	defer c.Close()
+	p := c.(*client).conn.(*pool)
+
+	if s.accepted() != 2 {
+		t.Errorf("pool opened %d connections, want 2", s.accepted())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.CacheGetNames(); err != nil {
+				t.Errorf("CacheGetNames() error = %v", err)
+			}
+		}()
+	}
+	for s.accepted() < 4 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		p.mutex.Lock()
+		open := p.open
+		p.mutex.Unlock()
+		if open == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool has %d open connections, want 2", open)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConnectPool_Close(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan struct{}, 1)
+	s := newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		received <- struct{}{}
+		<-release
+		return nil, errTestDrop
+	})
+	defer s.close()
+	defer close(release)
+
+	c, err := ConnectPool(PoolConfig{ConnInfo: s.connInfo(), MaxConnections: 2})
+	if err != nil {
+		t.Fatalf("ConnectPool() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.CacheGetNames()
+		done <- err
+	}()
+	<-received
+
+	if err = c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	// request over the borrowed connection is failed
+	select {
+	case err = <-done:
+		if !stderrors.Is(err, ErrClientClosed) {
+			t.Errorf("CacheGetNames() error = %v, want %v", err, ErrClientClosed)
+		}
+	default:
+		t.Errorf("CacheGetNames() is not finished when Close() returns")
+	}
+	if _, err = c.CacheGetNames(); !stderrors.Is(err, ErrClientClosed) {
+		t.Errorf("CacheGetNames() error = %v, want %v", err, ErrClientClosed)
+	}
+	if got := c.State(); got != StateClosed {
+		t.Errorf("State() = %v, want %v", got, StateClosed)
+	}
+}
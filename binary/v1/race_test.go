@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package ignite
+
+// raceEnabled is true if the tests are run with race detector which makes sync.Pool drop items randomly
+const raceEnabled = true
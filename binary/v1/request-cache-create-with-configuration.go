@@ -1,7 +1,6 @@
 package ignite
 
 import (
-	"encoding/binary"
 	"io"
 
 	"github.com/amsokol/ignite-go-client/binary/errors"
@@ -18,34 +17,32 @@ type RequestCacheCreateWithConfiguration struct {
 // Returns written bytes.
 func (r *RequestCacheCreateWithConfiguration) WriteTo(w io.Writer) (int64, error) {
 	// write payload length
-	l := int32(2 + 8 + 4 + 2 + r.payload.Len())
-	if err := binary.Write(w, binary.LittleEndian, &l); err != nil {
+	if err := writeUint32(w, uint32(2+8+4+2+r.length())); err != nil {
 		return 0, errors.Wrapf(err, "failed to write operation request length")
 	}
 
 	// write operation code
-	if err := binary.Write(w, binary.LittleEndian, &r.Code); err != nil {
+	if err := writeUint16(w, uint16(r.Code)); err != nil {
 		return 0, errors.Wrapf(err, "failed to write operation code")
 	}
 
 	// write operation request id
-	if err := binary.Write(w, binary.LittleEndian, &r.UID); err != nil {
+	if err := writeUint64(w, uint64(r.UID)); err != nil {
 		return 0, errors.Wrapf(err, "failed to write operation request id")
 	}
 
 	// write data length
-	l = int32(r.payload.Len())
-	if err := binary.Write(w, binary.LittleEndian, &l); err != nil {
+	if err := writeUint32(w, uint32(r.length())); err != nil {
 		return 0, errors.Wrapf(err, "failed to write data length")
 	}
 
 	// write params count
-	if err := binary.Write(w, binary.LittleEndian, &r.Count); err != nil {
+	if err := writeUint16(w, uint16(r.Count)); err != nil {
 		return 0, errors.Wrapf(err, "failed to write params count")
 	}
 
 	// write payload
-	n, err := r.payload.WriteTo(w)
+	n, err := r.request.WriteTo(w)
 	return 4 + 2 + 8 + 4 + 2 + n, err
 }
 
@@ -1,7 +1,6 @@
 package ignite
 
 import (
-	"encoding/binary"
 	"io"
 
 	"github.com/amsokol/ignite-go-client/binary/errors"
@@ -11,6 +10,10 @@ import (
 type RequestHandshake struct {
 	major, minor, patch int
 	username, password  string
+	// user attributes (protocol version 1.5.0 and above)
+	attributes map[string]string
+	// features supported by the client (protocol version 1.7.0 and above)
+	features Features
 
 	request
 }
@@ -33,16 +36,28 @@ func (r *RequestHandshake) WriteTo(w io.Writer) (int64, error) {
 	if err := WriteByte(r, 2); err != nil {
 		return 0, errors.Wrapf(err, "failed to write handshake client code")
 	}
-	if err := WriteOString(r, r.username); err != nil {
-		return 0, errors.Wrapf(err, "failed to write handshake username")
+	version := ProtocolVersion{Major: r.major, Minor: r.minor, Patch: r.patch}
+	if !version.Less(featuresVersion) {
+		if err := WriteOArrayBytes(r, r.features); err != nil {
+			return 0, errors.Wrapf(err, "failed to write handshake features")
+		}
 	}
-	if err := WriteOString(r, r.password); err != nil {
-		return 0, errors.Wrapf(err, "failed to write handshake password")
+	if !version.Less(userAttributesVersion) {
+		if err := writeUserAttributes(r, r.attributes); err != nil {
+			return 0, errors.Wrapf(err, "failed to write handshake user attributes")
+		}
+	}
+	if !version.Less(credentialsVersion) {
+		if err := WriteOString(r, r.username); err != nil {
+			return 0, errors.Wrapf(err, "failed to write handshake username")
+		}
+		if err := WriteOString(r, r.password); err != nil {
+			return 0, errors.Wrapf(err, "failed to write handshake password")
+		}
 	}
 
 	// write payload length
-	l := int32(r.payload.Len())
-	if err := binary.Write(w, binary.LittleEndian, &l); err != nil {
+	if err := writeUint32(w, uint32(r.length())); err != nil {
 		return 0, errors.Wrapf(err, "failed to write handshake request length")
 	}
 	// write request
@@ -55,3 +70,37 @@ func NewRequestHandshake(major, minor, patch int, username, password string) *Re
 	return &RequestHandshake{request: newRequest(),
 		major: major, minor: minor, patch: patch, username: username, password: password}
 }
+
+// NewRequestHandshakeAttributes creates new handshake request object with user attributes
+// (protocol version 1.5.0 and above) and features supported by the client (protocol version 1.7.0 and above)
+func NewRequestHandshakeAttributes(major, minor, patch int, username, password string,
+	attributes map[string]string, features Features) *RequestHandshake {
+	r := NewRequestHandshake(major, minor, patch, username, password)
+	r.attributes, r.features = attributes, features
+	return r
+}
+
+// writeUserAttributes writes user attributes as map of strings, NULL is written if there are no attributes
+func writeUserAttributes(w io.Writer, attributes map[string]string) error {
+	if len(attributes) == 0 {
+		return WriteNull(w)
+	}
+	if err := WriteType(w, typeMap); err != nil {
+		return err
+	}
+	if err := WriteInt(w, int32(len(attributes))); err != nil {
+		return err
+	}
+	if err := WriteByte(w, mapKindHashMap); err != nil {
+		return err
+	}
+	for k, v := range attributes {
+		if err := WriteOString(w, k); err != nil {
+			return err
+		}
+		if err := WriteOString(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
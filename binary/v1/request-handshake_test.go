@@ -16,12 +16,28 @@ func TestRequestHandshake_WriteTo(t *testing.T) {
 	}{
 		{
 			name: "1",
-			r:    NewRequestHandshake(1, 0, 0, "ignite", "ignite"),
+			r:    NewRequestHandshake(1, 1, 0, "ignite", "ignite"),
 			want: 4 + 8 + 22,
-			wantW: []byte{0x1e, 0x0, 0x0, 0x0, 0x1, 0x1, 0x0, 0x0, 0x0, 0x0, 0x0, 0x2,
+			wantW: []byte{0x1e, 0x0, 0x0, 0x0, 0x1, 0x1, 0x0, 0x1, 0x0, 0x0, 0x0, 0x2,
 				0x9, 0x6, 0x0, 0x0, 0x0, 0x69, 0x67, 0x6e, 0x69, 0x74, 0x65, 0x9, 0x6,
 				0x0, 0x0, 0x0, 0x69, 0x67, 0x6e, 0x69, 0x74, 0x65},
 		},
+		{
+			name:  "2",
+			r:     NewRequestHandshake(1, 0, 0, "ignite", "ignite"),
+			want:  4 + 8,
+			wantW: []byte{0x8, 0x0, 0x0, 0x0, 0x1, 0x1, 0x0, 0x0, 0x0, 0x0, 0x0, 0x2},
+		},
+		{
+			name: "3",
+			r: NewRequestHandshakeAttributes(1, 7, 0, "u", "p",
+				map[string]string{"a": "b"}, newFeatures(FeatureUserAttributes)),
+			want: 4 + 8 + 6 + 18 + 12,
+			wantW: []byte{0x2c, 0x0, 0x0, 0x0, 0x1, 0x1, 0x0, 0x7, 0x0, 0x0, 0x0, 0x2,
+				0xc, 0x1, 0x0, 0x0, 0x0, 0x1,
+				0x19, 0x1, 0x0, 0x0, 0x0, 0x1, 0x9, 0x1, 0x0, 0x0, 0x0, 0x61, 0x9, 0x1, 0x0, 0x0, 0x0, 0x62,
+				0x9, 0x1, 0x0, 0x0, 0x0, 0x75, 0x9, 0x1, 0x0, 0x0, 0x0, 0x70},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1,7 +1,6 @@
 package ignite
 
 import (
-	"encoding/binary"
 	"io"
 	"math/rand"
 
@@ -20,18 +19,17 @@ type RequestOperation struct {
 // Returns written bytes.
 func (r *RequestOperation) WriteTo(w io.Writer) (int64, error) {
 	// write payload length
-	l := int32(2 + 8 + r.payload.Len())
-	if err := binary.Write(w, binary.LittleEndian, &l); err != nil {
+	if err := writeUint32(w, uint32(2+8+r.length())); err != nil {
 		return 0, errors.Wrapf(err, "failed to write operation request length")
 	}
 
 	// write operation code
-	if err := binary.Write(w, binary.LittleEndian, &r.Code); err != nil {
+	if err := writeUint16(w, uint16(r.Code)); err != nil {
 		return 0, errors.Wrapf(err, "failed to write operation code")
 	}
 
 	// write operation request id
-	if err := binary.Write(w, binary.LittleEndian, &r.UID); err != nil {
+	if err := writeUint64(w, uint64(r.UID)); err != nil {
 		return 0, errors.Wrapf(err, "failed to write operation request id")
 	}
 
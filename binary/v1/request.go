@@ -12,9 +12,13 @@ type Request interface {
 	WriteTo(w io.Writer) (int64, error)
 }
 
-// request is struct is implementing base message request functionality
+// request is struct is implementing base message request functionality.
+// Payload buffer is taken from the pool on the first write and returned to the pool when it is written out.
 type request struct {
 	payload *bytes.Buffer
+	// schemas are schemas registered in the cluster the request is sent to,
+	// it is nil if compact footers are disabled
+	schemas *registeredSchemas
 
 	Request
 	io.Writer
@@ -23,7 +27,13 @@ type request struct {
 // WriteTo is function to write request data to io.Writer.
 // Returns written bytes.
 func (r *request) WriteTo(w io.Writer) (int64, error) {
-	return r.payload.WriteTo(w)
+	if r.payload == nil {
+		return 0, nil
+	}
+	n, err := r.payload.WriteTo(w)
+	putBuffer(r.payload)
+	r.payload = nil
+	return n, err
 }
 
 // Write writes len(p) bytes from p to the underlying data stream.
@@ -34,10 +44,31 @@ func (r *request) WriteTo(w io.Writer) (int64, error) {
 //
 // Implementations must not retain p.
 func (r *request) Write(p []byte) (n int, err error) {
-	return r.payload.Write(p)
+	return r.buffer().Write(p)
+}
+
+// buffer returns payload buffer values are encoded to
+func (r *request) buffer() *bytes.Buffer {
+	if r.payload == nil {
+		r.payload = getBuffer()
+	}
+	return r.payload
+}
+
+// isRegistered returns true if the schema is registered in the cluster the request is sent to
+func (r *request) isRegistered(typeID int32, schemaID int32) bool {
+	return r.schemas != nil && r.schemas.isRegistered(typeID, schemaID)
+}
+
+// length returns length of the payload
+func (r *request) length() int {
+	if r.payload == nil {
+		return 0
+	}
+	return r.payload.Len()
 }
 
 // newRequest is private constructor for request
 func newRequest() request {
-	return request{payload: &bytes.Buffer{}}
+	return request{}
 }
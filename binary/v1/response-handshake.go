@@ -3,6 +3,8 @@ package ignite
 import (
 	"io"
 
+	"github.com/google/uuid"
+
 	"github.com/amsokol/ignite-go-client/binary/errors"
 )
 
@@ -14,6 +16,13 @@ type ResponseHandshake struct {
 	Major, Minor, Patch int
 	// Error message
 	Message string
+	// Node ID of the server (protocol version 1.4.0 and above)
+	NodeID uuid.UUID
+	// Features supported by the server (protocol version 1.7.0 and above)
+	Features Features
+
+	// version requested by the client
+	version ProtocolVersion
 
 	response
 }
@@ -32,7 +41,18 @@ func (r *ResponseHandshake) ReadFrom(rr io.Reader) (int64, error) {
 		return 0, errors.Wrapf(err, "failed to read success flag")
 	}
 
-	if !r.Success {
+	if r.Success {
+		if !r.version.Less(featuresVersion) {
+			if r.Features, err = ReadOArrayBytes(r); err != nil {
+				return 0, errors.Wrapf(err, "failed to read server features")
+			}
+		}
+		if !r.version.Less(nodeIDVersion) {
+			if r.NodeID, err = ReadOUUID(r); err != nil {
+				return 0, errors.Wrapf(err, "failed to read node ID")
+			}
+		}
+	} else {
 		v, err := ReadShort(r)
 		if err != nil {
 			return 0, errors.Wrapf(err, "failed to read server version major")
@@ -59,3 +79,8 @@ func (r *ResponseHandshake) ReadFrom(rr io.Reader) (int64, error) {
 
 	return n, nil
 }
+
+// NewResponseHandshake creates new handshake response object for the handshake with requested version
+func NewResponseHandshake(major, minor, patch int) *ResponseHandshake {
+	return &ResponseHandshake{version: ProtocolVersion{Major: major, Minor: minor, Patch: patch}}
+}
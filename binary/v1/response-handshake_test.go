@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"io"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 func TestResponseHandshake_ReadFrom(t *testing.T) {
@@ -13,8 +15,17 @@ func TestResponseHandshake_ReadFrom(t *testing.T) {
 		[]byte{23, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0,
 			9, 0x0B, 0, 0, 0, 0x74, 0x65, 0x73, 0x74, 0x20, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67})
 
+	nodeID := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	rr3 := bytes.NewBuffer([]byte{18, 0, 0, 0, 1})
+	_ = WriteOUUID(rr3, nodeID)
+
+	rr4 := bytes.NewBuffer([]byte{25, 0, 0, 0, 1, 12, 2, 0, 0, 0, 0x01, 0x08})
+	_ = WriteOUUID(rr4, nodeID)
+
 	r1 := &ResponseHandshake{}
 	r2 := &ResponseHandshake{}
+	r3 := NewResponseHandshake(1, 4, 0)
+	r4 := NewResponseHandshake(1, 7, 0)
 
 	type args struct {
 		rr io.Reader
@@ -27,6 +38,8 @@ func TestResponseHandshake_ReadFrom(t *testing.T) {
 		wantSuccess                     bool
 		wantMajor, wantMinor, wantPatch int
 		wantMessage                     string
+		wantNodeID                      uuid.UUID
+		wantFeatures                    Features
 		wantErr                         bool
 	}{
 		{
@@ -51,6 +64,27 @@ func TestResponseHandshake_ReadFrom(t *testing.T) {
 			wantPatch:   0,
 			wantMessage: "test string",
 		},
+		{
+			name: "3",
+			r:    r3,
+			args: args{
+				rr: rr3,
+			},
+			want:        4 + 18,
+			wantSuccess: true,
+			wantNodeID:  nodeID,
+		},
+		{
+			name: "4",
+			r:    r4,
+			args: args{
+				rr: rr4,
+			},
+			want:         4 + 25,
+			wantSuccess:  true,
+			wantNodeID:   nodeID,
+			wantFeatures: Features{0x01, 0x08},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -77,6 +111,12 @@ func TestResponseHandshake_ReadFrom(t *testing.T) {
 			if tt.r.Message != tt.wantMessage {
 				t.Errorf("ResponseHandshake.ReadFrom() message = %v, want %v", tt.r.Message, tt.wantMessage)
 			}
+			if tt.r.NodeID != tt.wantNodeID {
+				t.Errorf("ResponseHandshake.ReadFrom() node ID = %v, want %v", tt.r.NodeID, tt.wantNodeID)
+			}
+			if !bytes.Equal(tt.r.Features, tt.wantFeatures) {
+				t.Errorf("ResponseHandshake.ReadFrom() features = %v, want %v", tt.r.Features, tt.wantFeatures)
+			}
 		})
 	}
 }
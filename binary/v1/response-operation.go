@@ -1,6 +1,7 @@
 package ignite
 
 import (
+	"encoding/binary"
 	"io"
 
 	"github.com/amsokol/ignite-go-client/binary/errors"
@@ -71,3 +72,15 @@ func (r *ResponseOperation) CheckStatus() error {
 func NewResponseOperation(uid int64) *ResponseOperation {
 	return &ResponseOperation{UID: uid}
 }
+
+// operationResponseData returns data following the header of operation response message.
+// Returns false if the message is not response of successfully executed operation.
+func operationResponseData(frame []byte) ([]byte, bool) {
+	if len(frame) < responseHeaderLength+4 {
+		return nil, false
+	}
+	if int32(binary.LittleEndian.Uint32(frame[responseHeaderLength:])) != OperationStatusSuccess {
+		return nil, false
+	}
+	return frame[responseHeaderLength+4:], true
+}
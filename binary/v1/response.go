@@ -1,13 +1,40 @@
 package ignite
 
 import (
-	"bytes"
-	"encoding/binary"
+	"fmt"
 	"io"
 
 	"github.com/amsokol/ignite-go-client/binary/errors"
 )
 
+const (
+	// DefaultMaxFrameSize is default maximum length of the response message
+	DefaultMaxFrameSize = 256 << 20
+	// DefaultReadBufferSize is default size of the buffer responses are read from the connection through
+	DefaultReadBufferSize = 64 << 10
+)
+
+// FrameSizeError is returned if length of the response message is invalid or exceeds the maximum frame size
+type FrameSizeError struct {
+	// Length is length of the message
+	Length int64
+	// Max is the maximum frame size
+	Max int64
+}
+
+func (e *FrameSizeError) Error() string {
+	if e.Length < 0 || e.Length <= e.Max {
+		return fmt.Sprintf("invalid response message length %d", e.Length)
+	}
+	return fmt.Sprintf("response message length %d exceeds maximum frame size %d", e.Length, e.Max)
+}
+
+// frameReader is reader of the response message received from the connection.
+// Length of the message is already checked, response reads the message from it directly without copying.
+type frameReader struct {
+	io.Reader
+}
+
 // Response is interface of base message response functionality
 type Response interface {
 	// ReadFrom is function to read request data from io.Reader.
@@ -18,6 +45,12 @@ type Response interface {
 // response is struct is implementing base message response functionality
 type response struct {
 	message io.Reader
+	// frame decodes the message received in memory
+	frame decoder
+	// scratch is buffer values are read to from the message which is not in memory
+	scratch [16]byte
+	// resolver resolves schemas of the read objects with compact footers, it is nil if they are not resolved
+	resolver schemaResolver
 
 	Response
 	io.Reader
@@ -27,21 +60,53 @@ type response struct {
 // Returns read bytes.
 func (r *response) ReadFrom(rr io.Reader) (int64, error) {
 	// read response length
-	var l int32
-	if err := binary.Read(rr, binary.LittleEndian, &l); err != nil {
+	l, err := ReadInt(rr)
+	if err != nil {
 		return 0, errors.Wrapf(err, "failed to read response length")
 	}
 
+	if d, ok := rr.(*decoder); ok {
+		// message is decoded in place, rr is not used after return
+		b, err := d.next(int(l))
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to read response data")
+		}
+		r.frame = decoder{b: b}
+		r.message = &r.frame
+		return 4 + int64(l), nil
+	}
+	if _, ok := rr.(*frameReader); ok {
+		r.message = io.LimitReader(rr, int64(l))
+		return 4 + int64(l), nil
+	}
+	if l < 0 || l > DefaultMaxFrameSize {
+		return 0, &FrameSizeError{Length: int64(l), Max: DefaultMaxFrameSize}
+	}
+
 	// read response message
 	b := make([]byte, int(l))
-	if err := binary.Read(rr, binary.LittleEndian, &b); err != nil {
+	if _, err := io.ReadFull(rr, b); err != nil {
 		return 0, errors.Wrapf(err, "failed to read response data")
 	}
-	r.message = bytes.NewReader(b)
+	r.frame = decoder{b: b}
+	r.message = &r.frame
 
 	return 4 + int64(l), nil
 }
 
+// setResolver sets resolver of the schemas of the objects read from the response
+func (r *response) setResolver(resolver schemaResolver) {
+	r.resolver = resolver
+}
+
+// resolveSchema resolves schema of the object with compact footer read from the response
+func (r *response) resolveSchema(typeID int32, schemaID int32) (*schema, error) {
+	if r.resolver == nil {
+		return nil, nil
+	}
+	return r.resolver.resolveSchema(typeID, schemaID)
+}
+
 // Read reads up to len(p) bytes into p. It returns the number of bytes
 // read (0 <= n <= len(p)) and any error encountered. Even if Read
 // returns n < len(p), it may use all of p as scratch space during the call.
@@ -50,3 +115,27 @@ func (r *response) ReadFrom(rr io.Reader) (int64, error) {
 func (r *response) Read(p []byte) (n int, err error) {
 	return r.message.Read(p)
 }
+
+// remaining returns count of the unread bytes of the message, -1 if it is unknown
+func (r *response) remaining() int64 {
+	return remaining(r.message)
+}
+
+// next returns the next n bytes of the message.
+// Returned slice is valid until the next read only.
+func (r *response) next(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if s, ok := r.message.(sliceReader); ok {
+		return s.next(n)
+	}
+	if n > len(r.scratch) {
+		return readNext(r.message, n)
+	}
+	b := r.scratch[:n]
+	if _, err := io.ReadFull(r.message, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
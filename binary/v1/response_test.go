@@ -27,6 +27,22 @@ func Test_response_ReadFrom(t *testing.T) {
 			},
 			want: 4 + 1,
 		},
+		{
+			name: "negative length",
+			r:    &response{},
+			args: args{
+				rr: bytes.NewBuffer([]byte{0xff, 0xff, 0xff, 0xff}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "length exceeds maximum frame size",
+			r:    &response{},
+			args: args{
+				rr: bytes.NewBuffer([]byte{0xff, 0xff, 0xff, 0x7f}),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,224 @@
+package ignite
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// maxSchemasPerType is the maximum count of the cached schemas of the single type,
+// schemas of the type are not cached after the limit is reached
+const maxSchemasPerType = 64
+
+// schema is order of the complex object fields
+type schema struct {
+	id     int32
+	fields []int32
+	// registered is true if the schema is received from the cluster, order of its fields is not replaced
+	// by the declared one. Whether the schema is registered in the cluster the object is written to
+	// is checked by schemaRegistry of the writer.
+	registered bool
+}
+
+// schemaCache caches schemas of the complex objects by type ID.
+// WriteOComplexObject writes fields in order of the cached schema with the same set of fields,
+// so identical objects are always written as identical bytes.
+type schemaCache struct {
+	mutex sync.RWMutex
+	// types are all known schemas of the types by schema ID
+	types map[int32]map[int32]*schema
+	// preferred are schemas the fields are written in order of by type ID, one schema for every set of fields
+	preferred map[int32][]*schema
+}
+
+// schemas is cache of the schemas of all complex objects written and read by the package
+var schemas = &schemaCache{types: map[int32]map[int32]*schema{}, preferred: map[int32][]*schema{}}
+
+// RegisterSchema declares order of the fields of the complex objects of the type typeID.
+// Objects with exactly the same set of fields are written by WriteOComplexObject in this order.
+// Schemas of the binary types received from the cluster replace the declared ones,
+// the first schema received from the cluster is kept for the set of fields.
+// Schemas of the read objects never replace the known order, they are used for the sets of fields without it.
+func RegisterSchema(typeID int32, fieldIDs ...int32) {
+	schemas.put(typeID, fieldIDs, true, false)
+}
+
+// get returns preferred schema of the type with the set of fields
+func (c *schemaCache) get(typeID int32, fields map[int32]interface{}) *schema {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+next:
+	for _, s := range c.preferred[typeID] {
+		if len(s.fields) != len(fields) {
+			continue
+		}
+		for _, id := range s.fields {
+			if _, ok := fields[id]; !ok {
+				continue next
+			}
+		}
+		return s
+	}
+	return nil
+}
+
+// lookup returns schema of the type by schema ID, nil is returned if the schema is not known
+func (c *schemaCache) lookup(typeID int32, schemaID int32) *schema {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.types[typeID][schemaID]
+}
+
+// schemaResolver is implemented by readers of the responses of the client.
+// It gets schemas of the objects with compact footers from the cluster if they are not known.
+type schemaResolver interface {
+	// resolveSchema returns schema of the type, nil is returned if the schema is not known to the cluster
+	resolveSchema(typeID int32, schemaID int32) (*schema, error)
+}
+
+// resolverOf returns resolver of the schemas of the objects read from r, nil is returned if r has no resolver
+func resolverOf(r io.Reader) schemaResolver {
+	switch r := r.(type) {
+	case *decoder:
+		return r.resolver
+	case schemaResolver:
+		return r
+	}
+	return nil
+}
+
+// unknownSchemaError is returned if schema of the object with compact footer is not known
+type unknownSchemaError struct {
+	typeID   int32
+	schemaID int32
+}
+
+func (e *unknownSchemaError) Error() string {
+	return fmt.Sprintf("schema %d of binary type %d is not known, get the binary type "+
+		"from the cluster to read objects with compact footer", e.schemaID, e.typeID)
+}
+
+// lookupSchema returns schema of the object with compact footer.
+// Schema which is not known yet is resolved by resolver if it is not nil.
+func lookupSchema(resolver schemaResolver, typeID int32, schemaID int32) (*schema, error) {
+	if s := schemas.lookup(typeID, schemaID); s != nil {
+		return s, nil
+	}
+	if resolver != nil {
+		s, err := resolver.resolveSchema(typeID, schemaID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get schema %d of binary type %d", schemaID, typeID)
+		}
+		if s != nil {
+			return s, nil
+		}
+	}
+	return nil, &unknownSchemaError{typeID: typeID, schemaID: schemaID}
+}
+
+// schemaRegistry is implemented by writers which know the schemas registered in the cluster
+// the objects are written to, objects of these schemas are written with compact footers.
+// Requests of the client with ConnInfo.CompactFooter set implement it.
+type schemaRegistry interface {
+	// isRegistered returns true if the schema of the type is registered in the cluster
+	isRegistered(typeID int32, schemaID int32) bool
+}
+
+// registeredSchemas is set of the schemas registered in the cluster.
+// Every client keeps its own set: the schema registered in one cluster may be unknown to another one.
+type registeredSchemas struct {
+	mutex sync.RWMutex
+	types map[int32]map[int32]bool
+}
+
+// add adds schema of the type with fields in order of fieldIDs to the set
+func (s *registeredSchemas) add(typeID int32, fieldIDs []int32) {
+	id := schemaID(fieldIDs)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.types == nil {
+		s.types = map[int32]map[int32]bool{}
+	}
+	if s.types[typeID] == nil {
+		s.types[typeID] = map[int32]bool{}
+	}
+	s.types[typeID][id] = true
+}
+
+// isRegistered returns true if the schema of the type is in the set
+func (s *registeredSchemas) isRegistered(typeID int32, schemaID int32) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.types[typeID][schemaID]
+}
+
+// getOrAdd returns preferred schema of the type with the set of fields.
+// Schema with fields in ascending order of IDs is cached if there is no schema with the set of fields.
+func (c *schemaCache) getOrAdd(typeID int32, fields map[int32]interface{}) *schema {
+	if s := c.get(typeID, fields); s != nil {
+		return s
+	}
+	ids := make([]int32, 0, len(fields))
+	for id := range fields {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return c.put(typeID, ids, false, false)
+}
+
+// put caches schema of the type with fields in order of fieldIDs, registered is true if the schema
+// is received from the cluster.
+// Preferred schema with the same set of fields is replaced if replace is true,
+// schema registered in the cluster is never replaced to keep the order of the fields stable.
+// Returns the preferred schema with the set of fields.
+func (c *schemaCache) put(typeID int32, fieldIDs []int32, replace, registered bool) *schema {
+	id := schemaID(fieldIDs)
+	set := make(map[int32]bool, len(fieldIDs))
+	for _, f := range fieldIDs {
+		set[f] = true
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ns, ok := c.types[typeID][id]
+	if !ok {
+		ns = &schema{id: id, fields: append([]int32(nil), fieldIDs...)}
+		if c.types[typeID] == nil {
+			c.types[typeID] = map[int32]*schema{}
+		}
+		if len(c.types[typeID]) < maxSchemasPerType {
+			c.types[typeID][id] = ns
+		}
+	}
+	if registered {
+		ns.registered = true
+	}
+
+	preferred := c.preferred[typeID]
+	for i, s := range preferred {
+		if len(s.fields) != len(set) {
+			continue
+		}
+		same := true
+		for _, f := range s.fields {
+			if !set[f] {
+				same = false
+				break
+			}
+		}
+		if same {
+			if replace && !s.registered {
+				preferred[i] = ns
+				return ns
+			}
+			return s
+		}
+	}
+	if len(preferred) < maxSchemasPerType {
+		c.preferred[typeID] = append(preferred, ns)
+	}
+	return ns
+}
@@ -0,0 +1,136 @@
+package ignite
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// writtenFields returns IDs of the fields of the complex object in order they are written
+func writtenFields(t *testing.T, b []byte) []int32 {
+	var ids []int32
+	if _, _, err := ReadOComplexObjectFields(bytes.NewReader(b), func(fieldID int32, field io.Reader) error {
+		ids = append(ids, fieldID)
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadOComplexObjectFields() error = %v", err)
+	}
+	return ids
+}
+
+func TestWriteOComplexObject_Schema(t *testing.T) {
+	object := func(typeName string, fields ...string) ComplexObject {
+		o := NewComplexObject(typeName)
+		for _, f := range fields {
+			o.Set(f, f)
+		}
+		return o
+	}
+	written := func(o ComplexObject) []byte {
+		w := &bytes.Buffer{}
+		if err := WriteOComplexObject(w, o); err != nil {
+			t.Fatalf("WriteOComplexObject() error = %v", err)
+		}
+		return w.Bytes()
+	}
+
+	tests := []struct {
+		name  string
+		setup func()
+		o     ComplexObject
+		want  []int32
+	}{
+		{
+			name: "ascending order of field IDs",
+			o:    object("TestSchemaAscending", "c", "a", "b"),
+			want: []int32{HashCode("a"), HashCode("b"), HashCode("c")},
+		},
+		{
+			name: "registered schema",
+			setup: func() {
+				RegisterSchema(HashCode("TestSchemaRegistered"), HashCode("c"), HashCode("a"), HashCode("b"))
+			},
+			o:    object("TestSchemaRegistered", "a", "b", "c"),
+			want: []int32{HashCode("c"), HashCode("a"), HashCode("b")},
+		},
+		{
+			name: "schema of read object",
+			setup: func() {
+				c := NewComplexObjectWriter(HashCode("TestSchemaRead"))
+				_ = WriteOString(c.Field(HashCode("b")), "b")
+				_ = WriteOString(c.Field(HashCode("a")), "a")
+				w := &bytes.Buffer{}
+				_ = c.WriteObject(w)
+				if _, err := ReadObject(w); err != nil {
+					t.Fatalf("ReadObject() error = %v", err)
+				}
+			},
+			o:    object("TestSchemaRead", "a", "b"),
+			want: []int32{HashCode("b"), HashCode("a")},
+		},
+		{
+			name: "read object does not replace schema",
+			setup: func() {
+				RegisterSchema(HashCode("TestSchemaReadRegistered"), HashCode("a"), HashCode("b"))
+				c := NewComplexObjectWriter(HashCode("TestSchemaReadRegistered"))
+				_ = WriteOString(c.Field(HashCode("b")), "b")
+				_ = WriteOString(c.Field(HashCode("a")), "a")
+				w := &bytes.Buffer{}
+				_ = c.WriteObject(w)
+				if _, err := ReadObject(w); err != nil {
+					t.Fatalf("ReadObject() error = %v", err)
+				}
+			},
+			o:    object("TestSchemaReadRegistered", "a", "b"),
+			want: []int32{HashCode("a"), HashCode("b")},
+		},
+		{
+			name: "first schema from cluster is kept",
+			setup: func() {
+				typeID := HashCode("TestSchemaCluster")
+				schemas.put(typeID, []int32{HashCode("b"), HashCode("a")}, true, true)
+				schemas.put(typeID, []int32{HashCode("a"), HashCode("b")}, true, true)
+				RegisterSchema(typeID, HashCode("a"), HashCode("b"))
+			},
+			o:    object("TestSchemaCluster", "a", "b"),
+			want: []int32{HashCode("b"), HashCode("a")},
+		},
+		{
+			name: "struct fields order",
+			setup: func() {
+				type TestSchemaStruct struct {
+					C string `ignite:"c"`
+					A string `ignite:"a"`
+				}
+				if err := WriteObject(&bytes.Buffer{}, TestSchemaStruct{}); err != nil {
+					t.Fatalf("WriteObject() error = %v", err)
+				}
+			},
+			o:    object("TestSchemaStruct", "a", "c"),
+			want: []int32{HashCode("c"), HashCode("a")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			b := written(tt.o)
+			if got := writtenFields(t, b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WriteOComplexObject() fields = %v, want %v", got, tt.want)
+			}
+			// identical objects are written as identical bytes
+			for i := 0; i < 10; i++ {
+				o := NewComplexObject("")
+				o.Type = tt.o.Type
+				for id, v := range tt.o.Fields {
+					o.Fields[id] = v
+				}
+				if got := written(o); !bytes.Equal(got, b) {
+					t.Fatalf("WriteOComplexObject() = %v, want %v", got, b)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package ignite
+
+import (
+	"bytes"
+	"encoding/binary"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// testHandler returns data of response for the operation request received over connection with index conn.
+// If error is returned the response has error status.
+type testHandler func(conn int, code int16, data []byte) ([]byte, error)
+
+// testFeatures are features supported by fake cluster node
+var testFeatures = Features{0xff, 0xff}
+
+// errTestDrop is returned by testHandler to close the connection without response
+var errTestDrop = stderrors.New("drop connection")
+
+// testServer is fake cluster node for the tests which do not need real Apache Ignite server
+type testServer struct {
+	t        *testing.T
+	listener net.Listener
+	handler  testHandler
+	// nodeID is not nil if the server uses protocol version 1.4.0
+	nodeID *uuid.UUID
+	// version is the highest protocol version accepted by the server, any version is accepted if it is not set
+	version *ProtocolVersion
+
+	mutex sync.Mutex
+	conns []net.Conn
+	// handshakes contains received handshake requests
+	handshakes [][]byte
+	wg         sync.WaitGroup
+}
+
+// newTestServer starts fake cluster node with protocol version 1.1.0 which accepts any handshake
+func newTestServer(t *testing.T, handler testHandler) *testServer {
+	return startTestServer(t, nil, nil, handler)
+}
+
+// newTestServerVersion starts fake cluster node which rejects handshakes with protocol version above version
+func newTestServerVersion(t *testing.T, version ProtocolVersion, handler testHandler) *testServer {
+	return startTestServer(t, nil, &version, handler)
+}
+
+// newTestNode starts fake cluster node with protocol version 1.4.0 which accepts any handshake
+func newTestNode(t *testing.T, nodeID uuid.UUID, handler testHandler) *testServer {
+	return startTestServer(t, &nodeID, nil, handler)
+}
+
+func startTestServer(t *testing.T, nodeID *uuid.UUID, version *ProtocolVersion, handler testHandler) *testServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &testServer{t: t, listener: l, handler: handler, nodeID: nodeID, version: version}
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+// connInfo returns connection parameters of the server
+func (s *testServer) connInfo() ConnInfo {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	ci := ConnInfo{Network: "tcp", Host: addr.IP.String(), Port: addr.Port, Major: 1, Minor: 1, Patch: 0}
+	if s.nodeID != nil {
+		ci.Minor = 4
+	}
+	return ci
+}
+
+// endpoint returns address of the server
+func (s *testServer) endpoint() Endpoint {
+	ci := s.connInfo()
+	return Endpoint{Host: ci.Host, Port: ci.Port}
+}
+
+// accepted returns count of accepted connections
+func (s *testServer) accepted() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.conns)
+}
+
+// handshake returns handshake request received over connection with index conn
+func (s *testServer) handshake(conn int) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.handshakes[conn]
+}
+
+// drop closes accepted connections
+func (s *testServer) drop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// close stops the server and closes accepted connections
+func (s *testServer) close() {
+	s.listener.Close()
+	s.mutex.Lock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
+
+func (s *testServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		index := len(s.conns)
+		s.conns = append(s.conns, conn)
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(index, conn)
+	}
+}
+
+func (s *testServer) serve(index int, conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	// handshake
+	frame, err := readFrame(conn)
+	if err != nil {
+		return
+	}
+	s.mutex.Lock()
+	s.handshakes = append(s.handshakes, frame)
+	s.mutex.Unlock()
+	requested := ProtocolVersion{
+		Major: int(binary.LittleEndian.Uint16(frame[5:])),
+		Minor: int(binary.LittleEndian.Uint16(frame[7:])),
+		Patch: int(binary.LittleEndian.Uint16(frame[9:])),
+	}
+	if s.version != nil && s.version.Less(requested) {
+		msg := "unsupported version"
+		res := []byte{0, 0, 0, 0, 0}
+		for _, v := range []int{s.version.Major, s.version.Minor, s.version.Patch} {
+			res = append(res, byte(v), byte(v>>8))
+		}
+		res = append(res, 9)
+		res = appendInt32(res, int32(len(msg)))
+		res = append(res, msg...)
+		binary.LittleEndian.PutUint32(res, uint32(len(res)-4))
+		_, _ = conn.Write(res)
+		return
+	}
+	// responses have flags instead of status since protocol version 1.4.0
+	flags := !requested.Less(nodeIDVersion)
+
+	handshake := &bytes.Buffer{}
+	handshake.Write([]byte{0, 0, 0, 0, 1})
+	if !requested.Less(featuresVersion) {
+		// server supports all features
+		_ = WriteOArrayBytes(handshake, testFeatures)
+	}
+	if flags {
+		var nodeID uuid.UUID
+		if s.nodeID != nil {
+			nodeID = *s.nodeID
+		}
+		_ = WriteOUUID(handshake, nodeID)
+	}
+	binary.LittleEndian.PutUint32(handshake.Bytes(), uint32(handshake.Len()-4))
+	if _, err := conn.Write(handshake.Bytes()); err != nil {
+		return
+	}
+
+	for {
+		frame, err := readFrame(conn)
+		if err != nil {
+			return
+		}
+		code := int16(binary.LittleEndian.Uint16(frame[4:]))
+		uid := frame[6:requestHeaderLength]
+
+		data, err := s.handler(index, code, frame[requestHeaderLength:])
+		if err == errTestDrop {
+			return
+		}
+		res := make([]byte, 4, responseHeaderLength+4+len(data))
+		res = append(res, uid...)
+		if err != nil {
+			msg := err.Error()
+			if flags {
+				res = append(res, responseFlagError, 0)
+			}
+			res = append(res, 1, 0, 0, 0, 9)
+			res = appendInt32(res, int32(len(msg)))
+			res = append(res, msg...)
+		} else {
+			if flags {
+				res = append(res, 0, 0)
+			} else {
+				res = append(res, 0, 0, 0, 0)
+			}
+			res = append(res, data...)
+		}
+		binary.LittleEndian.PutUint32(res, uint32(len(res)-4))
+		if _, err := conn.Write(res); err != nil {
+			return
+		}
+	}
+}
+
+// readFrame reads message including message length
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	l := int32(binary.LittleEndian.Uint32(header[:]))
+	if l < 0 {
+		return nil, fmt.Errorf("invalid message length %d", l)
+	}
+	frame := make([]byte, 4+int(l))
+	copy(frame, header[:])
+	if _, err := io.ReadFull(r, frame[4:]); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func appendInt32(b []byte, v int32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(v))
+	return append(b, buf[:]...)
+}
+
+func appendInt64(b []byte, v int64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(v))
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,235 @@
+package ignite
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+)
+
+// TxConcurrency is transaction concurrency mode
+type TxConcurrency byte
+
+const (
+	// TxOptimistic is OPTIMISTIC = 0
+	TxOptimistic TxConcurrency = 0
+	// TxPessimistic is PESSIMISTIC = 1
+	TxPessimistic TxConcurrency = 1
+)
+
+// TxIsolation is transaction isolation level
+type TxIsolation byte
+
+const (
+	// TxReadCommitted is READ_COMMITTED = 0
+	TxReadCommitted TxIsolation = 0
+	// TxRepeatableRead is REPEATABLE_READ = 1
+	TxRepeatableRead TxIsolation = 1
+	// TxSerializable is SERIALIZABLE = 2
+	TxSerializable TxIsolation = 2
+)
+
+// Tx is transaction started by Client.TxStart (protocol version 1.5.0 and above).
+// Key-Value operations (Cache* methods) and SQL queries (QuerySQL* methods) of Tx are executed in the transaction
+// over the connection the transaction is started on, other operations are executed outside of the transaction.
+// Operations of Tx fail if the connection is broken, they are never sent over another connection
+// because the transaction is rolled back by the server.
+// Tx is finished by Commit or Rollback, Close rolls the transaction back if it is not finished
+// and does not close the client.
+type Tx interface {
+	Client
+
+	// ID returns ID of the transaction
+	ID() int32
+
+	// Commit commits the transaction
+	Commit() error
+
+	// CommitContext is equal to Commit but uses ctx to cancel the operation or limit its duration.
+	CommitContext(ctx context.Context) error
+
+	// Rollback rolls the transaction back
+	Rollback() error
+
+	// RollbackContext is equal to Rollback but uses ctx to cancel the operation or limit its duration.
+	RollbackContext(ctx context.Context) error
+}
+
+// binder is transport which can bind requests to the single connection.
+// Every transport of the client implements it, so the transaction is pinned to the connection it is started on.
+type binder interface {
+	// bind returns transport which sends requests over the single connection until it is closed
+	bind(ctx context.Context) (transport, error)
+}
+
+// tx is client which executes Key-Value operations in the transaction
+type tx struct {
+	*client
+	// bound is transport bound to the connection the transaction is started on, it is closed when the transaction is finished
+	bound transport
+
+	mutex    sync.Mutex
+	finished bool
+}
+
+// TxStart starts the transaction.
+// timeout is the transaction timeout, zero value means the timeout is not limited.
+// label is optional label of the transaction shown in the cluster monitoring tools.
+func (c *client) TxStart(concurrency TxConcurrency, isolation TxIsolation, timeout time.Duration, label string) (Tx, error) {
+	return c.TxStartContext(context.Background(), concurrency, isolation, timeout, label)
+}
+
+// TxStartContext is equal to TxStart but uses ctx to cancel the operation or limit its duration.
+func (c *client) TxStartContext(ctx context.Context, concurrency TxConcurrency, isolation TxIsolation,
+	timeout time.Duration, label string) (Tx, error) {
+	if c.owner != nil {
+		return nil, errors.Errorf("transaction %d is already started", c.txID)
+	}
+	if v := c.ProtocolVersion(); v.Less(transactionsVersion) {
+		return nil, errors.Errorf("transactions are not supported by protocol version %s", v)
+	}
+
+	// request and response
+	req := c.newRequestOperation(OpTxStart)
+	res := NewResponseOperation(req.UID)
+
+	// set parameters
+	if err := WriteByte(req, byte(concurrency)); err != nil {
+		return nil, errors.Wrapf(err, "failed to write concurrency")
+	}
+	if err := WriteByte(req, byte(isolation)); err != nil {
+		return nil, errors.Wrapf(err, "failed to write isolation")
+	}
+	if err := WriteLong(req, timeout.Milliseconds()); err != nil {
+		return nil, errors.Wrapf(err, "failed to write timeout")
+	}
+	if err := writeOStringOrNull(req, label); err != nil {
+		return nil, errors.Wrapf(err, "failed to write label")
+	}
+
+	// transaction is bound to the connection, operations of the transaction are sent over it
+	b, ok := c.conn.(binder)
+	if !ok {
+		return nil, errors.Errorf("transactions are not supported by the connection")
+	}
+	if err := c.begin(); err != nil {
+		return nil, err
+	}
+	conn, err := b.bind(ctx)
+	c.end()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to bind transaction to connection")
+	}
+	t := &tx{client: &client{conn: conn, debugID: c.debugID, owner: c}, bound: conn}
+
+	// execute operation
+	if err := t.DoContext(ctx, req, res); err != nil {
+		t.unbind()
+		return nil, errors.Wrapf(err, "failed to execute OP_TX_START operation")
+	}
+	if err := res.CheckStatus(); err != nil {
+		t.unbind()
+		return nil, err
+	}
+	id, err := ReadInt(res)
+	if err != nil {
+		t.unbind()
+		return nil, errors.Wrapf(err, "failed to read transaction id")
+	}
+	t.txID = id
+
+	return t, nil
+}
+
+// ID returns ID of the transaction
+func (t *tx) ID() int32 {
+	return t.txID
+}
+
+// Commit commits the transaction
+func (t *tx) Commit() error {
+	return t.CommitContext(context.Background())
+}
+
+// CommitContext is equal to Commit but uses ctx to cancel the operation or limit its duration.
+func (t *tx) CommitContext(ctx context.Context) error {
+	return t.end(ctx, true)
+}
+
+// Rollback rolls the transaction back
+func (t *tx) Rollback() error {
+	return t.RollbackContext(context.Background())
+}
+
+// RollbackContext is equal to Rollback but uses ctx to cancel the operation or limit its duration.
+func (t *tx) RollbackContext(ctx context.Context) error {
+	return t.end(ctx, false)
+}
+
+// Close rolls the transaction back if it is not finished.
+// The client the transaction is started by is not closed.
+func (t *tx) Close() error {
+	t.mutex.Lock()
+	finished := t.finished
+	t.mutex.Unlock()
+	if finished {
+		return nil
+	}
+	return t.Rollback()
+}
+
+// end commits or rolls back the transaction
+func (t *tx) end(ctx context.Context, commit bool) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.finished {
+		return errors.Errorf("transaction %d is already finished", t.txID)
+	}
+
+	// request and response
+	req := t.newRequestOperation(OpTxEnd)
+	res := NewResponseOperation(req.UID)
+
+	// set parameters
+	if err := WriteInt(req, t.txID); err != nil {
+		return errors.Wrapf(err, "failed to write transaction id")
+	}
+	if err := WriteBool(req, commit); err != nil {
+		return errors.Wrapf(err, "failed to write commit flag")
+	}
+
+	// execute operation
+	if err := t.DoContext(ctx, req, res); err != nil {
+		if !t.bound.alive() {
+			// transaction is rolled back by the server when its connection is closed
+			t.finished = true
+			t.unbind()
+		}
+		return errors.Wrapf(err, "failed to execute OP_TX_END operation")
+	}
+	// transaction is finished on the server even if it fails to commit
+	t.finished = true
+	t.unbind()
+
+	return res.CheckStatus()
+}
+
+// unbind releases the connection the transaction is bound to
+func (t *tx) unbind() {
+	_ = t.bound.close()
+}
+
+// isTransactional returns true if Key-Value operation or SQL query request is executed in the transaction
+func isTransactional(message []byte) bool {
+	if len(message) < requestHeaderLength+4+1 {
+		return false
+	}
+	code := binary.LittleEndian.Uint16(message[4:])
+	if (code < OpCacheGet || code > OpCacheGetSize) && code != OpQuerySQL && code != OpQuerySQLFields {
+		return false
+	}
+	// request starts with cache ID and flags
+	return message[requestHeaderLength+4]&TransactionalFlagMask != 0
+}
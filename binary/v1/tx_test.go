@@ -0,0 +1,320 @@
+package ignite
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testTxServer is fake cluster node which records transaction requests
+type testTxServer struct {
+	*testServer
+
+	mutex    sync.Mutex
+	requests []testTxRequest
+}
+
+// testTxRequest is operation request received by testTxServer
+type testTxRequest struct {
+	conn int
+	code int16
+	data []byte
+}
+
+func newTestTxServer(t *testing.T) *testTxServer {
+	s := &testTxServer{}
+	s.testServer = newTestServer(t, func(conn int, code int16, data []byte) ([]byte, error) {
+		s.mutex.Lock()
+		s.requests = append(s.requests, testTxRequest{conn: conn, code: code, data: append([]byte(nil), data...)})
+		s.mutex.Unlock()
+		if code == OpTxStart {
+			// every connection starts transaction IDs from 1
+			return []byte{1, 0, 0, 0}, nil
+		}
+		return nil, nil
+	})
+	return s
+}
+
+// received returns requests received by the server
+func (s *testTxServer) received() []testTxRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]testTxRequest(nil), s.requests...)
+}
+
+// connInfo returns connection parameters with protocol version 1.5.0
+func (s *testTxServer) connInfo() ConnInfo {
+	ci := s.testServer.connInfo()
+	ci.Minor = 5
+	return ci
+}
+
+func TestClient_TxStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		end     func(tx Tx) error
+		wantEnd []byte
+	}{
+		{
+			name:    "commit",
+			end:     func(tx Tx) error { return tx.Commit() },
+			wantEnd: []byte{1, 0, 0, 0, 1},
+		},
+		{
+			name:    "rollback",
+			end:     func(tx Tx) error { return tx.Rollback() },
+			wantEnd: []byte{1, 0, 0, 0, 0},
+		},
+		{
+			name:    "close",
+			end:     func(tx Tx) error { return tx.Close() },
+			wantEnd: []byte{1, 0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTxServer(t)
+			defer s.close()
+			c, err := Connect(s.connInfo())
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer c.Close()
+
+			tx, err := c.TxStart(TxPessimistic, TxRepeatableRead, 5*time.Second, "label")
+			if err != nil {
+				t.Fatalf("client.TxStart() error = %v", err)
+			}
+			if tx.ID() != 1 {
+				t.Errorf("Tx.ID() = %d, want 1", tx.ID())
+			}
+			if err = tx.CachePut("TestCache", true, int32(1), int32(2)); err != nil {
+				t.Fatalf("Tx.CachePut() error = %v", err)
+			}
+			if err = c.CachePut("TestCache", false, int32(1), int32(2)); err != nil {
+				t.Fatalf("client.CachePut() error = %v", err)
+			}
+			if err = tt.end(tx); err != nil {
+				t.Fatalf("end of transaction error = %v", err)
+			}
+			if err = tx.Commit(); err == nil {
+				t.Errorf("Tx.Commit() of finished transaction error = nil")
+			}
+			if err = tx.Close(); err != nil {
+				t.Errorf("Tx.Close() of finished transaction error = %v", err)
+			}
+			if !c.Connected() {
+				t.Errorf("client is closed by Tx")
+			}
+
+			start := &bytes.Buffer{}
+			_ = WriteByte(start, byte(TxPessimistic))
+			_ = WriteByte(start, byte(TxRepeatableRead))
+			_ = WriteLong(start, 5000)
+			_ = WriteOString(start, "label")
+			put := &bytes.Buffer{}
+			_ = WriteInt(put, HashCode("TestCache"))
+			_ = WriteByte(put, KeepBinaryFlagMask|TransactionalFlagMask)
+			_ = WriteInt(put, 1)
+			_ = WriteOInt(put, 1)
+			_ = WriteOInt(put, 2)
+			plain := &bytes.Buffer{}
+			_ = WriteInt(plain, HashCode("TestCache"))
+			_ = WriteByte(plain, 0)
+			_ = WriteOInt(plain, 1)
+			_ = WriteOInt(plain, 2)
+			want := []testTxRequest{
+				{code: OpTxStart, data: start.Bytes()},
+				{code: OpCachePut, data: put.Bytes()},
+				{code: OpCachePut, data: plain.Bytes()},
+				{code: OpTxEnd, data: tt.wantEnd},
+			}
+			if got := s.received(); !reflect.DeepEqual(got, want) {
+				t.Errorf("requests = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestClient_TxStart_Version(t *testing.T) {
+	s := newTestTxServer(t)
+	defer s.close()
+	ci := s.connInfo()
+	ci.Minor = 4
+	c, err := Connect(ci)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	if _, err = c.TxStart(TxOptimistic, TxReadCommitted, 0, ""); err == nil {
+		t.Errorf("client.TxStart() error = nil for protocol version 1.4.0")
+	}
+	if got := len(s.received()); got != 0 {
+		t.Errorf("server received %d requests, want 0", got)
+	}
+}
+
+func TestConnectPool_Tx(t *testing.T) {
+	s := newTestTxServer(t)
+	defer s.close()
+	c, err := ConnectPool(PoolConfig{ConnInfo: s.connInfo(), MinConnections: 2, MaxConnections: 2})
+	if err != nil {
+		t.Fatalf("ConnectPool() error = %v", err)
+	}
+	defer c.Close()
+
+	// transactions of different connections have the same ID
+	txs := make([]Tx, 2)
+	for i := range txs {
+		if txs[i], err = c.TxStart(TxPessimistic, TxReadCommitted, 0, ""); err != nil {
+			t.Fatalf("client.TxStart() error = %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for _, tx := range txs {
+			if err = tx.CachePut("TestCache", false, int32(i), int32(i)); err != nil {
+				t.Fatalf("Tx.CachePut() error = %v", err)
+			}
+		}
+	}
+	for _, tx := range txs {
+		if err = tx.Commit(); err != nil {
+			t.Fatalf("Tx.Commit() error = %v", err)
+		}
+	}
+
+	// every transaction uses the connection it is started on
+	requests := s.received()
+	conns := map[int]int{}
+	for i, r := range requests[:2] {
+		conns[r.conn] = i
+	}
+	if len(conns) != 2 {
+		t.Fatalf("transactions are started on %d connections, want 2", len(conns))
+	}
+	for i, r := range requests[2:] {
+		if want := i % 2; conns[r.conn] != want {
+			t.Errorf("request %d of transaction %d is sent over connection of transaction %d", i/2, want, conns[r.conn])
+		}
+	}
+
+	// connections are returned to the pool
+	p := c.(*client).conn.(*pool)
+	p.mutex.Lock()
+	borrowed := len(p.borrowed)
+	p.mutex.Unlock()
+	if borrowed != 0 {
+		t.Errorf("pool has %d borrowed connections after transactions are finished", borrowed)
+	}
+}
+
+func TestClient_TxReconnect(t *testing.T) {
+	s1 := newTestTxServer(t)
+	defer s1.close()
+	s2 := newTestTxServer(t)
+	defer s2.close()
+
+	tests := []struct {
+		name    string
+		connect func(ci ConnInfo) (Client, error)
+	}{
+		{
+			name:    "failover",
+			connect: Connect,
+		},
+		{
+			name: "partition awareness",
+			connect: func(ci ConnInfo) (Client, error) {
+				ci.PartitionAwareness = true
+				return Connect(ci)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := s1.connInfo()
+			ci.Endpoints = []Endpoint{s1.endpoint(), s2.endpoint()}
+			ci.Reconnect = ReconnectConfig{InitialInterval: 10 * time.Millisecond}
+			c, err := tt.connect(ci)
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer c.Close()
+			before := len(s2.received())
+
+			tx, err := c.TxStart(TxPessimistic, TxRepeatableRead, 0, "")
+			if err != nil {
+				t.Fatalf("client.TxStart() error = %v", err)
+			}
+			// the client reconnects to the second node
+			s1.drop()
+			waitFor(t, func() bool { return c.Connected() && c.ActiveEndpoint() == s2.endpoint() })
+
+			if err = tx.CachePut("TestCache", false, int32(1), int32(2)); err == nil {
+				t.Errorf("Tx.CachePut() error = nil after the connection is broken")
+			}
+			if err = tx.Commit(); err == nil {
+				t.Errorf("Tx.Commit() error = nil after the connection is broken")
+			}
+			if err = tx.Close(); err != nil {
+				t.Errorf("Tx.Close() error = %v", err)
+			}
+			// operations of the transaction are not sent to the node which does not know it
+			for _, r := range s2.received()[before:] {
+				if r.code == OpCachePut || r.code == OpTxEnd {
+					t.Errorf("operation %d of the transaction is sent to the second node", r.code)
+				}
+			}
+		})
+	}
+}
+
+func Test_isTransactional(t *testing.T) {
+	message := func(code int16, flags byte) []byte {
+		req := NewRequestOperation(code)
+		_ = WriteInt(req, HashCode("TestCache"))
+		_ = WriteByte(req, flags)
+		b := &bytes.Buffer{}
+		_, _ = req.WriteTo(b)
+		return b.Bytes()
+	}
+	tests := []struct {
+		name    string
+		message []byte
+		want    bool
+	}{
+		{
+			name:    "transactional get",
+			message: message(OpCacheGet, KeepBinaryFlagMask|TransactionalFlagMask),
+			want:    true,
+		},
+		{
+			name:    "get",
+			message: message(OpCacheGet, KeepBinaryFlagMask),
+		},
+		{
+			name:    "transactional SQL fields query",
+			message: message(OpQuerySQLFields, TransactionalFlagMask),
+			want:    true,
+		},
+		{
+			name:    "cache names",
+			message: message(OpCacheGetNames, TransactionalFlagMask),
+		},
+		{
+			name:    "short message",
+			message: message(OpCacheGet, TransactionalFlagMask)[:requestHeaderLength+4],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransactional(tt.message); got != tt.want {
+				t.Errorf("isTransactional() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
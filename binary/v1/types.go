@@ -1,9 +1,9 @@
 package ignite
 
 import (
-	"bytes"
 	"encoding/binary"
 	"io"
+	"math"
 	"reflect"
 	"time"
 
@@ -14,42 +14,48 @@ import (
 
 const (
 	// Supported standard types and their type codes are as follows:
-	typeByte        = 1
-	typeShort       = 2
-	typeInt         = 3
-	typeLong        = 4
-	typeFloat       = 5
-	typeDouble      = 6
-	typeChar        = 7
-	typeBool        = 8
-	typeString      = 9
-	typeUUID        = 10
-	typeDate        = 11
-	typeByteArray   = 12
-	typeShortArray  = 13
-	typeIntArray    = 14
-	typeLongArray   = 15
-	typeFloatArray  = 16
-	typeDoubleArray = 17
-	typeCharArray   = 18
-	typeBoolArray   = 19
-	typeStringArray = 20
-	typeUUIDArray   = 21
-	typeDateArray   = 22
-	// TODO: Object array = 23
-	// TODO: Collection = 24
-	// TODO: Map = 25
+	typeByte              = 1
+	typeShort             = 2
+	typeInt               = 3
+	typeLong              = 4
+	typeFloat             = 5
+	typeDouble            = 6
+	typeChar              = 7
+	typeBool              = 8
+	typeString            = 9
+	typeUUID              = 10
+	typeDate              = 11
+	typeByteArray         = 12
+	typeShortArray        = 13
+	typeIntArray          = 14
+	typeLongArray         = 15
+	typeFloatArray        = 16
+	typeDoubleArray       = 17
+	typeCharArray         = 18
+	typeBoolArray         = 19
+	typeStringArray       = 20
+	typeUUIDArray         = 21
+	typeDateArray         = 22
+	typeObjectArray       = 23
+	typeCollection        = 24
+	typeMap               = 25
 	typeBinaryObjectArray = 27
-	// TODO: Enum = 28
-	// TODO: Enum Array = 29
-	// TODO: Decimal = 30
-	// TODO: Decimal Array = 31
-	typeTimestamp      = 33
-	typeTimestampArray = 34
-	typeTime           = 36
-	typeTimeArray      = 37
-	typeNULL           = 101
-	typeComplexObject  = 103
+	typeEnum              = 28
+	typeEnumArray         = 29
+	typeDecimal           = 30
+	typeDecimalArray      = 31
+	typeTimestamp         = 33
+	typeTimestampArray    = 34
+	typeTime              = 36
+	typeTimeArray         = 37
+	typeBinaryEnum        = 38
+	typeNULL              = 101
+	typeComplexObject     = 103
+)
+
+const (
+	// mapKindHashMap is kind of the map which is read as HashMap
+	mapKindHashMap = 1
 )
 
 const (
@@ -84,6 +90,8 @@ type Date int64
 type ComplexObject struct {
 	Type   int32
 	Fields map[int32]interface{}
+	// Raw is raw data section of the object written after the fields, nil if the object has no raw data
+	Raw []byte
 }
 
 // Set sets field value
@@ -97,6 +105,96 @@ func (c *ComplexObject) Get(field string) (interface{}, bool) {
 	return v, ok
 }
 
+// TypeName returns name of the object type resolved by r
+func (c *ComplexObject) TypeName(r BinaryTypeResolver) (string, error) {
+	t, err := r.ResolveBinaryType(c.Type)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve binary type %d", c.Type)
+	}
+	return t.TypeName, nil
+}
+
+// FieldNames returns names of the object fields resolved by r in order of the binary type fields
+func (c *ComplexObject) FieldNames(r BinaryTypeResolver) ([]string, error) {
+	fields, err := c.resolveFields(r)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(c.Fields))
+	for _, f := range fields {
+		if _, ok := c.Fields[f.ID]; ok {
+			names = append(names, f.Name)
+		}
+	}
+	return names, nil
+}
+
+// ToMap converts the object to the map of field values by field names resolved by r.
+// Nested complex objects are converted to maps too.
+func (c *ComplexObject) ToMap(r BinaryTypeResolver) (map[string]interface{}, error) {
+	fields, err := c.resolveFields(r)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, len(c.Fields))
+	for _, f := range fields {
+		v, ok := c.Fields[f.ID]
+		if !ok {
+			continue
+		}
+		switch o := v.(type) {
+		case ComplexObject:
+			if v, err = o.ToMap(r); err != nil {
+				return nil, errors.Wrapf(err, "failed to convert field %s", f.Name)
+			}
+		case *ComplexObject:
+			if o != nil {
+				if v, err = o.ToMap(r); err != nil {
+					return nil, errors.Wrapf(err, "failed to convert field %s", f.Name)
+				}
+			}
+		}
+		m[f.Name] = v
+	}
+	return m, nil
+}
+
+// resolveFields returns fields of the object type resolved by r.
+// Stale cached binary type information is reloaded if the object has unknown field.
+func (c *ComplexObject) resolveFields(r BinaryTypeResolver) ([]BinaryField, error) {
+	t, err := r.ResolveBinaryType(c.Type)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve binary type %d", c.Type)
+	}
+	id, unknown := c.unknownField(t)
+	if !unknown {
+		return t.Fields, nil
+	}
+	if l, ok := r.(binaryTypeReloader); ok {
+		if t, err = l.reloadBinaryType(c.Type); err != nil {
+			return nil, errors.Wrapf(err, "failed to reload binary type %d", c.Type)
+		}
+		if id, unknown = c.unknownField(t); !unknown {
+			return t.Fields, nil
+		}
+	}
+	return nil, errors.Errorf("field %d is not found in binary type %s", id, t.TypeName)
+}
+
+// unknownField returns ID of the object field which is not found in t, false is returned if all fields are known
+func (c *ComplexObject) unknownField(t *BinaryType) (int32, bool) {
+	known := make(map[int32]bool, len(t.Fields))
+	for _, f := range t.Fields {
+		known[f.ID] = true
+	}
+	for id := range c.Fields {
+		if !known[id] {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
 // NewComplexObject is constructor for ComplexObject
 func NewComplexObject(typeName string) ComplexObject {
 	return ComplexObject{Type: HashCode(typeName), Fields: map[int32]interface{}{}}
@@ -141,7 +239,11 @@ func WriteType(w io.Writer, code byte) error {
 
 // WriteByte writes "byte" value
 func WriteByte(w io.Writer, v byte) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	if b := writeBuffer(w); b != nil {
+		return b.WriteByte(v)
+	}
+	_, err := w.Write([]byte{v})
+	return err
 }
 
 // WriteOByte writes "byte" object value
@@ -154,7 +256,7 @@ func WriteOByte(w io.Writer, v byte) error {
 
 // WriteShort writes "short" value
 func WriteShort(w io.Writer, v int16) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeUint16(w, uint16(v))
 }
 
 // WriteOShort writes "short" object value
@@ -167,7 +269,7 @@ func WriteOShort(w io.Writer, v int16) error {
 
 // WriteInt writes "int" value
 func WriteInt(w io.Writer, v int32) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeUint32(w, uint32(v))
 }
 
 // WriteOInt writes "int" object value
@@ -180,7 +282,7 @@ func WriteOInt(w io.Writer, v int32) error {
 
 // WriteLong writes "long" value
 func WriteLong(w io.Writer, v int64) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeUint64(w, uint64(v))
 }
 
 // WriteOLong writes "long" object value
@@ -193,7 +295,7 @@ func WriteOLong(w io.Writer, v int64) error {
 
 // WriteFloat writes "float" value
 func WriteFloat(w io.Writer, v float32) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeUint32(w, math.Float32bits(v))
 }
 
 // WriteOFloat writes "float" object value
@@ -206,7 +308,7 @@ func WriteOFloat(w io.Writer, v float32) error {
 
 // WriteDouble writes "double" value
 func WriteDouble(w io.Writer, v float64) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeUint64(w, math.Float64bits(v))
 }
 
 // WriteODouble writes "double" object value
@@ -219,7 +321,7 @@ func WriteODouble(w io.Writer, v float64) error {
 
 // WriteChar writes "char" value
 func WriteChar(w io.Writer, v Char) error {
-	return binary.Write(w, binary.LittleEndian, int16(v))
+	return writeUint16(w, uint16(v))
 }
 
 // WriteOChar writes "char" object value
@@ -232,7 +334,10 @@ func WriteOChar(w io.Writer, v Char) error {
 
 // WriteBool writes "bool" value
 func WriteBool(w io.Writer, v bool) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	if v {
+		return WriteByte(w, 1)
+	}
+	return WriteByte(w, 0)
 }
 
 // WriteOBool writes "bool" object value
@@ -249,11 +354,15 @@ func WriteOString(w io.Writer, v string) error {
 	if err := WriteType(w, typeString); err != nil {
 		return err
 	}
-	s := []byte(v)
-	if err := WriteInt(w, int32(len(s))); err != nil {
+	if err := WriteInt(w, int32(len(v))); err != nil {
+		return err
+	}
+	if b := writeBuffer(w); b != nil {
+		_, err := b.WriteString(v)
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, s)
+	_, err := io.WriteString(w, v)
+	return err
 }
 
 // WriteOUUID writes "UUID" object value
@@ -263,7 +372,12 @@ func WriteOUUID(w io.Writer, v uuid.UUID) error {
 		return err
 	}
 	uuidFlip(&v)
-	return binary.Write(w, binary.LittleEndian, v)
+	if b := writeBuffer(w); b != nil {
+		_, err := b.Write(v[:])
+		return err
+	}
+	_, err := w.Write(append([]byte(nil), v[:]...))
+	return err
 }
 
 // WriteODate writes "Date" object value
@@ -276,7 +390,8 @@ func WriteODate(w io.Writer, v Date) error {
 
 // WriteBytes writes byte slice
 func WriteBytes(w io.Writer, v []byte) error {
-	return binary.Write(w, binary.LittleEndian, v)
+	_, err := w.Write(v)
+	return err
 }
 
 // WriteOArrayBytes writes "byte" array object value
@@ -287,7 +402,7 @@ func WriteOArrayBytes(w io.Writer, v []byte) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, v)
+	return WriteBytes(w, v)
 }
 
 // WriteOArrayShorts writes "short" array object value
@@ -298,7 +413,9 @@ func WriteOArrayShorts(w io.Writer, v []int16) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeArray(w, len(v), 2, func(b []byte, i int) {
+		binary.LittleEndian.PutUint16(b, uint16(v[i]))
+	})
 }
 
 // WriteOArrayInts writes "int" array object value
@@ -309,7 +426,9 @@ func WriteOArrayInts(w io.Writer, v []int32) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeArray(w, len(v), 4, func(b []byte, i int) {
+		binary.LittleEndian.PutUint32(b, uint32(v[i]))
+	})
 }
 
 // WriteOArrayLongs writes "long" array object value
@@ -320,16 +439,22 @@ func WriteOArrayLongs(w io.Writer, v []int64) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeArray(w, len(v), 8, func(b []byte, i int) {
+		binary.LittleEndian.PutUint64(b, uint64(v[i]))
+	})
 }
 
 // WriteOArrayGoInts writes "Go int" array object value
 func WriteOArrayGoInts(w io.Writer, v []int) error {
-	a := make([]int64, len(v))
-	for i, j := range v {
-		a[i] = int64(j)
+	if err := WriteType(w, typeLongArray); err != nil {
+		return err
 	}
-	return WriteOArrayLongs(w, a)
+	if err := WriteInt(w, int32(len(v))); err != nil {
+		return err
+	}
+	return writeArray(w, len(v), 8, func(b []byte, i int) {
+		binary.LittleEndian.PutUint64(b, uint64(v[i]))
+	})
 }
 
 // WriteOArrayFloats writes "float" array object value
@@ -340,7 +465,9 @@ func WriteOArrayFloats(w io.Writer, v []float32) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeArray(w, len(v), 4, func(b []byte, i int) {
+		binary.LittleEndian.PutUint32(b, math.Float32bits(v[i]))
+	})
 }
 
 // WriteOArrayDoubles writes "double" array object value
@@ -351,7 +478,9 @@ func WriteOArrayDoubles(w io.Writer, v []float64) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeArray(w, len(v), 8, func(b []byte, i int) {
+		binary.LittleEndian.PutUint64(b, math.Float64bits(v[i]))
+	})
 }
 
 // WriteOArrayChars writes "char" array object value
@@ -362,12 +491,9 @@ func WriteOArrayChars(w io.Writer, v []Char) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	for _, c := range v {
-		if err := WriteChar(w, c); err != nil {
-			return err
-		}
-	}
-	return nil
+	return writeArray(w, len(v), 2, func(b []byte, i int) {
+		binary.LittleEndian.PutUint16(b, uint16(v[i]))
+	})
 }
 
 // WriteOArrayBools writes "Bool" array object value
@@ -378,7 +504,12 @@ func WriteOArrayBools(w io.Writer, v []bool) error {
 	if err := WriteInt(w, int32(len(v))); err != nil {
 		return err
 	}
-	return binary.Write(w, binary.LittleEndian, v)
+	return writeArray(w, len(v), 1, func(b []byte, i int) {
+		b[0] = 0
+		if v[i] {
+			b[0] = 1
+		}
+	})
 }
 
 // WriteOArrayOStrings writes "String" array object value
@@ -491,79 +622,28 @@ func WriteNull(w io.Writer) error {
 	return WriteByte(w, typeNULL)
 }
 
-// WriteOComplexObject writes complex object
+// WriteOComplexObject writes complex object.
+// Fields are written in order of the schema of the type with the same set of fields:
+// the schema received from the cluster, read from the object of the type or declared by RegisterSchema.
+// Fields are written in ascending order of IDs if the schema is not known, so identical objects are written
+// as identical bytes.
 func WriteOComplexObject(w io.Writer, v ComplexObject) error {
-	// write type code
-	if err := WriteType(w, typeComplexObject); err != nil {
-		return err
-	}
-	// write version
-	if err := WriteByte(w, ComplexObjectVersion); err != nil {
-		return err
-	}
-	// write flags
-	if err := WriteShort(w, ComplexObjectHasSchema|ComplexObjectUserType); err != nil {
-		return err
-	}
-
-	// write object type ID
-	if err := WriteInt(w, v.Type); err != nil {
-		return err
-	}
-
-	// prepare schema & content
-	schema := &bytes.Buffer{}
-	fields := &bytes.Buffer{}
-	schemaID := uint32(0x811C9DC5)
-	for field, value := range v.Fields {
-		fieldID := uint32(field)
-		schemaID = schemaID ^ (fieldID & 0xFF)
-		schemaID = schemaID * uint32(0x01000193)
-		schemaID = schemaID ^ ((fieldID >> 8) & 0xFF)
-		schemaID = schemaID * uint32(0x01000193)
-		schemaID = schemaID ^ ((fieldID >> 16) & 0xFF)
-		schemaID = schemaID * uint32(0x01000193)
-		schemaID = schemaID ^ ((fieldID >> 24) & 0xFF)
-		schemaID = schemaID * uint32(0x01000193)
-		if err := WriteInt(schema, field); err != nil {
-			return errors.Wrapf(err, "failed to write field ID with hash %d", field)
-		}
-		if err := WriteInt(schema, int32(ComplexObjectHeaderLength+fields.Len())); err != nil {
-			return errors.Wrapf(err, "failed to write field offset with hash %d", field)
-		}
-		if err := WriteObject(fields, value); err != nil {
+	c := NewComplexObjectWriter(v.Type)
+	c.registry, _ = w.(schemaRegistry)
+	c.schema = schemas.getOrAdd(v.Type, v.Fields)
+	for _, field := range c.schema.fields {
+		if err := WriteObject(c.Field(field), v.Fields[field]); err != nil {
+			putBuffer(c.fields)
 			return errors.Wrapf(err, "failed to write field value with hash %d", field)
 		}
 	}
-	schemaOffset := ComplexObjectHeaderLength + fields.Len()
-
-	// write hash code, Java-style hash of contents without header, necessary for comparisons.
-	if err := WriteInt(w, HashCodeForSlice(fields.Bytes())); err != nil {
-		return err
-	}
-
-	// write length, including header
-	if err := WriteInt(w, int32(ComplexObjectHeaderLength+fields.Len()+schema.Len())); err != nil {
-		return err
-	}
-
-	// write schema Id
-	if err := WriteInt(w, int32(schemaID)); err != nil {
-		return err
-	}
-
-	// write schema offset from the header start, position where fields end.
-	if err := WriteInt(w, int32(schemaOffset)); err != nil {
-		return err
-	}
-
-	// write fields value
-	if err := WriteBytes(w, fields.Bytes()); err != nil {
-		return err
+	if v.Raw != nil {
+		if err := WriteBytes(c.Raw(), v.Raw); err != nil {
+			putBuffer(c.fields)
+			return errors.Wrapf(err, "failed to write raw data")
+		}
 	}
-
-	// write structure of schema
-	return WriteBytes(w, schema.Bytes())
+	return c.WriteObject(w)
 }
 
 // WriteObject writes object
@@ -572,7 +652,14 @@ func WriteObject(w io.Writer, o interface{}) error {
 		return WriteNull(w)
 	}
 
-	if v := reflect.ValueOf(o); v.Kind() == reflect.Ptr {
+	v := reflect.ValueOf(o)
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return WriteNull(w)
+	}
+	if m, ok := o.(BinaryMarshaler); ok {
+		return m.WriteBinary(w)
+	}
+	if v.Kind() == reflect.Ptr {
 		return WriteObject(w, v.Elem().Interface())
 	}
 
@@ -634,61 +721,111 @@ func WriteObject(w io.Writer, o interface{}) error {
 		return WriteOTime(w, v)
 	case []Time:
 		return WriteOArrayOTimes(w, v)
+	case Decimal:
+		return WriteODecimal(w, v)
+	case []*Decimal:
+		return WriteOArrayODecimals(w, v)
+	case []Decimal:
+		a := make([]*Decimal, len(v))
+		for i := range v {
+			a[i] = &v[i]
+		}
+		return WriteOArrayODecimals(w, a)
 	case ComplexObject:
 		return WriteOComplexObject(w, v)
 	case *ComplexObject:
 		return WriteOComplexObject(w, *v)
+	case []interface{}:
+		return WriteOArrayObjects(w, v)
+	case Enum:
+		return WriteOEnum(w, v)
+	case []*Enum:
+		return WriteOArrayOEnums(w, v)
+	case []Enum:
+		a := make([]*Enum, len(v))
+		for i := range v {
+			a[i] = &v[i]
+		}
+		return WriteOArrayOEnums(w, a)
+	case Collection:
+		return WriteOCollection(w, v)
+	case Map:
+		return WriteOMap(w, v)
+	}
+
+	// Go structs, slices, maps and types based on the supported ones
+	switch reflect.TypeOf(o).Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map, reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		v, err := marshalValue(reflect.ValueOf(o), "")
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal object of type %T", o)
+		}
+		return WriteObject(w, v)
 	default:
-		return errors.Errorf("unsupported object type: %s", reflect.TypeOf(v).Name())
+		return errors.Errorf("unsupported object type: %T", o)
 	}
 }
 
 // ReadByte reads "byte" value
 func ReadByte(r io.Reader) (byte, error) {
-	var v byte
-	err := binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	b, err := readNext(r, 1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
 }
 
 // ReadShort reads "short" value
 func ReadShort(r io.Reader) (int16, error) {
-	var v int16
-	err := binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	b, err := readNext(r, 2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.LittleEndian.Uint16(b)), nil
 }
 
 // ReadInt reads "int" value
 func ReadInt(r io.Reader) (int32, error) {
-	var v int32
-	err := binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	b, err := readNext(r, 4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
 }
 
 // ReadLong reads "long" value
 func ReadLong(r io.Reader) (int64, error) {
-	var v int64
-	err := binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	b, err := readNext(r, 8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
 }
 
 // ReadFloat reads "float" value
 func ReadFloat(r io.Reader) (float32, error) {
-	var v float32
-	err := binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	b, err := readNext(r, 4)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
 }
 
 // ReadDouble reads "Double" value
 func ReadDouble(r io.Reader) (float64, error) {
-	var v float64
-	err := binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	b, err := readNext(r, 8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
 }
 
 // ReadChar reads "char" value
 func ReadChar(r io.Reader) (Char, error) {
-	var v int16
-	err := binary.Read(r, binary.LittleEndian, &v)
+	v, err := ReadShort(r)
 	return Char(v), err
 }
 
@@ -715,8 +852,8 @@ func ReadString(r io.Reader) (string, error) {
 		return "", err
 	}
 	if l > 0 {
-		s := make([]byte, l)
-		if err = binary.Read(r, binary.LittleEndian, &s); err != nil {
+		s, err := readNext(r, int(l))
+		if err != nil {
 			return "", err
 		}
 		return string(s), nil
@@ -741,12 +878,32 @@ func ReadOString(r io.Reader) (string, error) {
 	}
 }
 
+// ReadOUUID reads "UUID" object value or NULL
+func ReadOUUID(r io.Reader) (uuid.UUID, error) {
+	t, err := ReadByte(r)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	switch t {
+	case typeNULL:
+		return uuid.UUID{}, nil
+	case typeUUID:
+		return ReadUUID(r)
+	default:
+		return uuid.UUID{}, errors.Errorf("invalid type (expected %d, but got %d)", typeUUID, t)
+	}
+}
+
 // ReadUUID reads "UUID" object value
 func ReadUUID(r io.Reader) (uuid.UUID, error) {
 	var o uuid.UUID
-	err := binary.Read(r, binary.LittleEndian, &o)
+	b, err := readNext(r, len(o))
+	if err != nil {
+		return o, err
+	}
+	copy(o[:], b)
 	uuidFlip(&o)
-	return o, err
+	return o, nil
 }
 
 // ReadDate reads "Date" object value
@@ -760,182 +917,198 @@ func ReadDate(r io.Reader) (time.Time, error) {
 
 // ReadArrayBytes reads "byte" array value
 func ReadArrayBytes(r io.Reader) ([]byte, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 1)
 	if err != nil {
 		return nil, err
 	}
-	b := make([]byte, l)
-	if l > 0 {
-		err = binary.Read(r, binary.LittleEndian, &b)
+	return readBytes(r, l)
+}
+
+// ReadOArrayBytes reads "byte" array object value or NULL
+func ReadOArrayBytes(r io.Reader) ([]byte, error) {
+	t, err := ReadByte(r)
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case typeNULL:
+		return nil, nil
+	case typeByteArray:
+		return ReadArrayBytes(r)
+	default:
+		return nil, errors.Errorf("invalid type (expected %d, but got %d)", typeByteArray, t)
 	}
-	return b, err
 }
 
 // ReadArrayShorts reads "short" array value
 func ReadArrayShorts(r io.Reader) ([]int16, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 2)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNext(r, l*2)
 	if err != nil {
 		return nil, err
 	}
 	b := make([]int16, l)
-	if l > 0 {
-		err = binary.Read(r, binary.LittleEndian, &b)
+	for i := range b {
+		b[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
 	}
-	return b, err
+	return b, nil
 }
 
 // ReadArrayInts reads "int" array value
 func ReadArrayInts(r io.Reader) ([]int32, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 4)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNext(r, l*4)
 	if err != nil {
 		return nil, err
 	}
 	b := make([]int32, l)
-	if l > 0 {
-		err = binary.Read(r, binary.LittleEndian, &b)
+	for i := range b {
+		b[i] = int32(binary.LittleEndian.Uint32(data[i*4:]))
 	}
-	return b, err
+	return b, nil
 }
 
 // ReadArrayLongs reads "long" array value
 func ReadArrayLongs(r io.Reader) ([]int64, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 8)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNext(r, l*8)
 	if err != nil {
 		return nil, err
 	}
 	b := make([]int64, l)
-	if l > 0 {
-		err = binary.Read(r, binary.LittleEndian, &b)
+	for i := range b {
+		b[i] = int64(binary.LittleEndian.Uint64(data[i*8:]))
 	}
-	return b, err
+	return b, nil
 }
 
 // ReadArrayFloats reads "float" array value
 func ReadArrayFloats(r io.Reader) ([]float32, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 4)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNext(r, l*4)
 	if err != nil {
 		return nil, err
 	}
 	b := make([]float32, l)
-	if l > 0 {
-		err = binary.Read(r, binary.LittleEndian, &b)
+	for i := range b {
+		b[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
 	}
-	return b, err
+	return b, nil
 }
 
 // ReadArrayDoubles reads "double" array value
 func ReadArrayDoubles(r io.Reader) ([]float64, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 8)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNext(r, l*8)
 	if err != nil {
 		return nil, err
 	}
 	b := make([]float64, l)
-	if l > 0 {
-		err = binary.Read(r, binary.LittleEndian, &b)
+	for i := range b {
+		b[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
 	}
-	return b, err
+	return b, nil
 }
 
 // ReadArrayChars reads "char" array value
 func ReadArrayChars(r io.Reader) ([]Char, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 2)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNext(r, l*2)
 	if err != nil {
 		return nil, err
 	}
 	b := make([]Char, l)
-	for i := 0; i < int(l); i++ {
-		if b[i], err = ReadChar(r); err != nil {
-			return nil, err
-		}
+	for i := range b {
+		b[i] = Char(binary.LittleEndian.Uint16(data[i*2:]))
 	}
 	return b, nil
 }
 
 // ReadArrayBools reads "bool" array value
 func ReadArrayBools(r io.Reader) ([]bool, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNext(r, l)
 	if err != nil {
 		return nil, err
 	}
 	b := make([]bool, l)
-	if l > 0 {
-		err = binary.Read(r, binary.LittleEndian, &b)
+	for i := range b {
+		b[i] = data[i] != 0
 	}
-	return b, err
+	return b, nil
 }
 
 // ReadArrayOStrings reads "String" array value
 func ReadArrayOStrings(r io.Reader) ([]string, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 1)
 	if err != nil {
 		return nil, err
 	}
-	b := make([]string, l)
-	for i := 0; i < int(l); i++ {
-		if b[i], err = ReadOString(r); err != nil {
+	b := make([]string, 0, capacity(r, l))
+	for i := 0; i < l; i++ {
+		v, err := ReadOString(r)
+		if err != nil {
 			return nil, err
 		}
+		b = append(b, v)
 	}
 	return b, nil
 }
 
 // ReadArrayOUUIDs reads "UUID" array value
 func ReadArrayOUUIDs(r io.Reader) ([]uuid.UUID, error) {
-	l, err := ReadInt(r)
+	l, err := readLength(r, 1)
 	if err != nil {
 		return nil, err
 	}
-	b := make([]uuid.UUID, l)
-	for i := 0; i < int(l); i++ {
+	b := make([]uuid.UUID, 0, capacity(r, l))
+	for i := 0; i < l; i++ {
 		o, err := ReadObject(r)
 		if err != nil {
 			return nil, err
 		}
-		b[i] = o.(uuid.UUID)
+		v, ok := o.(uuid.UUID)
+		if !ok && o != nil {
+			return nil, errors.Errorf("invalid type %T of element with index %d", o, i)
+		}
+		b = append(b, v)
 	}
 	return b, nil
 }
 
 // ReadArrayODates reads "Date" array value
 func ReadArrayODates(r io.Reader) ([]time.Time, error) {
-	l, err := ReadInt(r)
-	if err != nil {
-		return nil, err
-	}
-	b := make([]time.Time, l)
-	for i := 0; i < int(l); i++ {
-		o, err := ReadObject(r)
-		if err != nil {
-			return nil, err
-		}
-		b[i] = o.(time.Time)
-	}
-	return b, nil
+	return readArrayOTimes(r)
 }
 
 // ReadArrayBinaryObject reads "binary object" value wrapped by array
 func ReadArrayBinaryObject(r io.Reader) (interface{}, error) {
-	// read byte array size
-	l, err := ReadInt(r)
-	if err != nil {
-		return nil, err
-	}
-
-	// read byte array
-	b := make([]byte, l)
-	if err = binary.Read(r, binary.LittleEndian, &b); err != nil {
-		return nil, err
-	}
-
-	// read object offset
-	o, err := ReadInt(r)
+	o, err := ReadBinaryObject(r)
 	if err != nil {
 		return nil, err
 	}
-
-	// read object
-	buf := bytes.NewBuffer(b[int(o):])
-	return ReadObject(buf)
+	return o.Deserialize()
 }
 
 // ReadTimestamp reads "Timestamp" object value
@@ -955,19 +1128,7 @@ func ReadTimestamp(r io.Reader) (time.Time, error) {
 
 // ReadArrayOTimestamps reads "Timestamp" array value
 func ReadArrayOTimestamps(r io.Reader) ([]time.Time, error) {
-	l, err := ReadInt(r)
-	if err != nil {
-		return nil, err
-	}
-	b := make([]time.Time, l)
-	for i := 0; i < int(l); i++ {
-		o, err := ReadObject(r)
-		if err != nil {
-			return nil, err
-		}
-		b[i] = o.(time.Time)
-	}
-	return b, nil
+	return readArrayOTimes(r)
 }
 
 // ReadTime reads "Time" object value
@@ -982,74 +1143,128 @@ func ReadTime(r io.Reader) (time.Time, error) {
 
 // ReadArrayOTimes reads "Time" array value
 func ReadArrayOTimes(r io.Reader) ([]time.Time, error) {
-	l, err := ReadInt(r)
+	return readArrayOTimes(r)
+}
+
+// readArrayOTimes reads array of "Date", "Timestamp" or "Time" values, NULL elements are read as zero time
+func readArrayOTimes(r io.Reader) ([]time.Time, error) {
+	l, err := readLength(r, 1)
 	if err != nil {
 		return nil, err
 	}
-	b := make([]time.Time, l)
-	for i := 0; i < int(l); i++ {
+	b := make([]time.Time, 0, capacity(r, l))
+	for i := 0; i < l; i++ {
 		o, err := ReadObject(r)
 		if err != nil {
 			return nil, err
 		}
-		b[i] = o.(time.Time)
+		v, ok := o.(time.Time)
+		if !ok && o != nil {
+			return nil, errors.Errorf("invalid type %T of element with index %d", o, i)
+		}
+		b = append(b, v)
 	}
 	return b, nil
 }
 
 // ReadComplexObject reads "complex object" value
 func ReadComplexObject(r io.Reader) (ComplexObject, error) {
+	c := ComplexObject{Fields: map[int32]interface{}{}}
+	typeID, raw, err := readComplexObject(r, func(fieldID int32, field io.Reader) error {
+		o, err := ReadObject(field)
+		if err != nil {
+			return err
+		}
+		c.Fields[fieldID] = o
+		return nil
+	})
+	if err != nil {
+		return ComplexObject{}, err
+	}
+	c.Type = typeID
+	c.Raw = raw
+	return c, nil
+}
+
+// readComplexObject reads "complex object" value and passes data of every field to fn.
+// Returns type ID and raw data of the object, raw data is nil if the object has no raw data section.
+// Returns type ID of the object.
+func readComplexObject(r io.Reader, fn func(fieldID int32, field io.Reader) error) (int32, []byte, error) {
 	// read version, always 1
 	ver, err := ReadByte(r)
 	if err != nil {
-		return ComplexObject{}, err
+		return 0, nil, err
 	}
 	if ver != ComplexObjectVersion {
-		return ComplexObject{}, errors.Errorf("invalid complex object version %d, but expected %d", ver, ComplexObjectVersion)
+		return 0, nil, errors.Errorf("invalid complex object version %d, but expected %d", ver, ComplexObjectVersion)
 	}
 
 	// read flags
 	flags, err := ReadShort(r)
 	if err != nil {
-		return ComplexObject{}, err
+		return 0, nil, err
 	}
 
 	// read Type id, Java-style hash code of the type name
 	typeID, err := ReadInt(r)
 	if err != nil {
-		return ComplexObject{}, err
+		return 0, nil, err
 	}
 
 	// read hash code, Java-style hash of contents without header, necessary for comparisons
 	if _, err = ReadInt(r); err != nil {
-		return ComplexObject{}, err
+		return 0, nil, err
 	}
 
 	// read length, including header
 	size, err := ReadInt(r)
 	if err != nil {
-		return ComplexObject{}, err
+		return 0, nil, err
 	}
 
 	// read schema Id
-	if _, err = ReadInt(r); err != nil {
-		return ComplexObject{}, err
+	schemaID, err := ReadInt(r)
+	if err != nil {
+		return 0, nil, err
 	}
 
 	// read schema offset from the header start, position where fields end
 	schemaOffset, err := ReadInt(r)
 	if err != nil {
-		return ComplexObject{}, err
+		return 0, nil, err
+	}
+
+	hasSchema := flags&ComplexObjectHasSchema != 0
+	hasRaw := flags&ComplexObjectHasRaw != 0
+	// raw data is placed between fields and footer
+	rawOffset := size
+	footerEnd := size
+	if !hasSchema {
+		// object has no fields and footer, raw data offset is written instead of schema offset
+		if hasRaw {
+			rawOffset = schemaOffset
+		}
+		schemaOffset = size
+	} else if hasRaw {
+		// raw data offset follows the footer
+		footerEnd -= 4
+	}
+	if schemaOffset < ComplexObjectHeaderLength || schemaOffset > footerEnd {
+		return 0, nil, errors.Errorf("invalid complex object schema offset %d, object length is %d", schemaOffset, size)
+	}
+	// header is already read
+	if err = checkLength(r, size-ComplexObjectHeaderLength, 1); err != nil {
+		return 0, nil, errors.Wrapf(err, "invalid complex object length %d", size)
 	}
 
 	// read fields
-	fields := make([]byte, schemaOffset-ComplexObjectHeaderLength)
-	if err = binary.Read(r, binary.LittleEndian, &fields); err != nil {
-		return ComplexObject{}, err
+	fields, err := readBytes(r, int(schemaOffset-ComplexObjectHeaderLength))
+	if err != nil {
+		return 0, nil, err
 	}
 
 	// read field schemas and data
-	left := size - schemaOffset
+	left := footerEnd - schemaOffset
 	var step int32
 	if flags&ComplexObjectOffsetOneByte != 0 {
 		step = 1
@@ -1058,19 +1273,35 @@ func ReadComplexObject(r io.Reader) (ComplexObject, error) {
 	} else {
 		step = 4
 	}
+	// compact footer has no field IDs, they are taken from the known schema
+	var compact *schema
+	if flags&ComplexObjectHasSchema != 0 && flags&ComplexObjectCompactFooter != 0 {
+		if compact, err = lookupSchema(resolverOf(r), typeID, schemaID); err != nil {
+			return 0, nil, err
+		}
+	}
+	// schema of the object is cached to write objects of the type in the same field order,
+	// it does not replace the order the objects with the same set of fields are already written in
+	var ids []int32
+	cache := flags&ComplexObjectHasSchema != 0 && compact == nil && schemas.lookup(typeID, schemaID) == nil
 	i := int32(1)
-	c := ComplexObject{Type: typeID, Fields: map[int32]interface{}{}}
 	for left > 0 {
 		var fieldID int32
 		if flags&ComplexObjectCompactFooter == 0 {
 			// get field ID
 			fieldID, err = ReadInt(r)
 			if err != nil {
-				return ComplexObject{}, errors.Wrapf(err, "failed to read field ID with index %d", i)
+				return 0, nil, errors.Wrapf(err, "failed to read field ID with index %d", i)
 			}
 			left -= 4
+			if cache {
+				ids = append(ids, fieldID)
+			}
 		} else {
-			fieldID = i
+			if int(i) > len(compact.fields) {
+				return 0, nil, errors.Errorf("object has more fields than schema %d of binary type %d", schemaID, typeID)
+			}
+			fieldID = compact.fields[i-1]
 		}
 
 		// get field offset
@@ -1079,35 +1310,50 @@ func ReadComplexObject(r io.Reader) (ComplexObject, error) {
 		case 1:
 			offset, err := ReadByte(r)
 			if err != nil {
-				return ComplexObject{}, errors.Wrapf(err, "failed to read field offset with index %d", i)
+				return 0, nil, errors.Wrapf(err, "failed to read field offset with index %d", i)
 			}
 			fieldOffset = int(offset)
 		case 2:
 			offset, err := ReadShort(r)
 			if err != nil {
-				return ComplexObject{}, errors.Wrapf(err, "failed to read field offset with index %d", i)
+				return 0, nil, errors.Wrapf(err, "failed to read field offset with index %d", i)
 			}
-			fieldOffset = int(offset)
+			fieldOffset = int(uint16(offset))
 		default:
 			offset, err := ReadInt(r)
 			if err != nil {
-				return ComplexObject{}, errors.Wrapf(err, "failed to read field offset with index %d", i)
+				return 0, nil, errors.Wrapf(err, "failed to read field offset with index %d", i)
 			}
 			fieldOffset = int(offset)
 		}
 		left -= step
 
 		// read field data
-		field := bytes.NewBuffer(fields[fieldOffset-ComplexObjectHeaderLength:])
-		o, err := ReadObject(field)
-		if err != nil {
-			return ComplexObject{}, errors.Wrapf(err, "failed to read field data with index %d", i)
+		if fieldOffset < ComplexObjectHeaderLength || fieldOffset > int(schemaOffset) {
+			return 0, nil, errors.Errorf("invalid offset %d of field with index %d", fieldOffset, i)
+		}
+		field := &decoder{b: fields[fieldOffset-ComplexObjectHeaderLength:], resolver: resolverOf(r)}
+		if err = fn(fieldID, field); err != nil {
+			return 0, nil, errors.Wrapf(err, "failed to read field data with index %d", i)
 		}
-		c.Fields[fieldID] = o
 		i++
 	}
+	if cache {
+		schemas.put(typeID, ids, false, false)
+	}
 
-	return c, nil
+	if !hasRaw {
+		return typeID, nil, nil
+	}
+	if hasSchema {
+		if rawOffset, err = ReadInt(r); err != nil {
+			return 0, nil, errors.Wrapf(err, "failed to read raw data offset")
+		}
+	}
+	if rawOffset < ComplexObjectHeaderLength || rawOffset > schemaOffset {
+		return 0, nil, errors.Errorf("invalid complex object raw data offset %d, schema offset is %d", rawOffset, schemaOffset)
+	}
+	return typeID, fields[rawOffset-ComplexObjectHeaderLength:], nil
 }
 
 // ReadObject read object
@@ -1116,7 +1362,11 @@ func ReadObject(r io.Reader) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	return readObject(r, t)
+}
 
+// readObject reads object value with type code t
+func readObject(r io.Reader, t byte) (interface{}, error) {
 	switch t {
 	case typeByte:
 		return ReadByte(r)
@@ -1172,10 +1422,24 @@ func ReadObject(r io.Reader) (interface{}, error) {
 		return ReadTime(r)
 	case typeTimeArray:
 		return ReadArrayOTimes(r)
+	case typeDecimal:
+		return ReadDecimal(r)
+	case typeDecimalArray:
+		return ReadArrayODecimals(r)
 	case typeNULL:
 		return nil, nil
 	case typeComplexObject:
 		return ReadComplexObject(r)
+	case typeObjectArray:
+		return ReadArrayObjects(r)
+	case typeEnum, typeBinaryEnum:
+		return ReadEnum(r)
+	case typeEnumArray:
+		return ReadArrayOEnums(r)
+	case typeCollection:
+		return ReadCollection(r)
+	case typeMap:
+		return ReadMap(r)
 	default:
 		return nil, errors.Errorf("unsupported object type: %d", t)
 	}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
 )
 
 func TestWriteByte(t *testing.T) {
@@ -1215,6 +1217,85 @@ func TestWriteOComplexObject(t *testing.T) {
 	}
 }
 
+func TestComplexObject_Raw(t *testing.T) {
+	// objects written by Java binary writer with raw data section
+	tests := []struct {
+		name    string
+		data    []byte
+		want    ComplexObject
+		wantErr bool
+	}{
+		{
+			name: "fields and raw data",
+			data: []byte{
+				103, 1, 15, 0, // type code, version, flags
+				112, 101, 155, 4, // type ID
+				142, 215, 37, 240, // hash code
+				58, 0, 0, 0, // length
+				52, 216, 163, 242, // schema ID
+				44, 0, 0, 0, // schema offset
+				3, 1, 0, 0, 0, 3, 2, 0, 0, 0, // fields
+				10, 0, 0, 0, 9, 1, 0, 0, 0, 97, // raw data
+				120, 0, 0, 0, 24, 121, 0, 0, 0, 29, // footer
+				34, 0, 0, 0, // raw data offset
+			},
+			want: ComplexObject{
+				Type:   HashCode("Point"),
+				Fields: map[int32]interface{}{HashCode("x"): int32(1), HashCode("y"): int32(2)},
+				Raw:    []byte{10, 0, 0, 0, 9, 1, 0, 0, 0, 97},
+			},
+		},
+		{
+			name: "raw data only",
+			data: []byte{
+				103, 1, 5, 0, // type code, version, flags
+				212, 143, 247, 157, // type ID
+				153, 46, 3, 0, // hash code
+				28, 0, 0, 0, // length
+				0, 0, 0, 0, // schema ID
+				24, 0, 0, 0, // raw data offset
+				7, 0, 0, 0, // raw data
+			},
+			want: ComplexObject{
+				Type:   HashCode("RawOnly"),
+				Fields: map[int32]interface{}{},
+				Raw:    []byte{7, 0, 0, 0},
+			},
+		},
+		{
+			name: "invalid raw data offset",
+			data: []byte{
+				103, 1, 15, 0, 112, 101, 155, 4, 142, 215, 37, 240, 58, 0, 0, 0, 52, 216, 163, 242, 44, 0, 0, 0,
+				3, 1, 0, 0, 0, 3, 2, 0, 0, 0, 10, 0, 0, 0, 9, 1, 0, 0, 0, 97,
+				120, 0, 0, 0, 24, 121, 0, 0, 0, 29,
+				45, 0, 0, 0,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadObject(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadObject() = %v, want %v", got, tt.want)
+			}
+			w := &bytes.Buffer{}
+			if err := WriteObject(w, got); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(w.Bytes(), tt.data) {
+				t.Errorf("WriteObject() = %v, want %v", w.Bytes(), tt.data)
+			}
+		})
+	}
+}
+
 func TestWriteObject(t *testing.T) {
 	byteVal := byte(123)
 	shortVal := int16(12345)
@@ -2681,3 +2762,96 @@ func Test_response_ReadObject(t *testing.T) {
 		})
 	}
 }
+
+// testBinaryTypes is BinaryTypeResolver with the fixed binary types
+type testBinaryTypes map[int32]*BinaryType
+
+func (r testBinaryTypes) ResolveBinaryType(typeID int32) (*BinaryType, error) {
+	t, ok := r[typeID]
+	if !ok {
+		return nil, errors.Errorf("binary type %d is not registered", typeID)
+	}
+	return t, nil
+}
+
+func TestComplexObject_ToMap(t *testing.T) {
+	types := testBinaryTypes{
+		HashCode("Person"): {
+			TypeID:   HashCode("Person"),
+			TypeName: "Person",
+			Fields: []BinaryField{
+				{Name: "name", TypeCode: typeString, ID: HashCode("name")},
+				{Name: "age", TypeCode: typeInt, ID: HashCode("age")},
+				{Name: "address", TypeCode: typeComplexObject, ID: HashCode("address")},
+			},
+		},
+		HashCode("Address"): {
+			TypeID:   HashCode("Address"),
+			TypeName: "Address",
+			Fields:   []BinaryField{{Name: "city", TypeCode: typeString, ID: HashCode("city")}},
+		},
+	}
+	address := NewComplexObject("Address")
+	address.Set("city", "Moscow")
+	person := NewComplexObject("Person")
+	person.Set("address", address)
+	person.Set("name", "Ivan")
+	unknown := NewComplexObject("Person")
+	unknown.Set("salary", int64(100))
+
+	tests := []struct {
+		name         string
+		o            ComplexObject
+		wantTypeName string
+		wantNames    []string
+		want         map[string]interface{}
+		wantErr      bool
+	}{
+		{
+			name:         "nested",
+			o:            person,
+			wantTypeName: "Person",
+			wantNames:    []string{"name", "address"},
+			want: map[string]interface{}{
+				"name":    "Ivan",
+				"address": map[string]interface{}{"city": "Moscow"},
+			},
+		},
+		{
+			name:         "unknown field",
+			o:            unknown,
+			wantTypeName: "Person",
+			wantErr:      true,
+		},
+		{
+			name:    "unknown type",
+			o:       NewComplexObject("Unknown"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeName, err := tt.o.TypeName(types)
+			if (err != nil) != (tt.wantTypeName == "") {
+				t.Errorf("ComplexObject.TypeName() error = %v", err)
+			}
+			if typeName != tt.wantTypeName {
+				t.Errorf("ComplexObject.TypeName() = %v, want %v", typeName, tt.wantTypeName)
+			}
+			names, err := tt.o.FieldNames(types)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ComplexObject.FieldNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("ComplexObject.FieldNames() = %v, want %v", names, tt.wantNames)
+			}
+			got, err := tt.o.ToMap(types)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ComplexObject.ToMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ComplexObject.ToMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
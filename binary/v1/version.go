@@ -0,0 +1,78 @@
+package ignite
+
+import (
+	"fmt"
+)
+
+// supportedVersions contains protocol versions supported by the client from the highest to the lowest
+var supportedVersions = []ProtocolVersion{
+	{Major: 1, Minor: 7, Patch: 0},
+	{Major: 1, Minor: 6, Patch: 0},
+	{Major: 1, Minor: 5, Patch: 0},
+	{Major: 1, Minor: 4, Patch: 0},
+	{Major: 1, Minor: 3, Patch: 0},
+	{Major: 1, Minor: 2, Patch: 0},
+	{Major: 1, Minor: 1, Patch: 0},
+	{Major: 1, Minor: 0, Patch: 0},
+}
+
+var (
+	// credentialsVersion is the first protocol version with credentials in the handshake request
+	credentialsVersion = ProtocolVersion{Major: 1, Minor: 1, Patch: 0}
+	// nodeIDVersion is the first protocol version with node ID in the handshake response
+	nodeIDVersion = ProtocolVersion{Major: 1, Minor: 4, Patch: 0}
+	// userAttributesVersion is the first protocol version with user attributes in the handshake request
+	userAttributesVersion = ProtocolVersion{Major: 1, Minor: 5, Patch: 0}
+	// transactionsVersion is the first protocol version with transactions
+	transactionsVersion = ProtocolVersion{Major: 1, Minor: 5, Patch: 0}
+	// expiryPolicyVersion is the first protocol version with expiry policy in the cache configuration
+	expiryPolicyVersion = ProtocolVersion{Major: 1, Minor: 6, Patch: 0}
+	// featuresVersion is the first protocol version with features bitmask in the handshake
+	featuresVersion = ProtocolVersion{Major: 1, Minor: 7, Patch: 0}
+)
+
+// ProtocolVersion is version of the binary client protocol
+type ProtocolVersion struct {
+	Major, Minor, Patch int
+}
+
+// Less returns true if version v is lower than version o
+func (v ProtocolVersion) Less(o ProtocolVersion) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// String returns version in "major.minor.patch" form
+func (v ProtocolVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// version returns protocol version requested by the client.
+// The highest supported version is used if version is not set.
+func (ci *ConnInfo) version() ProtocolVersion {
+	if ci.Major == 0 && ci.Minor == 0 && ci.Patch == 0 {
+		return supportedVersions[0]
+	}
+	return ProtocolVersion{Major: ci.Major, Minor: ci.Minor, Patch: ci.Patch}
+}
+
+// negotiate returns the highest version supported by the client and by the server
+// which is lower than the version rejected by the server.
+// Returns false if there is no such version or if the server rejected its own version
+// (e.g. because of the authentication failure).
+func negotiate(rejected, server ProtocolVersion) (ProtocolVersion, bool) {
+	if server == rejected {
+		return ProtocolVersion{}, false
+	}
+	for _, v := range supportedVersions {
+		if v.Less(rejected) && !server.Less(v) {
+			return v, true
+		}
+	}
+	return ProtocolVersion{}, false
+}
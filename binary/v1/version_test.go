@@ -0,0 +1,126 @@
+package ignite
+
+import (
+	"testing"
+)
+
+func Test_negotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rejected ProtocolVersion
+		server   ProtocolVersion
+		want     ProtocolVersion
+		wantOk   bool
+	}{
+		{
+			name:     "server version",
+			rejected: ProtocolVersion{Major: 1, Minor: 4},
+			server:   ProtocolVersion{Major: 1, Minor: 2},
+			want:     ProtocolVersion{Major: 1, Minor: 2},
+			wantOk:   true,
+		},
+		{
+			name:     "unknown server patch version",
+			rejected: ProtocolVersion{Major: 1, Minor: 4},
+			server:   ProtocolVersion{Major: 1, Minor: 3, Patch: 5},
+			want:     ProtocolVersion{Major: 1, Minor: 3},
+			wantOk:   true,
+		},
+		{
+			name:     "newer server version",
+			rejected: ProtocolVersion{Major: 1, Minor: 1},
+			server:   ProtocolVersion{Major: 2, Minor: 0},
+			want:     ProtocolVersion{Major: 1, Minor: 0},
+			wantOk:   true,
+		},
+		{
+			name:     "no common version",
+			rejected: ProtocolVersion{Major: 1, Minor: 0},
+			server:   ProtocolVersion{Major: 1, Minor: 0},
+		},
+		{
+			name:     "rejected server version",
+			rejected: ProtocolVersion{Major: 1, Minor: 7},
+			server:   ProtocolVersion{Major: 1, Minor: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := negotiate(tt.rejected, tt.server)
+			if ok != tt.wantOk {
+				t.Fatalf("negotiate() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("negotiate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnect_ProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		server ProtocolVersion
+		// requested version, the highest supported version is requested if it is not set
+		requested ProtocolVersion
+		want      ProtocolVersion
+		// length of the last handshake request
+		wantHandshake int
+		wantErr       bool
+	}{
+		{
+			name:          "requested version is supported",
+			server:        ProtocolVersion{Major: 1, Minor: 1},
+			requested:     ProtocolVersion{Major: 1, Minor: 1},
+			want:          ProtocolVersion{Major: 1, Minor: 1},
+			wantHandshake: 4 + 8 + 2*5,
+		},
+		{
+			name:          "highest common version",
+			server:        ProtocolVersion{Major: 1, Minor: 2},
+			want:          ProtocolVersion{Major: 1, Minor: 2},
+			wantHandshake: 4 + 8 + 2*5,
+		},
+		{
+			name:          "no credentials in 1.0.0",
+			server:        ProtocolVersion{Major: 1, Minor: 0},
+			requested:     ProtocolVersion{Major: 1, Minor: 1},
+			want:          ProtocolVersion{Major: 1, Minor: 0},
+			wantHandshake: 4 + 8,
+		},
+		{
+			name:    "unsupported server version",
+			server:  ProtocolVersion{Major: 0, Minor: 9},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServerVersion(t, tt.server, func(conn int, code int16, data []byte) ([]byte, error) {
+				return []byte{0, 0, 0, 0}, nil
+			})
+			defer s.close()
+
+			ci := s.connInfo()
+			ci.Major, ci.Minor, ci.Patch = tt.requested.Major, tt.requested.Minor, tt.requested.Patch
+			c, err := Connect(ci)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Connect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			if got := c.ProtocolVersion(); got != tt.want {
+				t.Errorf("Client.ProtocolVersion() = %v, want %v", got, tt.want)
+			}
+			if got := len(s.handshake(s.accepted() - 1)); got != tt.wantHandshake {
+				t.Errorf("handshake request length = %v, want %v", got, tt.wantHandshake)
+			}
+			if _, err = c.CacheGetNames(); err != nil {
+				t.Errorf("Client.CacheGetNames() error = %v", err)
+			}
+		})
+	}
+}
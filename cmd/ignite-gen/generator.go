@@ -0,0 +1,509 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amsokol/ignite-go-client/binary/v1"
+)
+
+const (
+	// annotation is comment which marks structs to generate codecs for
+	annotation = "//ignite:binary"
+
+	// tagName is key of the struct field tag with the mapping of the field
+	tagName = "ignite"
+
+	uuidPath = "github.com/google/uuid"
+)
+
+// wireType is Go type of the object value written and read by the ignite package
+type wireType struct {
+	// typ is Go type of the value returned by ignite.ReadObject
+	typ string
+	// write is ignite function which writes the value with type code
+	write string
+	// imports are packages used by typ
+	imports []string
+}
+
+// basicTypes are wire types of the Go basic types
+var basicTypes = map[string]wireType{
+	"string":  {typ: "string", write: "WriteOString"},
+	"bool":    {typ: "bool", write: "WriteOBool"},
+	"byte":    {typ: "byte", write: "WriteOByte"},
+	"int8":    {typ: "byte", write: "WriteOByte"},
+	"uint8":   {typ: "byte", write: "WriteOByte"},
+	"int16":   {typ: "int16", write: "WriteOShort"},
+	"uint16":  {typ: "int16", write: "WriteOShort"},
+	"rune":    {typ: "int32", write: "WriteOInt"},
+	"int32":   {typ: "int32", write: "WriteOInt"},
+	"uint32":  {typ: "int32", write: "WriteOInt"},
+	"int":     {typ: "int64", write: "WriteOLong"},
+	"int64":   {typ: "int64", write: "WriteOLong"},
+	"uint":    {typ: "int64", write: "WriteOLong"},
+	"uint64":  {typ: "int64", write: "WriteOLong"},
+	"float32": {typ: "float32", write: "WriteOFloat"},
+	"float64": {typ: "float64", write: "WriteODouble"},
+}
+
+// sliceTypes are wire types of the slices written as Ignite arrays
+var sliceTypes = map[string]wireType{
+	"[]byte":      {typ: "[]byte", write: "WriteOArrayBytes"},
+	"[]int16":     {typ: "[]int16", write: "WriteOArrayShorts"},
+	"[]int32":     {typ: "[]int32", write: "WriteOArrayInts"},
+	"[]int64":     {typ: "[]int64", write: "WriteOArrayLongs"},
+	"[]float32":   {typ: "[]float32", write: "WriteOArrayFloats"},
+	"[]float64":   {typ: "[]float64", write: "WriteOArrayDoubles"},
+	"[]bool":      {typ: "[]bool", write: "WriteOArrayBools"},
+	"[]string":    {typ: "[]string", write: "WriteOArrayOStrings"},
+	"[]time.Time": {typ: "[]time.Time", write: "WriteOArrayOTimestamps", imports: []string{"time"}},
+	"[]uuid.UUID": {typ: "[]uuid.UUID", write: "WriteOArrayOUUIDs", imports: []string{uuidPath}},
+}
+
+// field is struct field mapped to the complex object field
+type field struct {
+	goName string
+	name   string
+	id     int32
+	// goType is Go type of the struct field
+	goType string
+	// wire is wire type of the field, it is nil if ignite.WriteObject and ignite.ReadObjectInto are used
+	wire *wireType
+	// convert is conversion from the wire type to the field type, it is empty if conversion is not needed
+	convert string
+	// conv is conversion of the field value to the value written by wire.write
+	conv      string
+	pointer   bool
+	nullable  bool
+	omitEmpty bool
+	// zero is the field value which is omitted if omitEmpty is true
+	zero string
+}
+
+// structInfo is annotated struct
+type structInfo struct {
+	name     string
+	typeName string
+	fields   []field
+}
+
+// generator generates codecs of the structs declared in the single file
+type generator struct {
+	fset *token.FileSet
+	file *ast.File
+	// types are type declarations of the package by type name
+	types map[string]ast.Expr
+	// imports are import paths of the file by package name
+	imports map[string]string
+	// used are imports used by the generated code
+	used map[string]bool
+}
+
+// generate returns source of the codecs of the annotated structs declared in the file,
+// nil is returned if there are no annotated structs
+func generate(file string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, filepath.Dir(file), nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{fset: fset, types: map[string]ast.Expr{}, imports: map[string]string{}, used: map[string]bool{}}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for name, f := range pkg.Files {
+			if a, err := filepath.Abs(name); err == nil && a == abs {
+				g.file = f
+			}
+		}
+	}
+	if g.file == nil {
+		return nil, fmt.Errorf("file %s is not found in the package", file)
+	}
+	for name, f := range pkgs[g.file.Name.Name].Files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		for _, d := range f.Decls {
+			if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, s := range gd.Specs {
+					ts := s.(*ast.TypeSpec)
+					g.types[ts.Name.Name] = ts.Type
+				}
+			}
+		}
+	}
+	for _, i := range g.file.Imports {
+		path, _ := strconv.Unquote(i.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if i.Name != nil {
+			name = i.Name.Name
+		}
+		g.imports[name] = path
+	}
+
+	structs, err := g.structs()
+	if err != nil || len(structs) == 0 {
+		return nil, err
+	}
+	return g.source(structs)
+}
+
+// structs returns annotated structs of the file
+func (g *generator) structs() ([]structInfo, error) {
+	var structs []structInfo
+	for _, d := range g.file.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gd.Specs {
+			ts := s.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(gd.Specs) == 1 {
+				doc = gd.Doc
+			}
+			typeName, ok := annotated(doc)
+			if !ok {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("%s: annotated type %s is not struct", g.fset.Position(ts.Pos()), ts.Name.Name)
+			}
+			if typeName == "" {
+				typeName = ts.Name.Name
+			}
+			info := structInfo{name: ts.Name.Name, typeName: typeName}
+			for _, f := range st.Fields.List {
+				fields, err := g.fields(f)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", g.fset.Position(f.Pos()), err)
+				}
+				info.fields = append(info.fields, fields...)
+			}
+			ids := map[int32]string{}
+			for _, f := range info.fields {
+				if d, ok := ids[f.id]; ok {
+					return nil, fmt.Errorf("%s: fields %s and %s of struct %s have the same ID",
+						g.fset.Position(ts.Pos()), d, f.name, info.name)
+				}
+				ids[f.id] = f.name
+			}
+			structs = append(structs, info)
+		}
+	}
+	return structs, nil
+}
+
+// annotated returns binary type name set by the annotation, false is returned if the comment has no annotation
+func annotated(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, c := range doc.List {
+		if c.Text == annotation || strings.HasPrefix(c.Text, annotation+" ") {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, annotation)), true
+		}
+	}
+	return "", false
+}
+
+// fields returns complex object fields of the struct field declaration
+func (g *generator) fields(f *ast.Field) ([]field, error) {
+	var tag string
+	if f.Tag != nil {
+		s, _ := strconv.Unquote(f.Tag.Value)
+		tag = reflect.StructTag(s).Get(tagName)
+	}
+	if tag == "-" {
+		return nil, nil
+	}
+	if len(f.Names) == 0 {
+		return nil, fmt.Errorf("embedded field %s is not supported", g.expr(f.Type))
+	}
+
+	opts := strings.Split(tag, ",")
+	var typ string
+	var omitEmpty bool
+	for _, o := range opts[1:] {
+		switch {
+		case o == "omitempty":
+			omitEmpty = true
+		case strings.HasPrefix(o, "type="):
+			typ = strings.TrimPrefix(o, "type=")
+			switch typ {
+			case "timestamp", "date", "time", "char":
+			default:
+				return nil, fmt.Errorf("unsupported type option %q", typ)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported option %q", o)
+		}
+	}
+
+	var fields []field
+	for _, n := range f.Names {
+		if !n.IsExported() {
+			continue
+		}
+		fd := field{goName: n.Name, name: n.Name, goType: g.expr(f.Type), omitEmpty: omitEmpty}
+		if opts[0] != "" {
+			fd.name = opts[0]
+		}
+		if len(f.Names) > 1 && opts[0] != "" {
+			return nil, fmt.Errorf("tag name of fields %s is the same", fd.goType)
+		}
+		fd.id = ignite.HashCode(fd.name)
+		g.mapType(&fd, f.Type, typ)
+		if omitEmpty && fd.zero == "" {
+			return nil, fmt.Errorf("omitempty is not supported for field %s of type %s", n.Name, fd.goType)
+		}
+		fields = append(fields, fd)
+	}
+	return fields, nil
+}
+
+// mapType sets wire type of the field of type expr with type option typ
+func (g *generator) mapType(f *field, expr ast.Expr, typ string) {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		f.pointer, f.nullable, f.zero = true, true, "nil"
+		elem := field{goType: g.expr(e.X)}
+		g.mapType(&elem, e.X, typ)
+		if elem.wire != nil && !elem.nullable {
+			f.wire, f.convert, f.conv = elem.wire, elem.convert, elem.conv
+		}
+		return
+	case *ast.ArrayType:
+		if e.Len != nil {
+			// arrays are written by ignite.WriteObject
+			return
+		}
+		f.nullable, f.zero = true, "nil"
+		if w, ok := sliceTypes[g.expr(e)]; ok && g.wellKnown(e.Elt) {
+			f.wire = &w
+		}
+		return
+	case *ast.MapType, *ast.InterfaceType:
+		f.nullable, f.zero = true, "nil"
+		return
+	}
+
+	name, basic := g.underlying(expr)
+	if w, ok := basicTypes[name]; ok && basic {
+		if typ == "char" && (w.typ == "int32" || name == "uint16") {
+			w = wireType{typ: "ignite.Char", write: "WriteOChar"}
+		}
+		f.wire = &w
+		if f.goType != w.typ {
+			f.convert = f.goType
+			f.conv = w.typ
+		}
+		switch name {
+		case "string":
+			f.zero = `""`
+		case "bool":
+			f.zero = "false"
+		default:
+			f.zero = "0"
+		}
+		return
+	}
+	switch {
+	case name == "time.Time" && f.goType == "time.Time":
+		w := wireType{typ: "time.Time", write: "WriteOTimestamp", imports: []string{"time"}}
+		switch typ {
+		case "date":
+			w.write, f.conv = "WriteODate", "ignite.ToDate"
+		case "time":
+			w.write, f.conv = "WriteOTime", "ignite.ToTime"
+		}
+		f.wire = &w
+	case name == "uuid.UUID" && f.goType == "uuid.UUID":
+		f.wire = &wireType{typ: "uuid.UUID", write: "WriteOUUID", imports: []string{uuidPath}}
+	}
+}
+
+// underlying returns name of the basic type or the well-known type expr is based on,
+// false is returned if the type is not based on the basic type
+func (g *generator) underlying(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if _, ok := basicTypes[e.Name]; ok && e.Obj == nil {
+			return e.Name, true
+		}
+		if t, ok := g.types[e.Name]; ok {
+			return g.underlying(t)
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			switch path := g.imports[x.Name]; {
+			case path == "time" && e.Sel.Name == "Time":
+				return "time.Time", false
+			case path == uuidPath && e.Sel.Name == "UUID":
+				return "uuid.UUID", false
+			}
+		}
+	}
+	return "", false
+}
+
+// wellKnown returns true if the type is builtin or the well-known type imported with the default name
+func (g *generator) wellKnown(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Obj == nil
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		path := g.imports[x.Name]
+		return path == "time" && x.Name == "time" || path == uuidPath && x.Name == "uuid"
+	}
+	return false
+}
+
+// expr returns source of the expression
+func (g *generator) expr(e ast.Expr) string {
+	b := &bytes.Buffer{}
+	_ = format.Node(b, g.fset, e)
+	return b.String()
+}
+
+// source returns source of the codecs of the structs
+func (g *generator) source(structs []structInfo) ([]byte, error) {
+	body := &bytes.Buffer{}
+	for _, s := range structs {
+		g.writeStruct(body, s)
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by ignite-gen. DO NOT EDIT.\n\npackage %s\n\n", g.file.Name.Name)
+	imports := []string{"fmt", "io"}
+	for i := range g.used {
+		imports = append(imports, i)
+	}
+	sort.Strings(imports)
+	fmt.Fprintf(b, "import (\n")
+	for _, i := range imports {
+		if !strings.Contains(i, ".") {
+			fmt.Fprintf(b, "%q\n", i)
+		}
+	}
+	fmt.Fprintf(b, "\n")
+	for _, i := range imports {
+		if strings.Contains(i, ".") {
+			fmt.Fprintf(b, "%q\n", i)
+		}
+	}
+	fmt.Fprintf(b, "\nignite %q\n)\n", "github.com/amsokol/ignite-go-client/binary/v1")
+	b.Write(body.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %v", err)
+	}
+	return src, nil
+}
+
+// writeStruct writes WriteBinary and ReadBinary methods of the struct
+func (g *generator) writeStruct(b *bytes.Buffer, s structInfo) {
+	fmt.Fprintf(b, "\n// WriteBinary writes %s as complex object of type %q\n", s.name, s.typeName)
+	fmt.Fprintf(b, "func (v %s) WriteBinary(w io.Writer) error {\n", s.name)
+	fmt.Fprintf(b, "c := ignite.NewComplexObjectWriter(%d) // %s\n", ignite.HashCode(s.typeName), s.typeName)
+	for _, f := range s.fields {
+		g.writeField(b, f)
+	}
+	fmt.Fprintf(b, "return c.WriteObject(w)\n}\n")
+
+	fmt.Fprintf(b, "\n// ReadBinary reads %s from complex object, NULL resets the value\n", s.name)
+	fmt.Fprintf(b, "func (v *%s) ReadBinary(r io.Reader) error {\n", s.name)
+	fmt.Fprintf(b, "*v = %s{}\n", s.name)
+	if len(s.fields) == 0 {
+		fmt.Fprintf(b, "_, _, err := ignite.ReadOComplexObjectFields(r, func(int32, io.Reader) error { return nil })\n")
+		fmt.Fprintf(b, "return err\n}\n")
+		return
+	}
+	fmt.Fprintf(b, "_, _, err := ignite.ReadOComplexObjectFields(r, func(fieldID int32, field io.Reader) error {\n")
+	fmt.Fprintf(b, "switch fieldID {\n")
+	for _, f := range s.fields {
+		g.readField(b, f)
+	}
+	fmt.Fprintf(b, "}\nreturn nil\n})\nreturn err\n}\n")
+}
+
+// writeField writes code which writes the field
+func (g *generator) writeField(b *bytes.Buffer, f field) {
+	v := "v." + f.goName
+	if f.omitEmpty {
+		fmt.Fprintf(b, "if %s != %s {\n", v, f.zero)
+	}
+	errf := fmt.Sprintf("return fmt.Errorf(\"failed to write field %s: %%w\", err)", f.name)
+	switch {
+	case f.wire == nil:
+		fmt.Fprintf(b, "if err := ignite.WriteObject(c.Field(%d), %s); err != nil {\n%s\n}\n", f.id, v, errf)
+	case f.nullable:
+		fmt.Fprintf(b, "if %s == nil {\n", v)
+		fmt.Fprintf(b, "if err := ignite.WriteNull(c.Field(%d)); err != nil {\n%s\n}\n", f.id, errf)
+		fmt.Fprintf(b, "} else if err := ignite.%s(c.Field(%d), %s); err != nil {\n%s\n}\n",
+			f.wire.write, f.id, g.conv(f, v), errf)
+	default:
+		fmt.Fprintf(b, "if err := ignite.%s(c.Field(%d), %s); err != nil {\n%s\n}\n", f.wire.write, f.id, g.conv(f, v), errf)
+	}
+	if f.omitEmpty {
+		fmt.Fprintf(b, "}\n")
+	}
+}
+
+// conv returns expression which converts the field value v to the written value
+func (g *generator) conv(f field, v string) string {
+	if f.pointer {
+		v = "*" + v
+	}
+	for _, i := range f.wire.imports {
+		g.used[i] = true
+	}
+	if f.conv != "" {
+		return f.conv + "(" + v + ")"
+	}
+	return v
+}
+
+// readField writes switch case which reads the field
+func (g *generator) readField(b *bytes.Buffer, f field) {
+	fmt.Fprintf(b, "case %d: // %s\n", f.id, f.name)
+	if f.wire == nil {
+		fmt.Fprintf(b, "return ignite.ReadObjectInto(field, &v.%s)\n", f.goName)
+		return
+	}
+	fmt.Fprintf(b, "o, err := ignite.ReadObject(field)\nif err != nil || o == nil {\nreturn err\n}\n")
+	fmt.Fprintf(b, "x, ok := o.(%s)\n", f.wire.typ)
+	fmt.Fprintf(b, "if !ok {\nreturn fmt.Errorf(\"field %s: unexpected type %%T\", o)\n}\n", f.name)
+	x := "x"
+	if f.convert != "" {
+		x = f.convert + "(x)"
+	}
+	if f.pointer {
+		if f.convert != "" {
+			fmt.Fprintf(b, "y := %s\n", x)
+			x = "y"
+		}
+		fmt.Fprintf(b, "v.%s = &%s\n", f.goName, x)
+		return
+	}
+	fmt.Fprintf(b, "v.%s = %s\n", f.goName, x)
+}
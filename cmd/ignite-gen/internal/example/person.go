@@ -0,0 +1,45 @@
+// Package example contains structs with codecs generated by ignite-gen
+package example
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:generate go run github.com/amsokol/ignite-go-client/cmd/ignite-gen
+
+// Status is status of the person
+type Status string
+
+// Address is address of the person
+//
+//ignite:binary
+type Address struct {
+	City   string `ignite:"city"`
+	Street string `ignite:"street,omitempty"`
+}
+
+// Person is complex object with the fields of all supported kinds
+//
+//ignite:binary org.example.Person
+type Person struct {
+	ID       int64       `ignite:"id"`
+	Name     string      `ignite:"name"`
+	Age      *int32      `ignite:"age"`
+	Status   Status      `ignite:"status,omitempty"`
+	Initial  rune        `ignite:"initial,type=char"`
+	Height   float32     `ignite:"height"`
+	Active   bool        `ignite:"active"`
+	Born     time.Time   `ignite:"born,type=date"`
+	Updated  time.Time   `ignite:"updated"`
+	Key      uuid.UUID   `ignite:"key"`
+	Tags     []string    `ignite:"tags"`
+	Scores   []int64     `ignite:"scores"`
+	Address  Address     `ignite:"address"`
+	Previous []Address   `ignite:"previous"`
+	Parent   *Person     `ignite:"parent"`
+	Count    int         `ignite:"count"`
+	Secret   string      `ignite:"-"`
+	hidden   string
+}
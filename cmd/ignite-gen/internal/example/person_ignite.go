@@ -0,0 +1,273 @@
+// Code generated by ignite-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	ignite "github.com/amsokol/ignite-go-client/binary/v1"
+)
+
+// WriteBinary writes Address as complex object of type "Address"
+func (v Address) WriteBinary(w io.Writer) error {
+	c := ignite.NewComplexObjectWriter(516961236) // Address
+	if err := ignite.WriteOString(c.Field(3053931), v.City); err != nil {
+		return fmt.Errorf("failed to write field city: %w", err)
+	}
+	if v.Street != "" {
+		if err := ignite.WriteOString(c.Field(-891990013), v.Street); err != nil {
+			return fmt.Errorf("failed to write field street: %w", err)
+		}
+	}
+	return c.WriteObject(w)
+}
+
+// ReadBinary reads Address from complex object, NULL resets the value
+func (v *Address) ReadBinary(r io.Reader) error {
+	*v = Address{}
+	_, _, err := ignite.ReadOComplexObjectFields(r, func(fieldID int32, field io.Reader) error {
+		switch fieldID {
+		case 3053931: // city
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(string)
+			if !ok {
+				return fmt.Errorf("field city: unexpected type %T", o)
+			}
+			v.City = x
+		case -891990013: // street
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(string)
+			if !ok {
+				return fmt.Errorf("field street: unexpected type %T", o)
+			}
+			v.Street = x
+		}
+		return nil
+	})
+	return err
+}
+
+// WriteBinary writes Person as complex object of type "org.example.Person"
+func (v Person) WriteBinary(w io.Writer) error {
+	c := ignite.NewComplexObjectWriter(-1071852349) // org.example.Person
+	if err := ignite.WriteOLong(c.Field(3355), v.ID); err != nil {
+		return fmt.Errorf("failed to write field id: %w", err)
+	}
+	if err := ignite.WriteOString(c.Field(3373707), v.Name); err != nil {
+		return fmt.Errorf("failed to write field name: %w", err)
+	}
+	if v.Age == nil {
+		if err := ignite.WriteNull(c.Field(96511)); err != nil {
+			return fmt.Errorf("failed to write field age: %w", err)
+		}
+	} else if err := ignite.WriteOInt(c.Field(96511), *v.Age); err != nil {
+		return fmt.Errorf("failed to write field age: %w", err)
+	}
+	if v.Status != "" {
+		if err := ignite.WriteOString(c.Field(-892481550), string(v.Status)); err != nil {
+			return fmt.Errorf("failed to write field status: %w", err)
+		}
+	}
+	if err := ignite.WriteOChar(c.Field(1948342084), ignite.Char(v.Initial)); err != nil {
+		return fmt.Errorf("failed to write field initial: %w", err)
+	}
+	if err := ignite.WriteOFloat(c.Field(-1221029593), v.Height); err != nil {
+		return fmt.Errorf("failed to write field height: %w", err)
+	}
+	if err := ignite.WriteOBool(c.Field(-1422950650), v.Active); err != nil {
+		return fmt.Errorf("failed to write field active: %w", err)
+	}
+	if err := ignite.WriteODate(c.Field(3029833), ignite.ToDate(v.Born)); err != nil {
+		return fmt.Errorf("failed to write field born: %w", err)
+	}
+	if err := ignite.WriteOTimestamp(c.Field(-234430277), v.Updated); err != nil {
+		return fmt.Errorf("failed to write field updated: %w", err)
+	}
+	if err := ignite.WriteOUUID(c.Field(106079), v.Key); err != nil {
+		return fmt.Errorf("failed to write field key: %w", err)
+	}
+	if v.Tags == nil {
+		if err := ignite.WriteNull(c.Field(3552281)); err != nil {
+			return fmt.Errorf("failed to write field tags: %w", err)
+		}
+	} else if err := ignite.WriteOArrayOStrings(c.Field(3552281), v.Tags); err != nil {
+		return fmt.Errorf("failed to write field tags: %w", err)
+	}
+	if v.Scores == nil {
+		if err := ignite.WriteNull(c.Field(-907766751)); err != nil {
+			return fmt.Errorf("failed to write field scores: %w", err)
+		}
+	} else if err := ignite.WriteOArrayLongs(c.Field(-907766751), v.Scores); err != nil {
+		return fmt.Errorf("failed to write field scores: %w", err)
+	}
+	if err := ignite.WriteObject(c.Field(-1147692044), v.Address); err != nil {
+		return fmt.Errorf("failed to write field address: %w", err)
+	}
+	if err := ignite.WriteObject(c.Field(-1273775369), v.Previous); err != nil {
+		return fmt.Errorf("failed to write field previous: %w", err)
+	}
+	if err := ignite.WriteObject(c.Field(-995424086), v.Parent); err != nil {
+		return fmt.Errorf("failed to write field parent: %w", err)
+	}
+	if err := ignite.WriteOLong(c.Field(94851343), int64(v.Count)); err != nil {
+		return fmt.Errorf("failed to write field count: %w", err)
+	}
+	return c.WriteObject(w)
+}
+
+// ReadBinary reads Person from complex object, NULL resets the value
+func (v *Person) ReadBinary(r io.Reader) error {
+	*v = Person{}
+	_, _, err := ignite.ReadOComplexObjectFields(r, func(fieldID int32, field io.Reader) error {
+		switch fieldID {
+		case 3355: // id
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(int64)
+			if !ok {
+				return fmt.Errorf("field id: unexpected type %T", o)
+			}
+			v.ID = x
+		case 3373707: // name
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(string)
+			if !ok {
+				return fmt.Errorf("field name: unexpected type %T", o)
+			}
+			v.Name = x
+		case 96511: // age
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(int32)
+			if !ok {
+				return fmt.Errorf("field age: unexpected type %T", o)
+			}
+			v.Age = &x
+		case -892481550: // status
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(string)
+			if !ok {
+				return fmt.Errorf("field status: unexpected type %T", o)
+			}
+			v.Status = Status(x)
+		case 1948342084: // initial
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(ignite.Char)
+			if !ok {
+				return fmt.Errorf("field initial: unexpected type %T", o)
+			}
+			v.Initial = rune(x)
+		case -1221029593: // height
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(float32)
+			if !ok {
+				return fmt.Errorf("field height: unexpected type %T", o)
+			}
+			v.Height = x
+		case -1422950650: // active
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(bool)
+			if !ok {
+				return fmt.Errorf("field active: unexpected type %T", o)
+			}
+			v.Active = x
+		case 3029833: // born
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(time.Time)
+			if !ok {
+				return fmt.Errorf("field born: unexpected type %T", o)
+			}
+			v.Born = x
+		case -234430277: // updated
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(time.Time)
+			if !ok {
+				return fmt.Errorf("field updated: unexpected type %T", o)
+			}
+			v.Updated = x
+		case 106079: // key
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(uuid.UUID)
+			if !ok {
+				return fmt.Errorf("field key: unexpected type %T", o)
+			}
+			v.Key = x
+		case 3552281: // tags
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.([]string)
+			if !ok {
+				return fmt.Errorf("field tags: unexpected type %T", o)
+			}
+			v.Tags = x
+		case -907766751: // scores
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.([]int64)
+			if !ok {
+				return fmt.Errorf("field scores: unexpected type %T", o)
+			}
+			v.Scores = x
+		case -1147692044: // address
+			return ignite.ReadObjectInto(field, &v.Address)
+		case -1273775369: // previous
+			return ignite.ReadObjectInto(field, &v.Previous)
+		case -995424086: // parent
+			return ignite.ReadObjectInto(field, &v.Parent)
+		case 94851343: // count
+			o, err := ignite.ReadObject(field)
+			if err != nil || o == nil {
+				return err
+			}
+			x, ok := o.(int64)
+			if !ok {
+				return fmt.Errorf("field count: unexpected type %T", o)
+			}
+			v.Count = int(x)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,99 @@
+package example
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amsokol/ignite-go-client/binary/v1"
+)
+
+func TestPerson_Binary(t *testing.T) {
+	age := int32(30)
+	tests := []struct {
+		name string
+		v    Person
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "all fields",
+			v: Person{
+				ID:       1,
+				Name:     "Ivan",
+				Age:      &age,
+				Status:   "active",
+				Initial:  'I',
+				Height:   1.8,
+				Active:   true,
+				Born:     time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC),
+				Updated:  time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+				Key:      uuid.New(),
+				Tags:     []string{"a", "b"},
+				Scores:   []int64{1, 2},
+				Address:  Address{City: "Moscow"},
+				Previous: []Address{{City: "Kazan", Street: "Baumana"}},
+				Parent:   &Person{Name: "Petr", Born: time.Unix(0, 0).UTC(), Updated: time.Unix(0, 0).UTC()},
+				Count:    10,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.v.Born.IsZero() {
+				tt.v.Born, tt.v.Updated = time.Unix(0, 0).UTC(), time.Unix(0, 0).UTC()
+			}
+			w := &bytes.Buffer{}
+			if err := ignite.WriteObject(w, tt.v); err != nil {
+				t.Fatalf("ignite.WriteObject() error = %v", err)
+			}
+			b := w.Bytes()
+
+			// generated codec
+			var got Person
+			if err := ignite.ReadObjectInto(bytes.NewReader(b), &got); err != nil {
+				t.Fatalf("ignite.ReadObjectInto() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.v) {
+				t.Errorf("Person.ReadBinary() = %+v, want %+v", got, tt.v)
+			}
+
+			// the same object is read by the reflection based codec
+			o, err := ignite.ReadObject(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("ignite.ReadObject() error = %v", err)
+			}
+			c, ok := o.(ignite.ComplexObject)
+			if !ok {
+				t.Fatalf("ignite.ReadObject() = %T, want ignite.ComplexObject", o)
+			}
+			if want := ignite.HashCode("org.example.Person"); c.Type != want {
+				t.Errorf("type ID = %d, want %d", c.Type, want)
+			}
+			got = Person{}
+			if err = ignite.UnmarshalObject(c, &got); err != nil {
+				t.Fatalf("ignite.UnmarshalObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.v) {
+				t.Errorf("ignite.UnmarshalObject() = %+v, want %+v", got, tt.v)
+			}
+		})
+	}
+
+	var p *Person
+	w := &bytes.Buffer{}
+	if err := ignite.WriteObject(w, p); err != nil {
+		t.Fatalf("ignite.WriteObject() error = %v", err)
+	}
+	got := Person{Name: "Ivan"}
+	if err := got.ReadBinary(w); err != nil {
+		t.Fatalf("Person.ReadBinary() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, Person{}) {
+		t.Errorf("Person.ReadBinary() = %+v for NULL, want zero value", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Command ignite-gen generates reflection-free complex object codecs for Go structs.
+//
+// Structs annotated with "//ignite:binary" comment get WriteBinary and ReadBinary methods
+// which implement ignite.BinaryMarshaler and ignite.BinaryUnmarshaler, so ignite.WriteObject
+// and ignite.ReadObjectInto use them instead of reflection:
+//
+//	//go:generate ignite-gen
+//
+//	//ignite:binary org.example.Person
+//	type Person struct {
+//		Name string    `ignite:"name"`
+//		Born time.Time `ignite:"born,type=date"`
+//	}
+//
+// Binary type name is set after the annotation, the struct name is used if it is not set.
+// Fields are mapped by the "ignite" field tag the same way as in ignite.WriteObject.
+// Methods of the structs declared in file.go are written to file_ignite.go.
+// The file is $GOFILE set by go generate if files are not set in the command line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [file.go ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		if f := os.Getenv("GOFILE"); f != "" {
+			files = []string{f}
+		}
+	}
+	if len(files) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	for _, f := range files {
+		if err := generateFile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "ignite-gen: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// generateFile writes codecs of the annotated structs declared in the file to file_ignite.go
+func generateFile(file string) error {
+	src, err := generate(file)
+	if err != nil {
+		return err
+	}
+	if src == nil {
+		// no annotated structs
+		return nil
+	}
+	out := strings.TrimSuffix(file, filepath.Ext(file)) + "_ignite.go"
+	return ioutil.WriteFile(out, src, 0644)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func Test_generate(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "example",
+			file: "internal/example/person.go",
+			want: "internal/example/person_ignite.go",
+		},
+		{
+			name: "no annotated structs",
+			file: "main.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generate(tt.file)
+			if err != nil {
+				t.Fatalf("generate() error = %v", err)
+			}
+			var want []byte
+			if tt.want != "" {
+				if want, err = ioutil.ReadFile(tt.want); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if string(got) != string(want) {
+				t.Errorf("generate() = %s, want %s (run go generate to update the file)", got, want)
+			}
+		})
+	}
+}
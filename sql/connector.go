@@ -28,10 +28,10 @@ type connector struct {
 //
 // The returned connection is only used by one goroutine at a
 // time.
-func (c *connector) Connect(context.Context) (driver.Conn, error) {
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 	switch c.info.Major {
 	case 1:
-		return v1.Connect(c.info)
+		return v1.ConnectContext(ctx, c.info)
 	default:
 		return nil, errors.Errorf("unsupported protocol version: v%d.%d.%d", c.info.Major, c.info.Minor, c.info.Patch)
 	}
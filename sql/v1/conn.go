@@ -19,8 +19,12 @@ type conn struct {
 	debugID string
 	info    common.ConnInfo
 	client  ignite.Client
+	// tx is transaction statements are executed in
+	tx       ignite.Tx
+	readOnly bool
 
 	driver.Conn
+	driver.ConnBeginTx
 	driver.ExecerContext
 	driver.Pinger
 	driver.QueryerContext
@@ -32,12 +36,25 @@ func (c *conn) isConnected() bool {
 	return c.client != nil && c.client.Connected()
 }
 
+// executor returns client statements are executed by: the transaction if it is started
+func (c *conn) executor() ignite.Client {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.client
+}
+
+// endTx finishes execution of the statements in the transaction
+func (c *conn) endTx() {
+	c.tx, c.readOnly = nil, false
+}
+
 // resourceClose closes a resource, such as query cursor.
-func (c *conn) resourceClose(id int64) error {
+func (c *conn) resourceClose(ctx context.Context, id int64) error {
 	if !c.isConnected() {
 		return driver.ErrBadConn
 	}
-	return c.client.ResourceClose(id)
+	return c.executor().ResourceCloseContext(ctx, id)
 }
 
 // <driver.Conn>
@@ -61,6 +78,8 @@ func (c *conn) Prepare(query string) (driver.Stmt, error) {
 func (c *conn) Close() error {
 	if c.client != nil {
 		defer func() {
+			// transaction is rolled back by the server when the connection is closed
+			c.endTx()
 			c.client = nil
 		}()
 		return c.client.Close()
@@ -72,7 +91,7 @@ func (c *conn) Close() error {
 //
 // Deprecated: Drivers should implement ConnBeginTx instead (or additionally).
 func (c *conn) Begin() (driver.Tx, error) {
-	return nil, errors.Errorf("Transactions are not supported by Apache Ignite binary protocol v1.x.x")
+	return c.BeginTx(context.Background(), driver.TxOptions{})
 }
 
 // </driver.Conn>
@@ -83,6 +102,9 @@ func (c *conn) ExecContext(ctx context.Context, query string, args []driver.Name
 	if !c.isConnected() {
 		return nil, driver.ErrBadConn
 	}
+	if c.tx != nil && c.readOnly {
+		return nil, errors.Errorf("update query is not allowed in read-only transaction")
+	}
 
 	d := ignite.QuerySQLFieldsData{
 		Schema:           c.info.Schema,
@@ -114,13 +136,13 @@ func (c *conn) ExecContext(ctx context.Context, query string, args []driver.Name
 		}
 	}
 
-	res, err := c.client.QuerySQLFields(c.info.Cache, false, d)
+	res, err := c.executor().QuerySQLFieldsContext(ctx, c.info.Cache, false, d)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to execute query")
 	}
 
 	if res.HasMore {
-		if err = c.resourceClose(res.ID); err != nil {
+		if err = c.resourceClose(ctx, res.ID); err != nil {
 			return nil, errors.Wrapf(err, "failed to close server cursor")
 		}
 	}
@@ -206,7 +228,7 @@ func (c *conn) QueryContext(ctx context.Context, query string, args []driver.Nam
 		}
 	}
 
-	r, err := c.client.QuerySQLFieldsRaw(c.info.Cache, false, d)
+	r, err := c.executor().QuerySQLFieldsRawContext(ctx, c.info.Cache, false, d)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to execute query")
 	}
@@ -229,16 +251,22 @@ func (c *conn) QueryNexPageContext(ctx context.Context, cursorID int64) (*ignite
 	if !c.isConnected() {
 		return nil, driver.ErrBadConn
 	}
-	return c.client.QuerySQLFieldsCursorGetPageRaw(cursorID)
+	return c.executor().QuerySQLFieldsCursorGetPageRawContext(ctx, cursorID)
 }
 
 // Connect opens connection with protocol version v1
 func Connect(ci common.ConnInfo) (driver.Conn, error) {
+	return ConnectContext(context.Background(), ci)
+}
+
+// ConnectContext opens connection with protocol version v1.
+// ctx is used for dialing and handshake only.
+func ConnectContext(ctx context.Context, ci common.ConnInfo) (driver.Conn, error) {
 	if ci.Timeout > 0 {
 		ci.ConnInfo.Dialer.Timeout = time.Duration(ci.Timeout) * time.Millisecond
 	}
 
-	client, err := ignite.Connect(ci.ConnInfo)
+	client, err := ignite.ConnectContext(ctx, ci.ConnInfo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
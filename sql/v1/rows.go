@@ -38,7 +38,7 @@ func (r *rows) Close() error {
 	if r.rowsLeft > 0 {
 		// to prevent resource leak on server try to close cursor
 		r.rowsLeft = 0
-		return r.conn.resourceClose(r.id)
+		return r.conn.resourceClose(context.Background(), r.id)
 	}
 	return nil
 }
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"github.com/amsokol/ignite-go-client/binary/errors"
+	"github.com/amsokol/ignite-go-client/binary/v1"
+)
+
+// SQL transaction struct
+type tx struct {
+	conn *conn
+	tx   ignite.Tx
+
+	driver.Tx
+}
+
+// txSettings returns concurrency mode and isolation level of Ignite transaction for the transaction options.
+// Read-only transaction is optimistic, so it does not lock the entries it reads.
+func txSettings(opts driver.TxOptions) (ignite.TxConcurrency, ignite.TxIsolation, error) {
+	concurrency := ignite.TxPessimistic
+	if opts.ReadOnly {
+		concurrency = ignite.TxOptimistic
+	}
+	switch sql.IsolationLevel(opts.Isolation) {
+	case sql.LevelDefault, sql.LevelRepeatableRead:
+		return concurrency, ignite.TxRepeatableRead, nil
+	case sql.LevelReadUncommitted, sql.LevelReadCommitted:
+		return concurrency, ignite.TxReadCommitted, nil
+	case sql.LevelSerializable:
+		return concurrency, ignite.TxSerializable, nil
+	default:
+		return 0, 0, errors.Errorf("isolation level %s is not supported", sql.IsolationLevel(opts.Isolation))
+	}
+}
+
+// <driver.Tx>
+
+// Commit commits the transaction
+func (t *tx) Commit() error {
+	defer t.conn.endTx()
+	return t.tx.Commit()
+}
+
+// Rollback rolls the transaction back
+func (t *tx) Rollback() error {
+	defer t.conn.endTx()
+	return t.tx.Rollback()
+}
+
+// </driver.Tx>
+
+// <driver.ConnBeginTx>
+
+// BeginTx starts and returns a new transaction.
+// Statements of the connection are executed in the transaction until it is committed or rolled back.
+// Isolation level and read-only flag of opts are mapped to the Ignite transaction settings,
+// deadline of ctx limits duration of the transaction.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if !c.isConnected() {
+		return nil, driver.ErrBadConn
+	}
+	if c.tx != nil {
+		return nil, errors.Errorf("transaction is already started")
+	}
+	concurrency, isolation, err := txSettings(opts)
+	if err != nil {
+		return nil, err
+	}
+	var timeout time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		if timeout = time.Until(deadline); timeout <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+	}
+
+	t, err := c.client.TxStartContext(ctx, concurrency, isolation, timeout, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to start transaction")
+	}
+	c.tx, c.readOnly = t, opts.ReadOnly
+	return &tx{conn: c, tx: t}, nil
+}
+
+// </driver.ConnBeginTx>
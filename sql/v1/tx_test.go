@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/amsokol/ignite-go-client/binary/v1"
+)
+
+func Test_txSettings(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            driver.TxOptions
+		wantConcurrency ignite.TxConcurrency
+		wantIsolation   ignite.TxIsolation
+		wantErr         bool
+	}{
+		{
+			name:            "default",
+			opts:            driver.TxOptions{},
+			wantConcurrency: ignite.TxPessimistic,
+			wantIsolation:   ignite.TxRepeatableRead,
+		},
+		{
+			name:            "read uncommitted",
+			opts:            driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelReadUncommitted)},
+			wantConcurrency: ignite.TxPessimistic,
+			wantIsolation:   ignite.TxReadCommitted,
+		},
+		{
+			name:            "read committed",
+			opts:            driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelReadCommitted)},
+			wantConcurrency: ignite.TxPessimistic,
+			wantIsolation:   ignite.TxReadCommitted,
+		},
+		{
+			name:            "repeatable read",
+			opts:            driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelRepeatableRead)},
+			wantConcurrency: ignite.TxPessimistic,
+			wantIsolation:   ignite.TxRepeatableRead,
+		},
+		{
+			name:            "serializable read-only",
+			opts:            driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable), ReadOnly: true},
+			wantConcurrency: ignite.TxOptimistic,
+			wantIsolation:   ignite.TxSerializable,
+		},
+		{
+			name:    "snapshot",
+			opts:    driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSnapshot)},
+			wantErr: true,
+		},
+		{
+			name:    "linearizable",
+			opts:    driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelLinearizable)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			concurrency, isolation, err := txSettings(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("txSettings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if concurrency != tt.wantConcurrency {
+				t.Errorf("txSettings() concurrency = %v, want %v", concurrency, tt.wantConcurrency)
+			}
+			if isolation != tt.wantIsolation {
+				t.Errorf("txSettings() isolation = %v, want %v", isolation, tt.wantIsolation)
+			}
+		})
+	}
+}
+
+func Test_conn_BeginTx(t *testing.T) {
+	c := &conn{}
+	if _, err := c.BeginTx(context.Background(), driver.TxOptions{}); err != driver.ErrBadConn {
+		t.Errorf("conn.BeginTx() error = %v, want %v", err, driver.ErrBadConn)
+	}
+}